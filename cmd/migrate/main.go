@@ -0,0 +1,236 @@
+// Команда migrate управляет схемой БД без запуска API: применяет и
+// откатывает встроенные миграции, показывает их состояние и создаёт новые.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/andro-kes/avito_test/internal/config"
+	logger "github.com/andro-kes/avito_test/internal/log"
+	"github.com/andro-kes/avito_test/internal/migrations"
+)
+
+const usage = `usage: migrate <command> [args] [-config file] [-key=value ...]
+
+commands:
+  up [n]            применить n неприменённых миграций (по умолчанию все)
+  down [n]          откатить n последних миграций (по умолчанию 1)
+  goto <version>    применить или откатить миграции до версии version (0 — откатить все)
+  status            показать встроенные и применённые миграции
+  verify            сравнить схему БД с ожидаемой после всех миграций
+  create <name>     создать пару файлов новой миграции (-dir — каталог, по умолчанию internal/migrations/migrations)
+
+Подключение к БД берётся из конфигурации сервиса: файл, DB_URL или -database.url.
+`
+
+func main() {
+	logger.Init()
+	code := run(os.Args[1:])
+	logger.Close()
+	os.Exit(code)
+}
+
+func run(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+	command, rest := args[0], args[1:]
+
+	// Позиционные аргументы команды идут до флагов конфигурации.
+	var positional []string
+	for len(rest) > 0 && !strings.HasPrefix(rest[0], "-") {
+		positional = append(positional, rest[0])
+		rest = rest[1:]
+	}
+
+	if command == "create" {
+		return runCreate(positional, rest)
+	}
+
+	action := parse(command, positional)
+	if action == nil {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	cfg, err := config.Load(rest)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "migrate:", err)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	pool, err := connect(ctx, cfg.DbURL)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "migrate: connect:", err)
+		return 1
+	}
+	defer pool.Close()
+
+	if err := action(ctx, pool); err != nil {
+		fmt.Fprintln(os.Stderr, "migrate:", err)
+		return 1
+	}
+	return 0
+}
+
+// parse проверяет аргументы команды до подключения к БД; nil — команда
+// или аргументы некорректны.
+func parse(command string, positional []string) func(context.Context, *pgxpool.Pool) error {
+	switch command {
+	case "up":
+		n, ok := count(positional, 0)
+		if !ok {
+			return nil
+		}
+		return func(ctx context.Context, pool *pgxpool.Pool) error {
+			done, err := migrations.Up(ctx, pool, n)
+			report("applied", done)
+			return err
+		}
+	case "down":
+		n, ok := count(positional, 1)
+		if !ok {
+			return nil
+		}
+		return func(ctx context.Context, pool *pgxpool.Pool) error {
+			done, err := migrations.Down(ctx, pool, n)
+			report("reverted", done)
+			return err
+		}
+	case "goto":
+		if len(positional) != 1 {
+			return nil
+		}
+		version, err := strconv.Atoi(positional[0])
+		if err != nil || version < 0 {
+			return nil
+		}
+		return func(ctx context.Context, pool *pgxpool.Pool) error {
+			applied, reverted, err := migrations.Goto(ctx, pool, version)
+			report("reverted", reverted)
+			report("applied", applied)
+			return err
+		}
+	case "verify":
+		if len(positional) != 0 {
+			return nil
+		}
+		return func(ctx context.Context, pool *pgxpool.Pool) error {
+			diffs, err := migrations.Verify(ctx, pool)
+			if err != nil {
+				return err
+			}
+			for _, d := range diffs {
+				fmt.Println(d)
+			}
+			if len(diffs) > 0 {
+				return fmt.Errorf("schema differs from migrations: %d differences", len(diffs))
+			}
+			fmt.Println("schema matches migrations")
+			return nil
+		}
+	case "status":
+		if len(positional) != 0 {
+			return nil
+		}
+		return func(ctx context.Context, pool *pgxpool.Pool) error {
+			states, err := migrations.List(ctx, pool)
+			if err != nil {
+				return err
+			}
+			printStatus(states)
+			return nil
+		}
+	}
+	return nil
+}
+
+func runCreate(positional, args []string) int {
+	fs := flag.NewFlagSet("create", flag.ContinueOnError)
+	dir := fs.String("dir", "internal/migrations/migrations", "каталог миграций")
+	if err := fs.Parse(args); err != nil || len(positional) != 1 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	upPath, downPath, err := migrations.Create(*dir, positional[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "migrate:", err)
+		return 1
+	}
+	fmt.Println("created", upPath)
+	fmt.Println("created", downPath)
+	return 0
+}
+
+// count разбирает необязательный аргумент [n].
+func count(positional []string, def int) (int, bool) {
+	switch len(positional) {
+	case 0:
+		return def, true
+	case 1:
+		n, err := strconv.Atoi(positional[0])
+		return n, err == nil && n > 0
+	}
+	return 0, false
+}
+
+func connect(ctx context.Context, url string) (*pgxpool.Pool, error) {
+	cfg, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		return nil, err
+	}
+	cfg.MaxConns = 1
+
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := pool.Ping(pingCtx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	return pool, nil
+}
+
+func report(verb string, done []migrations.Migration) {
+	for _, m := range done {
+		fmt.Printf("%s %06d %s\n", verb, m.Version, m.Name)
+	}
+}
+
+func printStatus(states []migrations.State) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, st := range states {
+		status, appliedAt := "pending", ""
+		if st.AppliedAt != nil {
+			status, appliedAt = "applied", st.AppliedAt.Format(time.RFC3339)
+		}
+		if st.Modified {
+			status = "modified"
+		}
+		if !st.Embedded {
+			status = "unknown"
+		}
+		fmt.Fprintf(w, "%06d\t%s\t%s\t%s\n", st.Version, st.Name, status, appliedAt)
+	}
+	w.Flush()
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/andro-kes/avito_test/internal/config"
+)
+
+// runConfig — подкоманда `server config print [флаги]`: действующая конфигурация
+// с учётом файла, окружения и флагов; секреты скрыты.
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, "usage: server config print [-config file] [-key=value ...]")
+		return 2
+	}
+
+	cfg, err := config.Load(args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "config:", err)
+		return 2
+	}
+	if err := cfg.Print(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "config:", err)
+		return 1
+	}
+	return 0
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
@@ -10,54 +11,143 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 
 	"github.com/andro-kes/avito_test/internal/config"
 	"github.com/andro-kes/avito_test/internal/http/handlers"
 	"github.com/andro-kes/avito_test/internal/http/middleware"
+	"github.com/andro-kes/avito_test/internal/integrations"
+	"github.com/andro-kes/avito_test/internal/integrations/github"
+	"github.com/andro-kes/avito_test/internal/integrations/gitlab"
 	logger "github.com/andro-kes/avito_test/internal/log"
+	"github.com/andro-kes/avito_test/internal/metrics"
 	"github.com/andro-kes/avito_test/internal/migrations"
+	"github.com/andro-kes/avito_test/internal/repo"
+	"github.com/andro-kes/avito_test/internal/repo/memory"
+	"github.com/andro-kes/avito_test/internal/repo/sqlite"
+	"github.com/andro-kes/avito_test/internal/service"
+	"github.com/andro-kes/avito_test/internal/tracing"
 )
 
 func main() {
 	logger.Init()
-	cfg := config.Init()
 
-	router := gin.Default()
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "sync":
+			code := runSync(os.Args[2:])
+			logger.Close()
+			os.Exit(code)
+		case "config":
+			os.Exit(runConfig(os.Args[2:]))
+		}
+	}
+
+	args := os.Args[1:]
+	cfg, err := config.Load(args)
+	if err != nil {
+		logger.Log.Fatal("invalid configuration", zap.Error(err))
+	}
+	if err := logger.SetLevel(cfg.LogLevel); err != nil {
+		logger.Log.Fatal("invalid log level", zap.Error(err))
+	}
+
+	router := gin.New()
 
 	ctx := context.Background()
-	pool, err := NewPool(ctx)
+	shutdownTracing, err := tracing.Init(ctx, cfg.Tracing)
 	if err != nil {
-		logger.Log.Fatal("failed to create pool")
+		logger.Log.Fatal("failed to init tracing", zap.Error(err))
 	}
+	router.Use(tracing.Middleware())
+	router.Use(middleware.RequestID(), middleware.AccessLog(), middleware.Recovery())
 
-	if err := applyMigrations(ctx, pool); err != nil {
-		logger.Log.Fatal("failed to apply migrations", zap.Error(err))
+	st, err := openStorage(ctx, cfg)
+	if err != nil {
+		logger.Log.Fatal("failed to open storage", zap.Error(err))
+	}
+	logger.Log.Info("storage opened", zap.String("backend", st.Backend))
+
+	metrics.Register(st.Stats, st.Pool)
+	router.Use(metrics.Middleware())
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	limiter := middleware.NewRateLimiter(cfg.RateLimit.RPS, cfg.RateLimit.Burst)
+	idempotency := service.NewIdempotencyService(st, cfg.IdempotencyTTL)
+
+	purgeCtx, stopPurge := context.WithCancel(ctx)
+	defer stopPurge()
+	go purgeIdempotencyKeys(purgeCtx, idempotency)
+
+	handlerManager := handlers.NewHandlerManager(st)
+
+	handlerManager.StatsService.Timezone = cfg.StatsTimezone
+	handlerManager.PRService.SetReviewersCount(cfg.Reviewers.Count)
+
+	// Параметры, которые можно менять на ходу: по SIGHUP или при изменении файла.
+	reloader := config.NewReloader(args, cfg, func(c *config.Config) {
+		_ = logger.SetLevel(c.LogLevel)
+		handlerManager.PRService.SetReviewersCount(c.Reviewers.Count)
+		limiter.SetLimits(c.RateLimit.RPS, c.RateLimit.Burst)
+	})
+	reloadCtx, stopReload := context.WithCancel(ctx)
+	defer stopReload()
+	go reloader.Run(reloadCtx)
+
+	notifier := newForgeNotifier(cfg, st.Forge)
+	if notifier != nil {
+		handlerManager.PRService.Notifier = notifier
 	}
 
-	handlerManager := handlers.NewHandlerManager(pool)
+	health := router.Group("/health/")
+	health.GET("live", handlerManager.Live)
+	health.GET("ready", handlerManager.Ready)
+
+	adminOnly := middleware.Admin(cfg.AdminToken)
 
-	team := router.Group("/team/")
+	// Лимит запросов действует только на API: пробы /health/ и опрос /metrics
+	// не должны получать 429. Лимит стоит до идемпотентности, чтобы 429 не сохранялся.
+	api := router.Group("/", middleware.RateLimit(limiter), middleware.Idempotency(idempotency), middleware.Errors())
+
+	team := api.Group("/team/")
 	team.POST("add/", handlerManager.AddTeam)
 	team.GET("get/", handlerManager.GetTeam)
+	team.GET("export/", handlerManager.ExportTeams)
+	team.POST("import/", adminOnly, handlerManager.ImportTeams)
 
-	user := router.Group("/users/")
-	user.POST("setIsActive/", middleware.Admin(), handlerManager.SetIsActive)
+	user := api.Group("/users/")
+	user.POST("setIsActive/", adminOnly, handlerManager.SetIsActive)
 	user.GET("getReview/", handlerManager.GetUserReview)
 	user.GET("countReview/", handlerManager.CountReview)
 	user.POST("deactivate/", handlerManager.DeactivateUsers)
 
-	pr := router.Group("/pullRequest/")
+	pr := api.Group("/pullRequest/")
 	pr.POST("create/", handlerManager.CreatePR)
 	pr.POST("merge/", handlerManager.MergePR)
 	pr.POST("reassign/", handlerManager.ReassignReviewer)
+	pr.POST("review/", handlerManager.ReviewPR)
+	pr.GET("get/", handlerManager.GetPR)
+	pr.GET("list/", handlerManager.ListPRs)
+	pr.POST("import/", adminOnly, handlerManager.ImportPRs)
+
+	stats := api.Group("/stats/")
+	stats.GET("reviewers/", handlerManager.ReviewerStats)
+	stats.GET("prs/", handlerManager.PRStats)
+
+	admin := api.Group("/admin/")
+	admin.POST("sync/", adminOnly, handlerManager.SyncOrg)
+
+	integrations := api.Group("/integrations/")
+	integrations.POST("gitlab/webhook", handlerManager.GitLabWebhook(cfg.GitLab))
+	integrations.POST("identities/", adminOnly, handlerManager.MapForgeIdentity)
 
 	srv := &http.Server{
 		Addr:         ":" + cfg.ServerPort,
 		Handler:      router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		ReadTimeout:  cfg.HTTP.ReadTimeout,
+		WriteTimeout: cfg.HTTP.WriteTimeout,
+		IdleTimeout:  cfg.HTTP.IdleTimeout,
 	}
 
 	go func() {
@@ -70,11 +160,24 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
+	handlerManager.HealthService.SetDraining()
+	if cfg.DrainDelay > 0 {
+		logger.Log.Info("draining before shutdown", zap.Duration("delay", cfg.DrainDelay))
+		time.Sleep(cfg.DrainDelay)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer func() {
 		cancel()
+		if notifier != nil {
+			logger.Log.Info("waiting for forge notifications")
+			notifier.Wait()
+		}
+		if err := shutdownTracing(context.Background()); err != nil {
+			logger.Log.Error("failed to flush traces", zap.Error(err))
+		}
 		logger.Log.Info("closing database connections")
-		pool.Close()
+		st.Close()
 		logger.Close()
 	}()
 
@@ -83,17 +186,18 @@ func main() {
 	}
 }
 
-func NewPool(ctx context.Context) (*pgxpool.Pool, error) {
-	dbURL := os.Getenv("DB_URL")
-	cfg, err := pgxpool.ParseConfig(dbURL)
+func NewPool(ctx context.Context, c *config.Config) (*pgxpool.Pool, error) {
+	cfg, err := pgxpool.ParseConfig(c.DbURL)
 	if err != nil {
 		return nil, err
 	}
 
-	cfg.MaxConns = 20
-	cfg.MinConns = 2
-	cfg.MaxConnLifetime = 30 * time.Minute
-	cfg.HealthCheckPeriod = 1 * time.Minute
+	cfg.MaxConns = int32(c.Pool.MaxConns)
+	cfg.MinConns = int32(c.Pool.MinConns)
+	cfg.MaxConnLifetime = c.Pool.MaxConnLifetime
+	cfg.MaxConnIdleTime = c.Pool.MaxConnIdleTime
+	cfg.HealthCheckPeriod = c.Pool.HealthCheckPeriod
+	cfg.ConnConfig.Tracer = tracing.QueryTracer{}
 
 	pool, err := pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
@@ -122,6 +226,63 @@ func NewPool(ctx context.Context) (*pgxpool.Pool, error) {
 	return pool, nil
 }
 
+// purgeIdempotencyKeys периодически удаляет истёкшие ключи идемпотентности.
+func purgeIdempotencyKeys(ctx context.Context, svc *service.IdempotencyService) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := svc.PurgeExpired(ctx)
+			if err != nil {
+				logger.Log.Error("failed to purge idempotency keys", zap.Error(err))
+				continue
+			}
+			logger.Log.Info("purged idempotency keys", zap.Int64("count", n))
+		}
+	}
+}
+
+// newForgeNotifier создаёт уведомления для форджей, у которых задан API токен.
+func newForgeNotifier(cfg *config.Config, forgeRepo repo.ForgeRepo) *integrations.Notifier {
+	clients := make(map[string]integrations.ForgeClient)
+	if cfg.GitHub.APIToken != "" {
+		clients[github.Forge] = github.NewClient(cfg.GitHub.APIURL, cfg.GitHub.APIToken)
+	}
+	if cfg.GitLab.APIToken != "" {
+		clients[gitlab.Forge] = gitlab.NewClient(cfg.GitLab.APIURL, cfg.GitLab.APIToken)
+	}
+	if len(clients) == 0 {
+		return nil
+	}
+
+	return integrations.NewNotifier(forgeRepo, clients)
+}
+
+// openStorage открывает хранилище из конфигурации; для Postgres — создаёт пул
+// и применяет миграции, SQLite применяет свои миграции сама.
+func openStorage(ctx context.Context, cfg *config.Config) (*repo.Storage, error) {
+	switch cfg.Storage.Backend {
+	case repo.BackendMemory:
+		return memory.NewStorage(), nil
+	case repo.BackendSQLite:
+		return sqlite.Open(ctx, cfg.Storage.SQLitePath)
+	}
+
+	pool, err := NewPool(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create pool: %w", err)
+	}
+	if err := applyMigrations(ctx, pool); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("apply migrations: %w", err)
+	}
+	return repo.NewPostgresStorage(pool), nil
+}
+
 func applyMigrations(ctx context.Context, pool *pgxpool.Pool) error {
 	return migrations.ApplyMigrations(ctx, pool)
 }
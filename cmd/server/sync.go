@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/andro-kes/avito_test/internal/config"
+	"github.com/andro-kes/avito_test/internal/orgfile"
+	"github.com/andro-kes/avito_test/internal/service"
+)
+
+// runSync — подкоманда `server sync`: то же, что POST /admin/sync/, без запуска API.
+func runSync(args []string) int {
+	fs := flag.NewFlagSet("sync", flag.ContinueOnError)
+	file := fs.String("f", "", "файл с желаемым составом команд (- для stdin)")
+	format := fs.String("format", "", "json, yaml или csv; по умолчанию по расширению файла")
+	dryRun := fs.Bool("dry-run", false, "только показать план, не применяя его")
+	configFile := fs.String("config", "", "файл конфигурации сервиса")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	var configArgs []string
+	if *configFile != "" {
+		configArgs = []string{"-config", *configFile}
+	}
+	cfg, err := config.Load(configArgs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "sync:", err)
+		return 2
+	}
+	if *file == "" {
+		fmt.Fprintln(os.Stderr, "sync: -f is required")
+		fs.Usage()
+		return 2
+	}
+
+	hint := *format
+	if hint == "" {
+		hint = filepath.Ext(*file)
+	}
+	f, err := orgfile.DetectFormat(hint)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "sync:", err)
+		return 2
+	}
+
+	var in io.Reader = os.Stdin
+	if *file != "-" {
+		fh, err := os.Open(*file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "sync:", err)
+			return 1
+		}
+		defer fh.Close()
+		in = fh
+	}
+
+	doc, err := orgfile.Decode(in, f)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "sync:", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	st, err := openStorage(ctx, cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "sync: failed to open storage:", err)
+		return 1
+	}
+	defer st.Close()
+
+	prService := service.NewPRService(st)
+	prService.SetReviewersCount(cfg.Reviewers.Count)
+	syncService := service.NewSyncService(st, service.NewTeamService(st), prService)
+	plan, err := syncService.Sync(ctx, doc, *dryRun)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "sync:", err)
+		return 1
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(plan); err != nil {
+		fmt.Fprintln(os.Stderr, "sync:", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+server:
+  port: 9000
+  read_timeout: 5s
+database:
+  max_conns: 50
+  min_conns: 5
+reviewers:
+  count: 3
+gitlab:
+  project_teams:
+    group/api: backend
+`)
+
+	cfg, err := load(
+		[]string{"-config", path, "-database.max_conns=40"},
+		env(map[string]string{"DB_MAX_CONNS": "30", "SERVE_PORT": "9100", "SHUTDOWN_TIMEOUT": "7"}),
+	)
+	require.NoError(t, err)
+
+	// флаг > окружение > файл > значение по умолчанию
+	require.Equal(t, 40, cfg.Pool.MaxConns)
+	require.Equal(t, "9100", cfg.ServerPort)
+	require.Equal(t, 5*time.Second, cfg.HTTP.ReadTimeout)
+	require.Equal(t, 15*time.Second, cfg.HTTP.WriteTimeout)
+	require.Equal(t, 5, cfg.Pool.MinConns)
+	require.Equal(t, 3, cfg.Reviewers.Count)
+	require.Equal(t, map[string]string{"group/api": "backend"}, cfg.GitLab.ProjectTeams)
+	// старый формат переменных: целое число секунд
+	require.Equal(t, 7*time.Second, cfg.ShutdownTimeout)
+	require.Equal(t, 24*time.Hour, cfg.IdempotencyTTL)
+}
+
+func TestLoadTOML(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+[log]
+level = "warn"
+
+[tracing]
+exporter = "stdout"
+otlp_insecure = true
+`)
+
+	cfg, err := load(nil, env(map[string]string{"CONFIG_FILE": path}))
+	require.NoError(t, err)
+	require.Equal(t, "warn", cfg.LogLevel)
+	require.Equal(t, "stdout", cfg.Tracing.Exporter)
+	require.True(t, cfg.Tracing.Insecure)
+}
+
+func TestLoadValidation(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+database:
+  max_conns: 2
+  min_conns: 5
+  pool_size: 10
+`)
+	_, err := load([]string{"-config", path}, env(nil))
+	require.ErrorContains(t, err, "database.pool_size: unknown setting")
+
+	_, err = load(
+		[]string{"-database.min_conns=5", "-database.max_conns=2", "-log.level=loud"},
+		env(map[string]string{"STATS_TIMEZONE": "Mars/Olympus", "STORAGE": "redis"}),
+	)
+	require.ErrorContains(t, err, "database.min_conns: must be between 0 and database.max_conns (2)")
+	require.ErrorContains(t, err, `log.level: unknown level "loud"`)
+	require.ErrorContains(t, err, `stats.timezone: unknown timezone "Mars/Olympus"`)
+	require.ErrorContains(t, err, "storage.backend: must be one of postgres, sqlite, memory")
+
+	_, err = load(nil, env(map[string]string{"IDEMPOTENCY_TTL_HOURS": "day"}))
+	require.ErrorContains(t, err, `idempotency.ttl: invalid duration "day"`)
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	cfg, err := load(nil, env(map[string]string{
+		"ADMIN_TOKEN":      "s3cret",
+		"GITHUB_API_TOKEN": "ghp_token",
+		"DB_URL":           "postgres://app:hunter2@db:5432/pr?sslmode=disable",
+	}))
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, cfg.Print(&out))
+	require.NotContains(t, out.String(), "s3cret")
+	require.NotContains(t, out.String(), "ghp_token")
+	require.NotContains(t, out.String(), "hunter2")
+	require.Contains(t, out.String(), "admin_token: <redacted>")
+	require.Contains(t, out.String(), "postgres://app:xxxxx@db:5432/pr?sslmode=disable")
+
+	// Вывод читается обратно как файл конфигурации
+	path := writeFile(t, "printed.yaml", out.String())
+	again, err := load([]string{"-config", path}, env(nil))
+	require.NoError(t, err)
+	require.Equal(t, cfg.Pool, again.Pool)
+	require.Equal(t, cfg.HTTP, again.HTTP)
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// readFile читает YAML или TOML и возвращает значения по ключам параметров
+// вида "database.max_conns". Неизвестные ключи считаются ошибкой.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]any
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &doc)
+	case ".toml":
+		err = toml.Unmarshal(data, &doc)
+	default:
+		return nil, fmt.Errorf("%s: unsupported config format %q", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	var errs []error
+	flatten("", doc, values, &errs)
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%s: %w", path, errors.Join(errs...))
+	}
+	return values, nil
+}
+
+func flatten(prefix string, v any, out map[string]string, errs *[]error) {
+	if _, ok := lookupSetting(prefix); ok {
+		out[prefix] = scalar(v)
+		return
+	}
+
+	m, ok := v.(map[string]any)
+	if !ok {
+		if prefix != "" {
+			*errs = append(*errs, fmt.Errorf("%s: unknown setting", prefix))
+		}
+		return
+	}
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		flatten(key, m[k], out, errs)
+	}
+}
+
+// scalar приводит значение из файла к строке, как если бы оно пришло из
+// переменной окружения; таблица превращается в "k1=v1,k2=v2".
+func scalar(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case map[string]any:
+		m := make(map[string]string, len(v))
+		for k, val := range v {
+			m[k] = scalar(val)
+		}
+		return formatMapping(m)
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, scalar(item))
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Print выводит действующую конфигурацию в YAML, пригодном для -config.
+// Секреты скрываются.
+func (c *Config) Print(w io.Writer) error {
+	doc := make(map[string]any)
+	for _, s := range settings {
+		section, name, _ := strings.Cut(s.key, ".")
+		m, ok := doc[section].(map[string]any)
+		if !ok {
+			m = make(map[string]any)
+			doc[section] = m
+		}
+		m[name] = typed(s.display(c))
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// typed возвращает числа и булевы значения без кавычек.
+func typed(v string) any {
+	if n, err := strconv.Atoi(v); err == nil {
+		return n
+	}
+	if v == "true" || v == "false" {
+		return v == "true"
+	}
+	return v
+}
@@ -0,0 +1,169 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	logger "github.com/andro-kes/avito_test/internal/log"
+)
+
+const defaultReloadInterval = 5 * time.Second
+
+// Change — изменение параметра, найденное при перезагрузке. Секреты
+// в Old и New скрыты.
+type Change struct {
+	Key string
+	Old string
+	New string
+	// Applied — false, если параметр вступит в силу только после перезапуска.
+	Applied bool
+}
+
+// Reloader перечитывает конфигурацию по SIGHUP и при изменении файла и
+// применяет параметры, которые можно менять без перезапуска (log.level,
+// reviewers.count, ratelimit.*). Некорректная конфигурация отклоняется
+// целиком, действующая остаётся прежней.
+type Reloader struct {
+	args      []string
+	lookupEnv func(string) (string, bool)
+	apply     func(*Config)
+	// Interval — период проверки файла конфигурации на изменения.
+	Interval time.Duration
+
+	mu      sync.Mutex
+	current *Config
+	// stamp — состояние файла, из которого загружена current.
+	stamp fileStamp
+}
+
+// NewReloader создаёт Reloader для конфигурации, загруженной через Load(args).
+// apply вызывается с новой конфигурацией после каждого успешного изменения.
+func NewReloader(args []string, current *Config, apply func(*Config)) *Reloader {
+	return &Reloader{
+		args:      args,
+		lookupEnv: os.LookupEnv,
+		apply:     apply,
+		Interval:  defaultReloadInterval,
+		current:   current,
+		stamp:     statFile(current.File),
+	}
+}
+
+// Current возвращает действующую конфигурацию.
+func (r *Reloader) Current() *Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// Reload загружает конфигурацию заново и применяет изменившиеся параметры.
+func (r *Reloader) Reload() ([]Change, error) {
+	next, err := load(r.args, r.lookupEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	updated := *r.current
+	applied := false
+	var changes []Change
+	for _, s := range settings {
+		v := s.get(next)
+		if s.get(r.current) == v {
+			continue
+		}
+		changes = append(changes, Change{
+			Key:     s.key,
+			Old:     s.display(r.current),
+			New:     s.display(next),
+			Applied: s.reload,
+		})
+		if s.reload {
+			if err := s.set(&updated, v); err != nil {
+				return nil, err
+			}
+			applied = true
+		}
+	}
+
+	if applied {
+		r.current = &updated
+		r.apply(&updated)
+	}
+	return changes, nil
+}
+
+// Run следит за SIGHUP и файлом конфигурации до отмены ctx.
+func (r *Reloader) Run(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	path := r.Current().File
+	var tick <-chan time.Time
+	if path != "" {
+		ticker := time.NewTicker(r.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			r.reload("sighup")
+		case <-tick:
+			s := statFile(path)
+			if s == r.stamp {
+				continue
+			}
+			r.stamp = s
+			r.reload("file")
+		}
+	}
+}
+
+func (r *Reloader) reload(trigger string) {
+	changes, err := r.Reload()
+	if err != nil {
+		logger.Log.Error("config reload rejected, keeping current config",
+			zap.String("trigger", trigger), zap.Error(err))
+		return
+	}
+
+	for _, ch := range changes {
+		fields := []zap.Field{zap.String("key", ch.Key), zap.String("old", ch.Old), zap.String("new", ch.New)}
+		if ch.Applied {
+			logger.Log.Info("config setting changed", fields...)
+		} else {
+			logger.Log.Warn("config setting changed, restart required to apply", fields...)
+		}
+	}
+	logger.Log.Info("config reloaded", zap.String("trigger", trigger), zap.Int("changes", len(changes)))
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// statFile возвращает отметку изменения файла; нулевую, если его нет.
+func statFile(path string) fileStamp {
+	if path == "" {
+		return fileStamp{}
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: fi.ModTime(), size: fi.Size()}
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	logger "github.com/andro-kes/avito_test/internal/log"
+)
+
+// TestMain задаёт логгер один раз: Reloader.Run пишет в него из своей горутины.
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+func newTestReloader(t *testing.T, content string) (*Reloader, string, chan *Config) {
+	t.Helper()
+	path := writeFile(t, "config.yaml", content)
+	args := []string{"-config", path}
+
+	cfg, err := load(args, env(nil))
+	require.NoError(t, err)
+
+	applied := make(chan *Config, 10)
+	r := NewReloader(args, cfg, func(c *Config) { applied <- c })
+	r.lookupEnv = env(nil)
+	return r, path, applied
+}
+
+func TestReload(t *testing.T) {
+	r, path, applied := newTestReloader(t, `
+log:
+  level: info
+reviewers:
+  count: 2
+auth:
+  admin_token: old
+`)
+
+	require.NoError(t, os.WriteFile(path, []byte(`
+log:
+  level: warn
+reviewers:
+  count: 3
+ratelimit:
+  rps: 10
+server:
+  port: 9000
+auth:
+  admin_token: new
+`), 0o600))
+
+	changes, err := r.Reload()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []Change{
+		{Key: "server.port", Old: "8080", New: "9000"},
+		{Key: "reviewers.count", Old: "2", New: "3", Applied: true},
+		{Key: "log.level", Old: "info", New: "warn", Applied: true},
+		{Key: "ratelimit.rps", Old: "0", New: "10", Applied: true},
+		{Key: "auth.admin_token", Old: "<redacted>", New: "<redacted>"},
+	}, changes)
+
+	cfg := <-applied
+	require.Same(t, cfg, r.Current())
+	require.Equal(t, "warn", cfg.LogLevel)
+	require.Equal(t, 3, cfg.Reviewers.Count)
+	require.Equal(t, 10, cfg.RateLimit.RPS)
+	// параметры, требующие перезапуска, не меняются
+	require.Equal(t, "8080", cfg.ServerPort)
+	require.Equal(t, "old", cfg.AdminToken)
+}
+
+func TestReloadRejectsInvalidConfig(t *testing.T) {
+	r, path, applied := newTestReloader(t, "log:\n  level: info\n")
+	before := r.Current()
+
+	require.NoError(t, os.WriteFile(path, []byte("log:\n  level: loud\nreviewers:\n  count: 5\n"), 0o600))
+	_, err := r.Reload()
+	require.ErrorContains(t, err, `log.level: unknown level "loud"`)
+	require.Same(t, before, r.Current())
+	require.Empty(t, applied)
+
+	require.NoError(t, os.WriteFile(path, []byte("log:\n  level: info\n"), 0o600))
+	changes, err := r.Reload()
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	require.Empty(t, applied)
+}
+
+func TestReloaderWatchesFile(t *testing.T) {
+	r, path, applied := newTestReloader(t, "log:\n  level: info\n")
+	r.Interval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Run(ctx)
+	}()
+	// Run должен завершиться до конца теста, иначе он гонится с файлом и логгером
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	require.NoError(t, os.WriteFile(path, []byte("log:\n  level: error\n"), 0o600))
+	select {
+	case cfg := <-applied:
+		require.Equal(t, "error", cfg.LogLevel)
+	case <-time.After(5 * time.Second):
+		t.Fatal("config file change was not picked up")
+	}
+}
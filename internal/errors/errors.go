@@ -1,16 +1,37 @@
 package prerrors
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
 
 type Error struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details,omitempty"`
+	// Cause — исходная ошибка. В ответ клиенту не попадает, только в логи.
+	Cause error `json:"-"`
 }
 
 func (e *Error) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Cause)
+	}
 	return fmt.Sprintf("%s: %s", e.Code, e.Message)
 }
 
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is сравнивает ошибки по коду, чтобы errors.Is находил и обёрнутые ошибки,
+// и ошибки с уточнённым сообщением.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
 func New(code, message string) *Error {
 	return &Error{
 		Code:    code,
@@ -18,6 +39,41 @@ func New(code, message string) *Error {
 	}
 }
 
+// FieldError — нарушенное правило для одного поля запроса.
+type FieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// Wrap возвращает копию base с причиной cause.
+func Wrap(base *Error, cause error) *Error {
+	return &Error{
+		Code:    base.Code,
+		Message: base.Message,
+		Details: base.Details,
+		Cause:   cause,
+	}
+}
+
+// Invalid возвращает VALIDATION_ERROR со списком нарушений по полям.
+func Invalid(details ...FieldError) *Error {
+	return &Error{
+		Code:    ErrValidation.Code,
+		Message: "request validation failed",
+		Details: details,
+	}
+}
+
+// Validation возвращает VALIDATION_ERROR с сообщением.
+func Validation(format string, args ...any) *Error {
+	return New(ErrValidation.Code, fmt.Sprintf(format, args...))
+}
+
+// Response — тело ответа с ошибкой.
+type Response struct {
+	Error *Error `json:"error"`
+}
+
 var (
 	ErrTeamExists = New(
 		"TEAM_EXISTS",
@@ -49,8 +105,84 @@ var (
 		"resource not found",
 	)
 
+	ErrIdempotencyKeyReused = New(
+		"IDEMPOTENCY_KEY_REUSED",
+		"idempotency key was used with a different request",
+	)
+
+	ErrIdempotencyInProgress = New(
+		"IDEMPOTENCY_KEY_IN_PROGRESS",
+		"request with this idempotency key is still in progress",
+	)
+
+	ErrInvalidQuery = New(
+		"INVALID_QUERY",
+		"invalid query parameters",
+	)
+
+	ErrInvalidPR = New(
+		"INVALID_PR",
+		"invalid pull request",
+	)
+
+	ErrInvalidTeam = New(
+		"INVALID_TEAM",
+		"invalid team",
+	)
+
+	ErrValidation = New(
+		"VALIDATION_ERROR",
+		"invalid request",
+	)
+
+	ErrUnauthorized = New(
+		"UNAUTHORIZED",
+		"authorization required",
+	)
+
+	ErrConflict = New(
+		"CONFLICT",
+		"resource state conflicts with the request",
+	)
+
+	ErrRateLimited = New(
+		"RATE_LIMITED",
+		"too many requests",
+	)
+
 	ErrServer = New(
 		"SERVER_ERROR",
 		"internal server error",
 	)
 )
+
+var statuses = map[string]int{
+	ErrValidation.Code:            http.StatusBadRequest,
+	ErrInvalidQuery.Code:          http.StatusBadRequest,
+	ErrInvalidPR.Code:             http.StatusBadRequest,
+	ErrInvalidTeam.Code:           http.StatusBadRequest,
+	ErrTeamExists.Code:            http.StatusBadRequest,
+	ErrUnauthorized.Code:          http.StatusUnauthorized,
+	ErrNotFound.Code:              http.StatusNotFound,
+	ErrPRExists.Code:              http.StatusConflict,
+	ErrPRMerged.Code:              http.StatusConflict,
+	ErrNotAssigned.Code:           http.StatusConflict,
+	ErrNoCandidate.Code:           http.StatusConflict,
+	ErrConflict.Code:              http.StatusConflict,
+	ErrIdempotencyInProgress.Code: http.StatusConflict,
+	ErrIdempotencyKeyReused.Code:  http.StatusUnprocessableEntity,
+	ErrRateLimited.Code:           http.StatusTooManyRequests,
+}
+
+// Status возвращает HTTP-статус ответа для err: по коду для *Error,
+// 500 для остальных ошибок и неизвестных кодов.
+func Status(err error) int {
+	var perr *Error
+	if !errors.As(err, &perr) {
+		return http.StatusInternalServerError
+	}
+	if status, ok := statuses[perr.Code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+)
+
+// abort прерывает обработку запроса с ошибкой; ответ формирует middleware.Errors.
+func abort(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// requiredQuery возвращает обязательный параметр запроса. При его отсутствии запрос уже прерван.
+func requiredQuery(c *gin.Context, name string) (string, bool) {
+	v := c.Query(name)
+	if v == "" {
+		abort(c, prerrors.Validation("%s is required", name))
+		return "", false
+	}
+	return v, true
+}
@@ -1,21 +1,30 @@
 package handlers
 
 import (
-	"github.com/jackc/pgx/v5/pgxpool"
-
+	"github.com/andro-kes/avito_test/internal/repo"
 	"github.com/andro-kes/avito_test/internal/service"
 )
 
 type HandlerManager struct {
-	TeamService *service.TeamService
-	PRService   *service.PRService
-	UserService *service.UserService
+	TeamService   *service.TeamService
+	PRService     *service.PRService
+	UserService   *service.UserService
+	ForgeService  *service.ForgeService
+	SyncService   *service.SyncService
+	StatsService  *service.StatsService
+	HealthService *service.HealthService
 }
 
-func NewHandlerManager(pool *pgxpool.Pool) *HandlerManager {
+func NewHandlerManager(st *repo.Storage) *HandlerManager {
+	prService := service.NewPRService(st)
+	teamService := service.NewTeamService(st)
 	return &HandlerManager{
-		TeamService: service.NewTeamService(pool),
-		UserService: service.NewUserService(pool),
-		PRService:   service.NewPRService(pool),
+		TeamService:   teamService,
+		UserService:   service.NewUserService(st),
+		PRService:     prService,
+		ForgeService:  service.NewForgeService(st, prService),
+		SyncService:   service.NewSyncService(st, teamService, prService),
+		StatsService:  service.NewStatsService(st),
+		HealthService: service.NewHealthService(st),
 	}
 }
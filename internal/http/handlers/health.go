@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/andro-kes/avito_test/internal/models"
+)
+
+// Live отвечает, пока процесс способен обрабатывать запросы; зависимости не проверяет.
+func (hm *HandlerManager) Live(c *gin.Context) {
+	c.JSON(200, gin.H{
+		"status": models.HealthUp,
+	})
+}
+
+func (hm *HandlerManager) Ready(c *gin.Context) {
+	report := hm.HealthService.Readiness(c.Request.Context())
+	if report.Status != models.HealthUp {
+		c.JSON(503, report)
+		return
+	}
+	c.JSON(200, report)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"io"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/andro-kes/avito_test/internal/config"
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/integrations/gitlab"
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/service"
+)
+
+func (hm *HandlerManager) GitLabWebhook(cfg config.GitLabConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader(gitlab.TokenHeader)
+		if cfg.WebhookToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(cfg.WebhookToken)) != 1 {
+			abort(c, prerrors.New(prerrors.ErrUnauthorized.Code, "invalid webhook token"))
+			return
+		}
+
+		if c.GetHeader(gitlab.EventHeader) != gitlab.MergeRequestHook {
+			c.JSON(200, gin.H{"status": service.ForgeEventIgnored})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			abort(c, prerrors.Wrap(prerrors.ErrValidation, err))
+			return
+		}
+
+		ev, err := gitlab.ParseMergeRequestEvent(body)
+		if err != nil {
+			abort(c, prerrors.Wrap(prerrors.ErrValidation, err))
+			return
+		}
+		if ev == nil {
+			c.JSON(200, gin.H{"status": service.ForgeEventIgnored})
+			return
+		}
+		ev.DeliveryID = c.GetHeader(gitlab.DeliveryHeader)
+		ev.TeamName = cfg.ProjectTeams[ev.Project]
+
+		ctx := c.Request.Context()
+		status, err := hm.ForgeService.HandleEvent(ctx, ev)
+		if err != nil {
+			abort(c, err)
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"status":          status,
+			"pull_request_id": service.ForgePullRequestId(ev.Forge, ev.Project, ev.Number),
+		})
+	}
+}
+
+func (hm *HandlerManager) MapForgeIdentity(c *gin.Context) {
+	var identity models.ForgeIdentity
+	if !hm.bindJSON(c, &identity) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := hm.ForgeService.MapIdentity(ctx, identity); err != nil {
+		abort(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"identity": identity,
+	})
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	logger "github.com/andro-kes/avito_test/internal/log"
+)
+
+// logFields дописывает в логгер запроса user_id и pr_id, известные только
+// после разбора тела.
+func logFields(c *gin.Context, userId, prId string) {
+	var fields []zap.Field
+	if userId != "" {
+		fields = append(fields, zap.String("user_id", userId))
+	}
+	if prId != "" {
+		fields = append(fields, zap.String("pr_id", prId))
+	}
+	if len(fields) == 0 {
+		return
+	}
+	c.Request = c.Request.WithContext(logger.With(c.Request.Context(), fields...))
+}
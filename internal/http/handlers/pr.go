@@ -1,41 +1,25 @@
 package handlers
 
 import (
-	"errors"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"go.uber.org/zap"
 
 	prerrors "github.com/andro-kes/avito_test/internal/errors"
-	logger "github.com/andro-kes/avito_test/internal/log"
 	"github.com/andro-kes/avito_test/internal/models"
 )
 
 func (hm *HandlerManager) CreatePR(c *gin.Context) {
 	var pr models.PullRequestShort
-	if err := c.ShouldBindJSON(&pr); err != nil {
-		logger.Log.Error("Не десериализовать объект", zap.Error(err))
-		c.AbortWithStatusJSON(404, prerrors.ErrNotFound)
+	if !hm.bindJSON(c, &pr) {
 		return
 	}
+	logFields(c, pr.AuthorId, pr.PullRequestId)
 
 	ctx := c.Request.Context()
-	exists, err := hm.PRService.CheckExistingPR(ctx, pr.PullRequestId)
-	if err != nil {
-		logger.Log.Error("Server error", zap.Error(err))
-		c.AbortWithStatusJSON(500, prerrors.ErrServer)
-		return
-	}
-	if exists {
-		logger.Log.Error("PR существует", zap.Error(err))
-		c.AbortWithStatusJSON(409, prerrors.ErrPRExists)
-		return
-	}
-
 	createdPR, err := hm.PRService.CreatePR(ctx, &pr)
 	if err != nil {
-		logger.Log.Error("Server error", zap.Error(err))
-		c.AbortWithStatusJSON(500, prerrors.ErrServer)
+		abort(c, err)
 		return
 	}
 
@@ -45,16 +29,16 @@ func (hm *HandlerManager) CreatePR(c *gin.Context) {
 }
 
 func (hm *HandlerManager) MergePR(c *gin.Context) {
-	var pr models.PullRequestShort
-	if err := c.ShouldBindJSON(&pr); err != nil {
-		c.AbortWithStatusJSON(404, prerrors.ErrNotFound)
+	var pr models.MergePRRequest
+	if !hm.bindJSON(c, &pr) {
 		return
 	}
+	logFields(c, "", pr.PullRequestId)
 
 	ctx := c.Request.Context()
 	merged, err := hm.PRService.MergePR(ctx, pr.PullRequestId)
 	if err != nil {
-		c.AbortWithStatusJSON(404, prerrors.ErrNotFound)
+		abort(c, err)
 		return
 	}
 
@@ -65,54 +49,100 @@ func (hm *HandlerManager) MergePR(c *gin.Context) {
 
 func (hm *HandlerManager) ReassignReviewer(c *gin.Context) {
 	var r models.ReassignRequest
-	if err := c.ShouldBindJSON(&r); err != nil {
-		c.AbortWithStatusJSON(404, prerrors.ErrNotFound)
+	if !hm.bindJSON(c, &r) {
 		return
 	}
+	logFields(c, r.OldUserId, r.PullRequestId)
 
 	ctx := c.Request.Context()
-	err := hm.PRService.IsMerged(ctx, r.PullRequestId)
+	pr, replaced_by, err := hm.PRService.ReassignReviewer(ctx, r.PullRequestId, r.OldUserId)
 	if err != nil {
-		if errors.Is(err, prerrors.ErrPRMerged) {
-			c.AbortWithStatusJSON(409, prerrors.ErrPRMerged)
+		abort(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"pr":          pr,
+		"replaced_by": replaced_by,
+	})
+}
+
+func (hm *HandlerManager) GetUserReview(c *gin.Context) {
+	userId, ok := requiredQuery(c, "user_id")
+	if !ok {
+		return
+	}
+
+	rq := models.ReviewQuery{
+		Status: c.Query("status"),
+		Cursor: c.Query("cursor"),
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			abort(c, prerrors.ErrInvalidQuery)
 			return
 		}
-		c.AbortWithStatusJSON(404, prerrors.ErrNotFound)
+		rq.Limit = limit
+	}
+	since, err := queryTime(c, "since")
+	if err != nil {
+		abort(c, prerrors.Wrap(prerrors.ErrInvalidQuery, err))
 		return
 	}
+	rq.Since = since
 
-	pr, replaced_by, err := hm.PRService.ReassignReviewer(ctx, r.PullRequestId, r.OldUserId)
+	ctx := c.Request.Context()
+	reviews, err := hm.PRService.GetReview(ctx, userId, rq)
 	if err != nil {
-		if errors.Is(err, prerrors.ErrNoCandidate) {
-			c.AbortWithStatusJSON(409, prerrors.ErrNoCandidate)
-			return
-		}
-		if errors.Is(err, prerrors.ErrNotAssigned) {
-			c.AbortWithStatusJSON(409, prerrors.ErrNotAssigned)
-			return
-		}
-		c.AbortWithStatusJSON(404, prerrors.ErrNotFound)
+		abort(c, err)
+		return
+	}
+
+	resp := gin.H{
+		"user_id":       userId,
+		"pull_requests": reviews.PullRequests,
+		"has_more":      reviews.HasMore,
+	}
+	if reviews.NextCursor != "" {
+		resp["next_cursor"] = reviews.NextCursor
+	}
+	c.JSON(200, resp)
+}
+
+func (hm *HandlerManager) GetPR(c *gin.Context) {
+	prId, ok := requiredQuery(c, "pull_request_id")
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	pr, err := hm.PRService.GetPR(ctx, prId)
+	if err != nil {
+		abort(c, err)
 		return
 	}
 
 	c.JSON(200, gin.H{
-		"pr":          pr,
-		"replaced_by": replaced_by,
+		"pr": pr,
 	})
 }
 
-func (hm *HandlerManager) GetUserReview(c *gin.Context) {
-	userId := c.Query("user_id")
+func (hm *HandlerManager) ReviewPR(c *gin.Context) {
+	var r models.ReviewDecisionRequest
+	if !hm.bindJSON(c, &r) {
+		return
+	}
+	logFields(c, r.UserId, r.PullRequestId)
 
 	ctx := c.Request.Context()
-	reviews, err := hm.PRService.GetReview(ctx, userId)
+	pr, err := hm.PRService.SetDecision(ctx, r.PullRequestId, r.UserId, r.Decision)
 	if err != nil {
-		c.AbortWithStatusJSON(500, prerrors.ErrServer)
+		abort(c, err)
 		return
 	}
 
 	c.JSON(200, gin.H{
-		"user_id":       userId,
-		"pull_requests": reviews,
+		"pr": pr,
 	})
 }
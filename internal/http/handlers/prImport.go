@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/service"
+)
+
+const maxImportLineSize = 1 << 20
+
+func (hm *HandlerManager) ImportPRs(c *gin.Context) {
+	rows, err := decodePRImportRows(c.Request.Body, c.ContentType())
+	if err != nil {
+		abort(c, prerrors.Wrap(prerrors.ErrValidation, err))
+		return
+	}
+	if len(rows) == 0 {
+		abort(c, prerrors.Validation("import batch is empty"))
+		return
+	}
+
+	ctx := c.Request.Context()
+	results, err := hm.PRService.ImportPRs(ctx, rows)
+	if err != nil {
+		abort(c, err)
+		return
+	}
+
+	imported := 0
+	for _, r := range results {
+		if r.Status == models.PRImportImported {
+			imported++
+		}
+	}
+
+	c.JSON(200, gin.H{
+		"imported": imported,
+		"failed":   len(results) - imported,
+		"results":  results,
+	})
+}
+
+// decodePRImportRows принимает JSON-массив или NDJSON.
+// Ошибка в отдельном элементе попадает в строку, а не прерывает разбор.
+func decodePRImportRows(r io.Reader, contentType string) ([]service.PRImportRow, error) {
+	br := bufio.NewReader(r)
+	if !strings.Contains(contentType, "ndjson") {
+		first, err := peekNonSpace(br)
+		if err != nil {
+			return nil, err
+		}
+		if first == '[' {
+			return decodeJSONArray(br)
+		}
+	}
+
+	return decodeNDJSON(br)
+}
+
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return 0, nil
+			}
+			return 0, err
+		}
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			return b, br.UnreadByte()
+		}
+	}
+}
+
+func decodeJSONArray(r io.Reader) ([]service.PRImportRow, error) {
+	dec := json.NewDecoder(r)
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	rows := make([]service.PRImportRow, 0)
+	for dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		rows = append(rows, decodePRImportRow(raw))
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func decodeNDJSON(r io.Reader) ([]service.PRImportRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLineSize)
+
+	rows := make([]service.PRImportRow, 0)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		rows = append(rows, decodePRImportRow(line))
+	}
+
+	return rows, scanner.Err()
+}
+
+func decodePRImportRow(raw []byte) service.PRImportRow {
+	var row service.PRImportRow
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	row.Err = dec.Decode(&row.PR)
+	return row
+}
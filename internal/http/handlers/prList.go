@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/models"
+)
+
+func (hm *HandlerManager) ListPRs(c *gin.Context) {
+	f := models.PRListFilter{
+		Status:     c.Query("status"),
+		AuthorId:   c.Query("author_id"),
+		ReviewerId: c.Query("reviewer_id"),
+		TeamName:   c.Query("team_name"),
+		Name:       c.Query("name"),
+		Sort:       c.Query("sort"),
+	}
+
+	switch c.DefaultQuery("order", "asc") {
+	case "asc":
+	case "desc":
+		f.Desc = true
+	default:
+		abort(c, prerrors.New(prerrors.ErrInvalidQuery.Code, "order must be asc or desc"))
+		return
+	}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			abort(c, prerrors.Wrap(prerrors.ErrInvalidQuery, err))
+			return
+		}
+		f.Limit = limit
+	}
+
+	for name, dst := range map[string]**time.Time{
+		"created_from": &f.CreatedFrom,
+		"created_to":   &f.CreatedTo,
+		"merged_from":  &f.MergedFrom,
+		"merged_to":    &f.MergedTo,
+	} {
+		t, err := queryTime(c, name)
+		if err != nil {
+			abort(c, prerrors.Wrap(prerrors.ErrInvalidQuery, err))
+			return
+		}
+		*dst = t
+	}
+
+	ctx := c.Request.Context()
+	page, err := hm.PRService.ListPRs(ctx, f, c.Query("cursor"))
+	if err != nil {
+		abort(c, err)
+		return
+	}
+
+	c.JSON(200, page)
+}
+
+// queryTime разбирает параметр в RFC 3339 или как дату YYYY-MM-DD (полночь UTC).
+// Время приводится к UTC, в котором хранятся created_at и merged_at.
+func queryTime(c *gin.Context, name string) (*time.Time, error) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		t, err = time.Parse(time.DateOnly, v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	t = t.UTC()
+	return &t, nil
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"io"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	logger "github.com/andro-kes/avito_test/internal/log"
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/service"
+)
+
+func (hm *HandlerManager) ReviewerStats(c *gin.Context) {
+	w, ok := bindStatsWindow(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	report, err := hm.StatsService.ReviewerStats(ctx, w, c.Query("team_name"))
+	if err != nil {
+		abort(c, err)
+		return
+	}
+
+	c.JSON(200, report)
+}
+
+func (hm *HandlerManager) PRStats(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		abort(c, prerrors.New(prerrors.ErrInvalidQuery.Code, "format must be json or csv"))
+		return
+	}
+
+	w, ok := bindStatsWindow(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	report, err := hm.StatsService.PRThroughput(ctx, w, c.Query("tz"), c.Query("team_name"))
+	if err != nil {
+		abort(c, err)
+		return
+	}
+
+	if format == "json" {
+		c.JSON(200, report)
+		return
+	}
+
+	c.Status(200)
+	c.Header("Content-Type", "text/csv")
+	if err := writeThroughputCSV(c.Writer, report.Weeks); err != nil {
+		logger.Ctx(c.Request.Context()).Error("Не удалось выгрузить статистику", zap.Error(err))
+	}
+}
+
+// writeThroughputCSV пишет по строке на команду и неделю; распределение по числу
+// ревьюверов разворачивается в колонки reviewers_<n> для всех встретившихся n.
+func writeThroughputCSV(w io.Writer, weeks []models.PRThroughput) error {
+	counts := make([]int, 0, 3)
+	for _, week := range weeks {
+		for n := range week.ReviewerCounts {
+			if !slices.Contains(counts, n) {
+				counts = append(counts, n)
+			}
+		}
+	}
+	slices.Sort(counts)
+
+	header := []string{
+		"team_name", "week", "opened", "merged", "closed",
+		"median_open_to_merge_seconds", "p90_open_to_merge_seconds",
+	}
+	for _, n := range counts {
+		header = append(header, "reviewers_"+strconv.Itoa(n))
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, week := range weeks {
+		record := []string{
+			week.TeamName, week.Week,
+			strconv.Itoa(week.Opened), strconv.Itoa(week.Merged), strconv.Itoa(week.Closed),
+			formatSeconds(week.MedianOpenToMerge), formatSeconds(week.P90OpenToMerge),
+		}
+		for _, n := range counts {
+			record = append(record, strconv.Itoa(week.ReviewerCounts[n]))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatSeconds(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', 0, 64)
+}
+
+// bindStatsWindow читает окно из параметров from и to. При ошибке ответ уже отправлен.
+func bindStatsWindow(c *gin.Context) (models.StatsWindow, bool) {
+	from, err := queryTime(c, "from")
+	if err != nil {
+		abort(c, prerrors.Wrap(prerrors.ErrInvalidQuery, err))
+		return models.StatsWindow{}, false
+	}
+	to, err := queryTime(c, "to")
+	if err != nil {
+		abort(c, prerrors.Wrap(prerrors.ErrInvalidQuery, err))
+		return models.StatsWindow{}, false
+	}
+
+	w, err := service.StatsWindow(from, to)
+	if err != nil {
+		abort(c, err)
+		return w, false
+	}
+	return w, true
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// SyncOrg приводит все команды и пользователей к присланному документу.
+func (hm *HandlerManager) SyncOrg(c *gin.Context) {
+	doc, dryRun, ok := bindOrgDocument(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	plan, err := hm.SyncService.Sync(ctx, doc, dryRun)
+	if err != nil {
+		abort(c, err)
+		return
+	}
+
+	c.JSON(200, plan)
+}
@@ -1,39 +1,31 @@
 package handlers
 
 import (
-	"errors"
-
 	"github.com/gin-gonic/gin"
 
-	prerrors "github.com/andro-kes/avito_test/internal/errors"
 	"github.com/andro-kes/avito_test/internal/models"
 )
 
 func (hm *HandlerManager) AddTeam(c *gin.Context) {
 	var team models.Team
-	if err := c.ShouldBindJSON(&team); err != nil {
-		c.AbortWithStatusJSON(404, prerrors.ErrNotFound)
+	if !hm.bindJSON(c, &team) {
 		return
 	}
 
 	ctx := c.Request.Context()
 	if err := hm.TeamService.CheckUnique(ctx, team.TeamName); err != nil {
-		if errors.Is(err, prerrors.ErrServer) {
-			c.AbortWithStatusJSON(500, err)
-			return
-		}
-		c.AbortWithStatusJSON(400, prerrors.ErrTeamExists)
+		abort(c, err)
 		return
 	}
 
 	if err := hm.TeamService.CreateTeamWithMembers(ctx, team); err != nil {
-		c.AbortWithStatusJSON(500, prerrors.ErrServer)
+		abort(c, err)
 		return
 	}
 
 	newTeam, err := hm.TeamService.GetTeam(ctx, team.TeamName)
 	if err != nil {
-		c.AbortWithStatusJSON(500, prerrors.ErrServer)
+		abort(c, err)
 		return
 	}
 
@@ -45,15 +37,14 @@ func (hm *HandlerManager) AddTeam(c *gin.Context) {
 func (hm *HandlerManager) GetTeam(c *gin.Context) {
 	ctx := c.Request.Context()
 
-	name, ok := c.GetQuery("team_name")
+	name, ok := requiredQuery(c, "team_name")
 	if !ok {
-		c.AbortWithStatusJSON(404, prerrors.ErrNotFound)
 		return
 	}
 
 	team, err := hm.TeamService.GetTeam(ctx, name)
 	if err != nil {
-		c.AbortWithStatusJSON(404, prerrors.ErrNotFound)
+		abort(c, err)
 		return
 	}
 
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	logger "github.com/andro-kes/avito_test/internal/log"
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/orgfile"
+)
+
+func (hm *HandlerManager) ExportTeams(c *gin.Context) {
+	format, err := orgfile.DetectFormat(c.Query("format"))
+	if err != nil {
+		abort(c, prerrors.Wrap(prerrors.ErrInvalidQuery, err))
+		return
+	}
+
+	ctx := c.Request.Context()
+	doc, err := hm.TeamService.ExportTeams(ctx)
+	if err != nil {
+		abort(c, err)
+		return
+	}
+
+	if name, ok := c.GetQuery("team_name"); ok {
+		filtered := make([]models.Team, 0, 1)
+		for _, team := range doc.Teams {
+			if team.TeamName == name {
+				filtered = append(filtered, team)
+			}
+		}
+		if len(filtered) == 0 {
+			abort(c, prerrors.ErrNotFound)
+			return
+		}
+		doc.Teams = filtered
+	}
+
+	c.Status(200)
+	c.Header("Content-Type", orgfile.ContentType(format))
+	if err := orgfile.Encode(c.Writer, format, doc); err != nil {
+		logger.Ctx(c.Request.Context()).Error("Не удалось выгрузить команды", zap.Error(err))
+	}
+}
+
+func (hm *HandlerManager) ImportTeams(c *gin.Context) {
+	doc, dryRun, ok := bindOrgDocument(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	diff, err := hm.TeamService.ImportTeams(ctx, doc, dryRun)
+	if err != nil {
+		abort(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"dry_run": dryRun,
+		"diff":    diff,
+	})
+}
+
+// bindOrgDocument разбирает состав команд в формате из ?format или Content-Type
+// и флаг dry_run. При ошибке ответ уже отправлен.
+func bindOrgDocument(c *gin.Context) (*models.OrgDocument, bool, bool) {
+	hint := c.Query("format")
+	if hint == "" {
+		hint = c.ContentType()
+	}
+	format, err := orgfile.DetectFormat(hint)
+	if err != nil {
+		abort(c, prerrors.Wrap(prerrors.ErrInvalidQuery, err))
+		return nil, false, false
+	}
+
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		abort(c, prerrors.Wrap(prerrors.ErrInvalidQuery, err))
+		return nil, false, false
+	}
+
+	doc, err := orgfile.Decode(c.Request.Body, format)
+	if err != nil {
+		abort(c, prerrors.Wrap(prerrors.ErrValidation, err))
+		return nil, false, false
+	}
+
+	return doc, dryRun, true
+}
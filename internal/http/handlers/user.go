@@ -1,30 +1,27 @@
 package handlers
 
 import (
-	"errors"
-
 	"github.com/gin-gonic/gin"
 
-	prerrors "github.com/andro-kes/avito_test/internal/errors"
 	"github.com/andro-kes/avito_test/internal/models"
 )
 
 func (hm *HandlerManager) SetIsActive(c *gin.Context) {
 	var user models.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.AbortWithStatusJSON(400, prerrors.ErrNotFound)
+	if !hm.bindJSON(c, &user) {
 		return
 	}
+	logFields(c, user.UserId, "")
 
 	ctx := c.Request.Context()
 	if err := hm.UserService.SetIsActive(ctx, user.UserId, user.IsActive); err != nil {
-		c.AbortWithStatusJSON(404, prerrors.ErrNotFound)
+		abort(c, err)
 		return
 	}
 
 	updatedUser, err := hm.UserService.GetUser(ctx, user.UserId)
 	if err != nil {
-		c.AbortWithStatusJSON(404, prerrors.ErrNotFound)
+		abort(c, err)
 		return
 	}
 
@@ -34,20 +31,15 @@ func (hm *HandlerManager) SetIsActive(c *gin.Context) {
 }
 
 func (hm *HandlerManager) CountReview(c *gin.Context) {
-	userId := c.Query("user_id")
-	if userId == "" {
-		c.AbortWithStatusJSON(400, prerrors.ErrNotFound)
+	userId, ok := requiredQuery(c, "user_id")
+	if !ok {
 		return
 	}
 
 	ctx := c.Request.Context()
 	cnt, err := hm.UserService.CountReview(ctx, userId)
 	if err != nil {
-		if errors.Is(err, prerrors.ErrNotFound) {
-			c.AbortWithStatusJSON(404, prerrors.ErrNotFound)
-			return
-		}
-		c.AbortWithStatusJSON(500, prerrors.ErrServer)
+		abort(c, err)
 		return
 	}
 
@@ -58,42 +50,21 @@ func (hm *HandlerManager) CountReview(c *gin.Context) {
 }
 
 type deactivatedRequest struct {
-	UserIds []string `json:"user_ids"`
+	UserIds []string `json:"user_ids" binding:"min=1,dive,notblank"`
 }
 
 func (hm *HandlerManager) DeactivateUsers(c *gin.Context) {
 	var ids deactivatedRequest
-	if err := c.ShouldBindJSON(&ids); err != nil {
-		c.AbortWithStatusJSON(400, prerrors.ErrNotFound)
-		return
-	}
-
-	if len(ids.UserIds) == 0 {
-		c.AbortWithStatusJSON(400, prerrors.ErrNotFound)
+	if !hm.bindJSON(c, &ids) {
 		return
 	}
 
 	ctx := c.Request.Context()
-	err := hm.UserService.DeactivateUsers(ctx, ids.UserIds)
+	result, err := hm.PRService.DeactivateReviewers(ctx, ids.UserIds)
 	if err != nil {
-		c.AbortWithStatusJSON(500, prerrors.ErrServer)
+		abort(c, err)
 		return
 	}
 
-	prsMap, err := hm.PRService.GetListByUsers(ctx, ids.UserIds)
-	if err != nil {
-		c.AbortWithStatusJSON(500, prerrors.ErrServer)
-		return
-	}
-	for _, pr := range prsMap {
-		err := hm.PRService.ReassignDeactivatedUsers(ctx, &pr, ids.UserIds)
-		if err != nil {
-			c.AbortWithStatusJSON(500, prerrors.ErrServer)
-			return
-		}
-	}
-
-	c.JSON(200, gin.H{
-		"deactivated": ids.UserIds,
-	})
+	c.JSON(200, result)
 }
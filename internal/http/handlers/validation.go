@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/service"
+)
+
+// validate проверяет тела запросов по тегам binding. Кроме правил validator
+// зарегистрированы notblank, user_exists и уникальность user_id в составе команды.
+var validate = newValidator()
+
+type usersKey struct{}
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.SetTagName("binding")
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	_ = v.RegisterValidation("notblank", notBlank)
+	_ = v.RegisterValidationCtx("user_exists", userExists)
+	v.RegisterStructValidation(uniqueMembers, models.Team{})
+	return v
+}
+
+// bindJSON разбирает и проверяет тело запроса. При ошибке запрос уже прерван
+// с VALIDATION_ERROR и списком нарушенных полей.
+func (hm *HandlerManager) bindJSON(c *gin.Context, dst any) bool {
+	if err := json.NewDecoder(c.Request.Body).Decode(dst); err != nil {
+		abort(c, decodeError(err))
+		return false
+	}
+
+	ctx := context.WithValue(c.Request.Context(), usersKey{}, hm.UserService)
+	if err := validate.StructCtx(ctx, dst); err != nil {
+		abort(c, validationError(err))
+		return false
+	}
+	return true
+}
+
+func notBlank(fl validator.FieldLevel) bool {
+	return strings.TrimSpace(fl.Field().String()) != ""
+}
+
+// userExists проверяет, что пользователь есть в БД. Ошибку БД здесь не
+// отличить от нарушения правила, поэтому она пропускается и проявится дальше.
+func userExists(ctx context.Context, fl validator.FieldLevel) bool {
+	users, ok := ctx.Value(usersKey{}).(*service.UserService)
+	if !ok || users == nil {
+		return true
+	}
+	_, err := users.GetUser(ctx, fl.Field().String())
+	return !errors.Is(err, prerrors.ErrNotFound)
+}
+
+func uniqueMembers(sl validator.StructLevel) {
+	team := sl.Current().Interface().(models.Team)
+	seen := make(map[string]int, len(team.Members))
+	for i, m := range team.Members {
+		if first, ok := seen[m.UserID]; ok {
+			sl.ReportError(m.UserID, fmt.Sprintf("members[%d].user_id", i), "UserID", "unique", fmt.Sprintf("members[%d].user_id", first))
+			continue
+		}
+		seen[m.UserID] = i
+	}
+}
+
+func decodeError(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return prerrors.Wrap(prerrors.Invalid(prerrors.FieldError{
+			Field:  typeErr.Field,
+			Reason: "must be " + jsonType(typeErr.Type.Kind()),
+		}), err)
+	}
+	return prerrors.Wrap(prerrors.Invalid(prerrors.FieldError{
+		Field:  "body",
+		Reason: "must be a valid JSON object",
+	}), err)
+}
+
+func validationError(err error) error {
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return prerrors.Wrap(prerrors.ErrValidation, err)
+	}
+
+	details := make([]prerrors.FieldError, 0, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		// Пространство имён начинается с имени типа: Team.members[0].user_id
+		_, field, _ := strings.Cut(fe.Namespace(), ".")
+		details = append(details, prerrors.FieldError{
+			Field:  field,
+			Reason: reason(fe),
+		})
+	}
+	return prerrors.Invalid(details...)
+}
+
+func reason(fe validator.FieldError) string {
+	unit := "characters"
+	if fe.Kind() == reflect.Slice {
+		unit = "items"
+	}
+
+	switch fe.Tag() {
+	case "required", "notblank":
+		return "is required"
+	case "min":
+		return fmt.Sprintf("must contain at least %s %s", fe.Param(), unit)
+	case "max":
+		return fmt.Sprintf("must contain at most %s %s", fe.Param(), unit)
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "unique":
+		return "duplicates " + fe.Param()
+	case "user_exists":
+		return "user does not exist"
+	}
+	return "failed " + fe.Tag() + " check"
+}
+
+func jsonType(k reflect.Kind) string {
+	switch k {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Struct, reflect.Map:
+		return "an object"
+	}
+	return "a number"
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/http/middleware"
+)
+
+func TestBindJSONValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hm := &HandlerManager{}
+	router := gin.New()
+	router.Use(middleware.Errors())
+	router.POST("/team/add/", hm.AddTeam)
+	router.POST("/pullRequest/review/", hm.ReviewPR)
+	router.POST("/users/deactivate/", hm.DeactivateUsers)
+
+	cases := []struct {
+		name    string
+		path    string
+		body    string
+		details []prerrors.FieldError
+	}{
+		{
+			name: "duplicate and blank members",
+			path: "/team/add/",
+			body: `{"team_name":" ","members":[
+				{"user_id":"u1","username":"Alice","is_active":true},
+				{"user_id":"u2","username":"","is_active":true},
+				{"user_id":"u1","username":"Alice again","is_active":true}]}`,
+			details: []prerrors.FieldError{
+				{Field: "team_name", Reason: "is required"},
+				{Field: "members[1].username", Reason: "is required"},
+				{Field: "members[2].user_id", Reason: "duplicates members[0].user_id"},
+			},
+		},
+		{
+			name:    "missing members",
+			path:    "/team/add/",
+			body:    `{"team_name":"backend"}`,
+			details: []prerrors.FieldError{{Field: "members", Reason: "is required"}},
+		},
+		{
+			name: "unknown decision",
+			path: "/pullRequest/review/",
+			body: `{"pull_request_id":"pr-1","user_id":"u1","decision":"LGTM"}`,
+			details: []prerrors.FieldError{
+				{Field: "decision", Reason: "must be one of: APPROVED, CHANGES_REQUESTED"},
+			},
+		},
+		{
+			name: "pending decision",
+			path: "/pullRequest/review/",
+			body: `{"pull_request_id":"pr-1","user_id":"u1","decision":"PENDING"}`,
+			details: []prerrors.FieldError{
+				{Field: "decision", Reason: "must be one of: APPROVED, CHANGES_REQUESTED"},
+			},
+		},
+		{
+			name:    "empty user list",
+			path:    "/users/deactivate/",
+			body:    `{"user_ids":[]}`,
+			details: []prerrors.FieldError{{Field: "user_ids", Reason: "must contain at least 1 items"}},
+		},
+		{
+			name:    "wrong type",
+			path:    "/users/deactivate/",
+			body:    `{"user_ids":"u1"}`,
+			details: []prerrors.FieldError{{Field: "user_ids", Reason: "must be an array"}},
+		},
+		{
+			name:    "malformed body",
+			path:    "/team/add/",
+			body:    `{"team_name":`,
+			details: []prerrors.FieldError{{Field: "body", Reason: "must be a valid JSON object"}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+			router.ServeHTTP(rec, req)
+			require.Equal(t, 400, rec.Code)
+
+			var resp prerrors.Response
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			require.Equal(t, prerrors.ErrValidation.Code, resp.Error.Code)
+			require.Equal(t, tc.details, resp.Error.Details)
+		})
+	}
+}
@@ -1,43 +1,32 @@
 package middleware
 
 import (
-	"net/http"
-	"os"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
 )
 
-func Admin() gin.HandlerFunc {
-	token := os.Getenv("ADMIN_TOKEN")
+// Admin пропускает запросы с заголовком "Authorization: Bearer <token>".
+// Пустой token закрывает маршрут.
+func Admin(token string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if token == "" {
-			c.AbortWithStatusJSON(
-				http.StatusUnauthorized,
-				gin.H{"code": "INVALID_TOKEN", "message": "no admin token"},
-			)
+			WriteError(c, prerrors.New(prerrors.ErrUnauthorized.Code, "no admin token"))
 			return
 		}
 		header := c.GetHeader("Authorization")
 		if header == "" {
-			c.AbortWithStatusJSON(
-				http.StatusUnauthorized,
-				gin.H{"code": "INVALID_TOKEN", "message": "no token header"},
-			)
+			WriteError(c, prerrors.New(prerrors.ErrUnauthorized.Code, "no token header"))
 			return
 		}
 		if !strings.HasPrefix(header, "Bearer ") {
-			c.AbortWithStatusJSON(
-				http.StatusUnauthorized,
-				gin.H{"code": "INVALID_TOKEN", "message": "invalid token header"},
-			)
+			WriteError(c, prerrors.New(prerrors.ErrUnauthorized.Code, "invalid token header"))
 			return
 		}
 		if strings.TrimSpace(strings.TrimPrefix(header, "Bearer ")) != token {
-			c.AbortWithStatusJSON(
-				http.StatusUnauthorized,
-				gin.H{"code": "INVALID_TOKEN", "message": "invalid token"},
-			)
+			WriteError(c, prerrors.New(prerrors.ErrUnauthorized.Code, "invalid token"))
 			return
 		}
 		c.Next()
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/repo/db"
+)
+
+// Errors формирует ответ на ошибку, которую обработчик добавил через c.Error:
+// статус определяется по коду ошибки, тело — {"error": {"code", "message"}}.
+// Причины ошибок в ответ не попадают, их пишет в лог AccessLog.
+func Errors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		writeError(c, c.Errors.Last().Err)
+	}
+}
+
+// WriteError прерывает запрос ответом с ошибкой err для middleware,
+// которые отвечают сами, минуя Errors.
+func WriteError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	writeError(c, err)
+}
+
+func writeError(c *gin.Context, err error) {
+	err = db.Classify(err)
+	status := prerrors.Status(err)
+
+	var perr *prerrors.Error
+	if status >= 500 || !errors.As(err, &perr) {
+		perr = prerrors.ErrServer
+	}
+	c.AbortWithStatusJSON(status, prerrors.Response{Error: perr})
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+)
+
+func TestErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"domain", prerrors.ErrPRMerged, 409, "PR_MERGED"},
+		{"wrapped domain", fmt.Errorf("merge: %w", prerrors.ErrNotAssigned), 409, "NOT_ASSIGNED"},
+		{"validation", prerrors.Validation("team_name is required"), 400, "VALIDATION_ERROR"},
+		{"no rows", fmt.Errorf("get user: %w", pgx.ErrNoRows), 404, "NOT_FOUND"},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, 409, "CONFLICT"},
+		{"foreign key violation", &pgconn.PgError{Code: "23503"}, 404, "NOT_FOUND"},
+		{"unknown", errors.New("connection reset"), 500, "SERVER_ERROR"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(Errors())
+			router.GET("/", func(c *gin.Context) {
+				_ = c.Error(tc.err)
+				c.Abort()
+			})
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+			require.Equal(t, tc.status, rec.Code)
+
+			var body map[string]map[string]any
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			require.Equal(t, tc.code, body["error"]["code"])
+			require.NotEmpty(t, body["error"]["message"])
+			require.Len(t, body["error"], 2, "причина не должна попадать в ответ")
+		})
+	}
+}
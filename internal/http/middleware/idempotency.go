@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	logger "github.com/andro-kes/avito_test/internal/log"
+	"github.com/andro-kes/avito_test/internal/service"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// replayedHeaders — заголовки ответа, которые сохраняются и повторяются
+// вместе с телом. Остальные (Date, Content-Length, X-Request-Id)
+// относятся к конкретной отправке.
+var replayedHeaders = []string{
+	"Content-Type",
+	"Content-Disposition",
+	"Content-Language",
+	"Location",
+	"ETag",
+	"Last-Modified",
+	"Cache-Control",
+}
+
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// idempotencyCaller определяет, чей это ключ: запросы с токеном различаются
+// по токену, остальные — по адресу клиента.
+func idempotencyCaller(c *gin.Context) string {
+	if auth := c.GetHeader("Authorization"); auth != "" {
+		return "auth:" + auth
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func savedHeaders(h http.Header) http.Header {
+	saved := make(http.Header)
+	for _, name := range replayedHeaders {
+		if values := h.Values(name); len(values) > 0 {
+			saved[name] = slices.Clone(values)
+		}
+	}
+	return saved
+}
+
+// Idempotency сохраняет ответы POST-запросов с заголовком Idempotency-Key
+// и возвращает сохранённый ответ на повторы того же запроса. Ключ действует
+// только для того же вызывающего.
+func Idempotency(svc *service.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			WriteError(c, prerrors.Validation("%s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength))
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			WriteError(c, prerrors.Wrap(prerrors.ErrValidation, err))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		scoped := service.ScopedKey(idempotencyCaller(c), key)
+		ctx := c.Request.Context()
+		fingerprint := service.Fingerprint(c.Request.Method, c.Request.URL.Path, body)
+		rec, err := svc.Begin(ctx, scoped, fingerprint)
+		if err != nil {
+			WriteError(c, err)
+			return
+		}
+		if rec != nil {
+			for name, values := range rec.Header {
+				c.Writer.Header()[name] = slices.Clone(values)
+			}
+			c.Header(IdempotencyReplayedHeader, "true")
+			c.Status(rec.StatusCode)
+			c.Writer.Write(rec.Body)
+			c.Abort()
+			return
+		}
+
+		// Ответ уже отправлен клиенту, поэтому отмена запроса не должна мешать сохранению.
+		ctx = context.WithoutCancel(ctx)
+		completed := false
+		defer func() {
+			// Паника в обработчике: освобождаем ключ, чтобы повтор не ждал истечения TTL.
+			if !completed {
+				if err := svc.Abort(ctx, scoped); err != nil {
+					logger.Ctx(c.Request.Context()).Error("Не удалось освободить ключ идемпотентности", zap.String("key", key), zap.Error(err))
+				}
+			}
+		}()
+
+		w := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		completed = true
+
+		status := w.Status()
+		if status >= 500 || status == http.StatusUnauthorized || status == http.StatusForbidden {
+			err = svc.Abort(ctx, scoped)
+		} else {
+			err = svc.Complete(ctx, scoped, status, savedHeaders(w.Header()), w.body.Bytes())
+		}
+		if err != nil {
+			logger.Ctx(c.Request.Context()).Error("Не удалось сохранить ключ идемпотентности", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/andro-kes/avito_test/internal/repo/memory"
+	"github.com/andro-kes/avito_test/internal/service"
+)
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	calls := 0
+	router := gin.New()
+	router.Use(Idempotency(service.NewIdempotencyService(memory.NewStorage(), time.Hour)))
+	router.POST("/", func(c *gin.Context) {
+		calls++
+		c.Header("Location", "/items/1")
+		c.Header("X-Request-Id", "first")
+		c.Data(http.StatusCreated, "text/csv", []byte("id\n1\n"))
+	})
+
+	post := func(ip, auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+		req.RemoteAddr = ip + ":1234"
+		req.Header.Set(IdempotencyKeyHeader, "key")
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	require.Equal(t, http.StatusCreated, post("10.0.0.1", "").Code)
+
+	// повтор отдаёт сохранённые тип содержимого и заголовки ответа
+	rec := post("10.0.0.1", "")
+	require.Equal(t, 1, calls)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Equal(t, "true", rec.Header().Get(IdempotencyReplayedHeader))
+	require.Equal(t, "text/csv", rec.Header().Get("Content-Type"))
+	require.Equal(t, "/items/1", rec.Header().Get("Location"))
+	require.Empty(t, rec.Header().Get("X-Request-Id"))
+	require.Equal(t, "id\n1\n", rec.Body.String())
+
+	// тот же ключ другого клиента — отдельный запрос
+	rec = post("10.0.0.2", "")
+	require.Equal(t, 2, calls)
+	require.Empty(t, rec.Header().Get(IdempotencyReplayedHeader))
+
+	// клиенты с токеном различаются по токену, а не по адресу
+	require.Empty(t, post("10.0.0.1", "Bearer a").Header().Get(IdempotencyReplayedHeader))
+	require.Equal(t, "true", post("10.0.0.3", "Bearer a").Header().Get(IdempotencyReplayedHeader))
+	require.Empty(t, post("10.0.0.3", "Bearer b").Header().Get(IdempotencyReplayedHeader))
+	require.Equal(t, 4, calls)
+}
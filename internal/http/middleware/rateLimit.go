@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+)
+
+// bucketsSweepInterval — как часто удаляются корзины клиентов, которые
+// успели полностью восстановиться.
+const bucketsSweepInterval = time.Minute
+
+// RateLimiter ограничивает частоту запросов с одного IP по алгоритму
+// token bucket. Лимиты меняются на ходу через SetLimits.
+type RateLimiter struct {
+	limits atomic.Pointer[rateLimits]
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type rateLimits struct {
+	rps   float64
+	burst float64
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter создаёт ограничитель на rps запросов в секунду с запасом
+// burst. rps = 0 отключает ограничение, burst = 0 означает burst = rps.
+func NewRateLimiter(rps, burst int) *RateLimiter {
+	l := &RateLimiter{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+	l.SetLimits(rps, burst)
+	return l
+}
+
+// SetLimits атомарно меняет лимиты. Накопленные клиентами токены
+// обрезаются до нового burst при следующем запросе.
+func (l *RateLimiter) SetLimits(rps, burst int) {
+	if burst <= 0 {
+		burst = rps
+	}
+	l.limits.Store(&rateLimits{rps: float64(rps), burst: float64(burst)})
+}
+
+// allow списывает токен клиента key. Если токенов нет, возвращает время
+// до появления следующего.
+func (l *RateLimiter) allow(key string) (bool, time.Duration) {
+	limits := l.limits.Load()
+	if limits.rps <= 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= bucketsSweepInterval {
+		l.sweep(now, limits)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: limits.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(limits.burst, b.tokens+now.Sub(b.last).Seconds()*limits.rps)
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / limits.rps * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+func (l *RateLimiter) sweep(now time.Time, limits *rateLimits) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*limits.rps >= limits.burst {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// RateLimit отвечает 429 RATE_LIMITED с заголовком Retry-After, когда
+// клиент превысил лимит.
+func RateLimit(l *RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ok, wait := l.allow(c.ClientIP())
+		if !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			WriteError(c, prerrors.ErrRateLimited)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(1, 2)
+	limiter.now = func() time.Time { return now }
+
+	router := gin.New()
+	router.Use(RateLimit(limiter))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	get := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.RemoteAddr = ip + ":1234"
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	require.Equal(t, http.StatusOK, get("10.0.0.1").Code)
+	require.Equal(t, http.StatusOK, get("10.0.0.1").Code)
+
+	rec := get("10.0.0.1")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "1", rec.Header().Get("Retry-After"))
+	require.JSONEq(t, `{"error":{"code":"RATE_LIMITED","message":"too many requests"}}`, rec.Body.String())
+
+	// лимит считается для каждого клиента отдельно
+	require.Equal(t, http.StatusOK, get("10.0.0.2").Code)
+
+	now = now.Add(time.Second)
+	require.Equal(t, http.StatusOK, get("10.0.0.1").Code)
+	require.Equal(t, http.StatusTooManyRequests, get("10.0.0.1").Code)
+
+	limiter.SetLimits(0, 0)
+	for range 5 {
+		require.Equal(t, http.StatusOK, get("10.0.0.1").Code)
+	}
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	logger "github.com/andro-kes/avito_test/internal/log"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen ограничивает длину чужого X-Request-ID, чтобы не тащить мусор в логи.
+const maxRequestIDLen = 128
+
+// RequestID назначает запросу идентификатор (или берёт его из X-Request-ID),
+// возвращает его в ответе и кладёт в контекст логгер с request_id, route,
+// user_id и pr_id.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > maxRequestIDLen {
+			id = newRequestID()
+		}
+		c.Header(RequestIDHeader, id)
+
+		fields := []zap.Field{
+			zap.String("request_id", id),
+			zap.String("route", route(c)),
+		}
+		if userId := c.Query("user_id"); userId != "" {
+			fields = append(fields, zap.String("user_id", userId))
+		}
+		if prId := c.Query("pull_request_id"); prId != "" {
+			fields = append(fields, zap.String("pr_id", prId))
+		}
+
+		ctx := logger.WithLogger(c.Request.Context(), logger.Log.With(fields...))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// AccessLog пишет по строке на каждый запрос через логгер запроса.
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		fields := []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("client_ip", c.ClientIP()),
+			zap.Int("size", c.Writer.Size()),
+		}
+		if errs := c.Errors.ByType(gin.ErrorTypePrivate).String(); errs != "" {
+			fields = append(fields, zap.String("errors", errs))
+		}
+
+		l := logger.Ctx(c.Request.Context())
+		switch {
+		case status >= 500:
+			l.Error("request", fields...)
+		case status >= 400:
+			l.Warn("request", fields...)
+		default:
+			l.Info("request", fields...)
+		}
+	}
+}
+
+// Recovery перехватывает панику обработчика, пишет её в лог запроса и отвечает 500.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, err any) {
+		logger.Ctx(c.Request.Context()).Error("panic recovered", zap.Any("panic", err), zap.Stack("stack"))
+		writeError(c, prerrors.ErrServer)
+	})
+}
+
+func route(c *gin.Context) string {
+	if r := c.FullPath(); r != "" {
+		return r
+	}
+	return "unmatched"
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	logger "github.com/andro-kes/avito_test/internal/log"
+)
+
+func TestRequestLog(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	logger.Log = zap.New(core)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID(), AccessLog(), Recovery())
+	router.GET("/users/getReview/", func(c *gin.Context) {
+		logger.Ctx(c.Request.Context()).Info("handler")
+		c.Status(200)
+	})
+	router.GET("/panic", func(c *gin.Context) { panic("boom") })
+
+	// Чужой X-Request-ID пробрасывается в ответ и во все записи запроса
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/users/getReview/?user_id=u1", http.NoBody)
+	req.Header.Set(RequestIDHeader, "req-1")
+	router.ServeHTTP(rec, req)
+	require.Equal(t, "req-1", rec.Header().Get(RequestIDHeader))
+
+	entries := logs.TakeAll()
+	require.Len(t, entries, 2)
+	for _, e := range entries {
+		fields := e.ContextMap()
+		require.Equal(t, "req-1", fields["request_id"])
+		require.Equal(t, "/users/getReview/", fields["route"])
+		require.Equal(t, "u1", fields["user_id"])
+	}
+	require.Equal(t, int64(200), entries[1].ContextMap()["status"])
+
+	// Без заголовка идентификатор генерируется, паника превращается в 500
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", http.NoBody))
+	require.Equal(t, 500, rec.Code)
+	require.Len(t, rec.Header().Get(RequestIDHeader), 32)
+
+	entries = logs.TakeAll()
+	require.Len(t, entries, 2)
+	require.Equal(t, "panic recovered", entries[0].Message)
+	require.Equal(t, rec.Header().Get(RequestIDHeader), entries[1].ContextMap()["request_id"])
+}
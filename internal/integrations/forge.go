@@ -0,0 +1,75 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/andro-kes/avito_test/internal/models"
+)
+
+// ForgeClient отражает назначения ревьюверов во внешнем фордже.
+type ForgeClient interface {
+	RequestReviewers(ctx context.Context, pr models.ForgePullRequest, logins []string) error
+	RemoveReviewer(ctx context.Context, pr models.ForgePullRequest, login string) error
+	PostComment(ctx context.Context, pr models.ForgePullRequest, body string) error
+}
+
+// RESTClient — общий JSON-клиент для REST API форджей.
+type RESTClient struct {
+	BaseURL string
+	Header  http.Header
+	HTTP    *http.Client
+}
+
+func NewRESTClient(baseURL string, header http.Header) *RESTClient {
+	return &RESTClient{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Header:  header,
+		HTTP:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Do отправляет in как JSON-тело и декодирует ответ в out, если он не nil.
+func (rc *RESTClient) Do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader = http.NoBody
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rc.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	for k, v := range rc.Header {
+		req.Header[k] = v
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := rc.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, bytes.TrimSpace(msg))
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
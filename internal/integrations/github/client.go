@@ -0,0 +1,60 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/andro-kes/avito_test/internal/integrations"
+	"github.com/andro-kes/avito_test/internal/models"
+)
+
+const (
+	Forge = "github"
+
+	DefaultBaseURL = "https://api.github.com"
+)
+
+// Client работает с GitHub REST API; Project PR — "owner/repo".
+type Client struct {
+	REST *integrations.RESTClient
+}
+
+func NewClient(baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	header := http.Header{}
+	header.Set("Accept", "application/vnd.github+json")
+	header.Set("X-GitHub-Api-Version", "2022-11-28")
+	header.Set("Authorization", "Bearer "+token)
+
+	return &Client{
+		REST: integrations.NewRESTClient(baseURL, header),
+	}
+}
+
+type reviewersRequest struct {
+	Reviewers []string `json:"reviewers"`
+}
+
+func (c *Client) RequestReviewers(ctx context.Context, pr models.ForgePullRequest, logins []string) error {
+	if len(logins) == 0 {
+		return nil
+	}
+	return c.REST.Do(ctx, http.MethodPost, reviewersPath(pr), reviewersRequest{Reviewers: logins}, nil)
+}
+
+func (c *Client) RemoveReviewer(ctx context.Context, pr models.ForgePullRequest, login string) error {
+	return c.REST.Do(ctx, http.MethodDelete, reviewersPath(pr), reviewersRequest{Reviewers: []string{login}}, nil)
+}
+
+func (c *Client) PostComment(ctx context.Context, pr models.ForgePullRequest, body string) error {
+	path := fmt.Sprintf("/repos/%s/issues/%d/comments", pr.Project, pr.Number)
+	return c.REST.Do(ctx, http.MethodPost, path, map[string]string{"body": body}, nil)
+}
+
+func reviewersPath(pr models.ForgePullRequest) string {
+	return fmt.Sprintf("/repos/%s/pulls/%d/requested_reviewers", pr.Project, pr.Number)
+}
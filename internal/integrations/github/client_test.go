@@ -0,0 +1,82 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/andro-kes/avito_test/internal/models"
+)
+
+type recordedRequest struct {
+	Method string
+	Path   string
+	Auth   string
+	Body   map[string]any
+}
+
+func fakeGitHub(t *testing.T) (*httptest.Server, *[]recordedRequest) {
+	t.Helper()
+
+	var requests []recordedRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := recordedRequest{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Auth:   r.Header.Get("Authorization"),
+		}
+		_ = json.NewDecoder(r.Body).Decode(&rec.Body)
+		requests = append(requests, rec)
+
+		if r.URL.Path == "/repos/org/missing/pulls/1/requested_reviewers" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("{}"))
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &requests
+}
+
+func TestClient(t *testing.T) {
+	srv, requests := fakeGitHub(t)
+	client := NewClient(srv.URL, "gh-token")
+	pr := models.ForgePullRequest{Forge: Forge, Project: "org/repo", Number: 12}
+	ctx := context.Background()
+
+	require.NoError(t, client.RequestReviewers(ctx, pr, []string{"alice", "bob"}))
+	require.NoError(t, client.RemoveReviewer(ctx, pr, "alice"))
+	require.NoError(t, client.PostComment(ctx, pr, "hello"))
+
+	require.Len(t, *requests, 3)
+
+	req := (*requests)[0]
+	require.Equal(t, http.MethodPost, req.Method)
+	require.Equal(t, "/repos/org/repo/pulls/12/requested_reviewers", req.Path)
+	require.Equal(t, "Bearer gh-token", req.Auth)
+	require.Equal(t, []any{"alice", "bob"}, req.Body["reviewers"])
+
+	req = (*requests)[1]
+	require.Equal(t, http.MethodDelete, req.Method)
+	require.Equal(t, []any{"alice"}, req.Body["reviewers"])
+
+	req = (*requests)[2]
+	require.Equal(t, "/repos/org/repo/issues/12/comments", req.Path)
+	require.Equal(t, "hello", req.Body["body"])
+}
+
+func TestClientError(t *testing.T) {
+	srv, _ := fakeGitHub(t)
+	client := NewClient(srv.URL, "gh-token")
+	pr := models.ForgePullRequest{Forge: Forge, Project: "org/missing", Number: 1}
+
+	err := client.RequestReviewers(context.Background(), pr, []string{"alice"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "404")
+}
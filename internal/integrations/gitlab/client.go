@@ -0,0 +1,123 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/andro-kes/avito_test/internal/integrations"
+	"github.com/andro-kes/avito_test/internal/models"
+)
+
+const DefaultBaseURL = "https://gitlab.com/api/v4"
+
+// Client работает с GitLab REST API; Project PR — path_with_namespace или id проекта.
+type Client struct {
+	REST *integrations.RESTClient
+}
+
+func NewClient(baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	header := http.Header{}
+	header.Set("PRIVATE-TOKEN", token)
+
+	return &Client{
+		REST: integrations.NewRESTClient(baseURL, header),
+	}
+}
+
+type gitlabUser struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+type mergeRequest struct {
+	Reviewers []gitlabUser `json:"reviewers"`
+}
+
+// RequestReviewers добавляет ревьюверов к уже назначенным:
+// GitLab принимает только полный список reviewer_ids.
+func (c *Client) RequestReviewers(ctx context.Context, pr models.ForgePullRequest, logins []string) error {
+	if len(logins) == 0 {
+		return nil
+	}
+
+	mr, err := c.mergeRequest(ctx, pr)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int64, 0, len(mr.Reviewers)+len(logins))
+	seen := make(map[int64]struct{}, cap(ids))
+	for _, r := range mr.Reviewers {
+		ids = append(ids, r.ID)
+		seen[r.ID] = struct{}{}
+	}
+	for _, login := range logins {
+		id, err := c.userID(ctx, login)
+		if err != nil {
+			return err
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		ids = append(ids, id)
+		seen[id] = struct{}{}
+	}
+
+	return c.setReviewers(ctx, pr, ids)
+}
+
+func (c *Client) RemoveReviewer(ctx context.Context, pr models.ForgePullRequest, login string) error {
+	mr, err := c.mergeRequest(ctx, pr)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int64, 0, len(mr.Reviewers))
+	for _, r := range mr.Reviewers {
+		if r.Username != login {
+			ids = append(ids, r.ID)
+		}
+	}
+	if len(ids) == len(mr.Reviewers) {
+		return nil
+	}
+
+	return c.setReviewers(ctx, pr, ids)
+}
+
+func (c *Client) PostComment(ctx context.Context, pr models.ForgePullRequest, body string) error {
+	return c.REST.Do(ctx, http.MethodPost, mergeRequestPath(pr)+"/notes", map[string]string{"body": body}, nil)
+}
+
+func (c *Client) mergeRequest(ctx context.Context, pr models.ForgePullRequest) (*mergeRequest, error) {
+	var mr mergeRequest
+	if err := c.REST.Do(ctx, http.MethodGet, mergeRequestPath(pr), nil, &mr); err != nil {
+		return nil, err
+	}
+	return &mr, nil
+}
+
+func (c *Client) setReviewers(ctx context.Context, pr models.ForgePullRequest, ids []int64) error {
+	return c.REST.Do(ctx, http.MethodPut, mergeRequestPath(pr), map[string][]int64{"reviewer_ids": ids}, nil)
+}
+
+func (c *Client) userID(ctx context.Context, login string) (int64, error) {
+	var users []gitlabUser
+	if err := c.REST.Do(ctx, http.MethodGet, "/users?username="+url.QueryEscape(login), nil, &users); err != nil {
+		return 0, err
+	}
+	if len(users) == 0 {
+		return 0, fmt.Errorf("gitlab user %q not found", login)
+	}
+	return users[0].ID, nil
+}
+
+func mergeRequestPath(pr models.ForgePullRequest) string {
+	return fmt.Sprintf("/projects/%s/merge_requests/%d", url.PathEscape(pr.Project), pr.Number)
+}
@@ -0,0 +1,86 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/andro-kes/avito_test/internal/models"
+)
+
+// fakeGitLab хранит ревьюверов одного MR и отвечает как GitLab API.
+type fakeGitLab struct {
+	users     map[string]int64
+	reviewers []int64
+	notes     []string
+	token     string
+}
+
+func (f *fakeGitLab) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.token = r.Header.Get("PRIVATE-TOKEN")
+	const mrPath = "/projects/group%2Fbackend/merge_requests/7"
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/users":
+		username := r.URL.Query().Get("username")
+		users := make([]gitlabUser, 0, 1)
+		if id, ok := f.users[username]; ok {
+			users = append(users, gitlabUser{ID: id, Username: username})
+		}
+		_ = json.NewEncoder(w).Encode(users)
+	case r.Method == http.MethodGet && r.URL.EscapedPath() == mrPath:
+		mr := mergeRequest{}
+		for _, id := range f.reviewers {
+			for name, uid := range f.users {
+				if uid == id {
+					mr.Reviewers = append(mr.Reviewers, gitlabUser{ID: id, Username: name})
+				}
+			}
+		}
+		_ = json.NewEncoder(w).Encode(mr)
+	case r.Method == http.MethodPut && r.URL.EscapedPath() == mrPath:
+		var body struct {
+			ReviewerIDs []int64 `json:"reviewer_ids"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.reviewers = body.ReviewerIDs
+		_, _ = w.Write([]byte("{}"))
+	case r.Method == http.MethodPost && r.URL.EscapedPath() == mrPath+"/notes":
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.notes = append(f.notes, body["body"])
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("{}"))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestClient(t *testing.T) {
+	fake := &fakeGitLab{
+		users:     map[string]int64{"alice": 1, "bob": 2, "carol": 3},
+		reviewers: []int64{1},
+	}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	client := NewClient(srv.URL, "gl-token")
+	pr := models.ForgePullRequest{Forge: Forge, Project: "group/backend", Number: 7}
+	ctx := context.Background()
+
+	require.NoError(t, client.RequestReviewers(ctx, pr, []string{"alice", "bob"}))
+	require.Equal(t, []int64{1, 2}, fake.reviewers)
+	require.Equal(t, "gl-token", fake.token)
+
+	require.NoError(t, client.RemoveReviewer(ctx, pr, "alice"))
+	require.Equal(t, []int64{2}, fake.reviewers)
+
+	require.NoError(t, client.PostComment(ctx, pr, "hello"))
+	require.Equal(t, []string{"hello"}, fake.notes)
+
+	require.Error(t, client.RequestReviewers(ctx, pr, []string{"unknown"}))
+}
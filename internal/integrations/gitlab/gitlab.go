@@ -0,0 +1,97 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/andro-kes/avito_test/internal/models"
+)
+
+const (
+	Forge = "gitlab"
+
+	TokenHeader    = "X-Gitlab-Token"
+	EventHeader    = "X-Gitlab-Event"
+	DeliveryHeader = "X-Gitlab-Event-UUID"
+
+	MergeRequestHook = "Merge Request Hook"
+)
+
+type boolChange struct {
+	Previous bool `json:"previous"`
+	Current  bool `json:"current"`
+}
+
+type mergeRequestHook struct {
+	ObjectKind string `json:"object_kind"`
+	User       struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	Project struct {
+		ID                int64  `json:"id"`
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		IID            int64  `json:"iid"`
+		Title          string `json:"title"`
+		Action         string `json:"action"`
+		Draft          bool   `json:"draft"`
+		WorkInProgress bool   `json:"work_in_progress"`
+	} `json:"object_attributes"`
+	Changes struct {
+		Draft          *boolChange `json:"draft"`
+		WorkInProgress *boolChange `json:"work_in_progress"`
+	} `json:"changes"`
+}
+
+// ParseMergeRequestEvent приводит merge request hook к models.ForgeEvent.
+// Для действий, которые сервис не отслеживает, возвращает nil без ошибки.
+func ParseMergeRequestEvent(body []byte) (*models.ForgeEvent, error) {
+	var hook mergeRequestHook
+	if err := json.Unmarshal(body, &hook); err != nil {
+		return nil, err
+	}
+	if hook.ObjectKind != "merge_request" {
+		return nil, nil
+	}
+
+	project := hook.Project.PathWithNamespace
+	if project == "" {
+		project = strconv.FormatInt(hook.Project.ID, 10)
+	}
+
+	attrs := hook.ObjectAttributes
+	ev := &models.ForgeEvent{
+		Forge:       Forge,
+		Project:     project,
+		Number:      attrs.IID,
+		Title:       attrs.Title,
+		AuthorLogin: hook.User.Username,
+		IsDraft:     attrs.Draft || attrs.WorkInProgress,
+	}
+
+	switch attrs.Action {
+	case "open":
+		ev.Action = models.ForgeActionOpen
+	case "reopen":
+		ev.Action = models.ForgeActionReopen
+	case "close":
+		ev.Action = models.ForgeActionClose
+	case "merge":
+		ev.Action = models.ForgeActionMerge
+	case "update":
+		change := hook.Changes.Draft
+		if change == nil {
+			change = hook.Changes.WorkInProgress
+		}
+		if change == nil || change.Previous == change.Current {
+			return nil, nil
+		}
+		ev.Action = models.ForgeActionDraft
+		ev.IsDraft = change.Current
+	default:
+		return nil, nil
+	}
+
+	return ev, nil
+}
@@ -0,0 +1,194 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	logger "github.com/andro-kes/avito_test/internal/log"
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/repo"
+)
+
+type notification func(ctx context.Context, client ForgeClient, link *models.ForgePullRequest) error
+
+// Notifier асинхронно переносит назначения ревьюверов в форджи,
+// из которых пришли PR. PR без связи с форджем пропускаются.
+//
+// Уведомления одного PR отправляются по очереди: клиенты форджей читают
+// и перезаписывают весь список ревьюверов, и параллельные запросы
+// затирали бы изменения друг друга.
+type Notifier struct {
+	Repo    repo.ForgeRepo
+	Clients map[string]ForgeClient
+	Timeout time.Duration
+
+	wg sync.WaitGroup
+	mu sync.Mutex
+	// queues — ждущие отправки уведомления PR; ключ есть, пока PR обрабатывается.
+	queues map[string][]notification
+}
+
+func NewNotifier(r repo.ForgeRepo, clients map[string]ForgeClient) *Notifier {
+	return &Notifier{
+		Repo:    r,
+		Clients: clients,
+		Timeout: 30 * time.Second,
+		queues:  make(map[string][]notification),
+	}
+}
+
+func (n *Notifier) ReviewersAssigned(prId string, reviewers []string) {
+	n.dispatch(prId, func(ctx context.Context, client ForgeClient, link *models.ForgePullRequest) error {
+		logins, err := n.logins(ctx, link, reviewers...)
+		if err != nil {
+			return err
+		}
+
+		requested := make([]string, 0, len(reviewers))
+		for _, id := range reviewers {
+			if login, ok := logins[id]; ok {
+				requested = append(requested, login)
+			}
+		}
+		return client.RequestReviewers(ctx, *link, requested)
+	})
+}
+
+func (n *Notifier) ReviewerReplaced(prId, oldUserId, newUserId string) {
+	n.dispatch(prId, func(ctx context.Context, client ForgeClient, link *models.ForgePullRequest) error {
+		logins, err := n.logins(ctx, link, oldUserId, newUserId)
+		if err != nil {
+			return err
+		}
+		oldLogin, hasOld := logins[oldUserId]
+		newLogin, hasNew := logins[newUserId]
+
+		if hasOld {
+			if err := client.RemoveReviewer(ctx, *link, oldLogin); err != nil {
+				return err
+			}
+		}
+		if hasNew {
+			if err := client.RequestReviewers(ctx, *link, []string{newLogin}); err != nil {
+				return err
+			}
+		}
+
+		switch {
+		case !hasOld:
+			return nil
+		case newUserId == "":
+			return client.PostComment(ctx, *link, fmt.Sprintf("Ревьювер @%s снят: нет доступной замены", oldLogin))
+		case hasNew:
+			return client.PostComment(ctx, *link, fmt.Sprintf("Ревьювер @%s заменён на @%s", oldLogin, newLogin))
+		default:
+			return client.PostComment(ctx, *link, fmt.Sprintf("Ревьювер @%s снят", oldLogin))
+		}
+	})
+}
+
+// Wait дожидается отправки всех запущенных уведомлений.
+func (n *Notifier) Wait() {
+	n.wg.Wait()
+}
+
+// dispatch ставит уведомление в очередь PR и, если очередь ещё никто
+// не разбирает, запускает для неё горутину.
+func (n *Notifier) dispatch(prId string, fn notification) {
+	n.wg.Add(1)
+
+	n.mu.Lock()
+	queue, running := n.queues[prId]
+	n.queues[prId] = append(queue, fn)
+	n.mu.Unlock()
+
+	if !running {
+		go n.drain(prId)
+	}
+}
+
+func (n *Notifier) drain(prId string) {
+	for {
+		n.mu.Lock()
+		queue := n.queues[prId]
+		if len(queue) == 0 {
+			delete(n.queues, prId)
+			n.mu.Unlock()
+			return
+		}
+		fn := queue[0]
+		n.queues[prId] = queue[1:]
+		n.mu.Unlock()
+
+		n.send(prId, fn)
+		n.wg.Done()
+	}
+}
+
+func (n *Notifier) send(prId string, fn notification) {
+	ctx, cancel := context.WithTimeout(context.Background(), n.Timeout)
+	defer cancel()
+
+	link, err := n.Repo.GetPRLink(ctx, prId)
+	if errors.Is(err, prerrors.ErrNotFound) {
+		return
+	}
+	if err != nil {
+		logger.Log.Error("Не удалось найти PR в фордже", zap.String("pr_id", prId), zap.Error(err))
+		return
+	}
+
+	client, ok := n.Clients[link.Forge]
+	if !ok {
+		return
+	}
+
+	if err := fn(ctx, client, link); err != nil {
+		logger.Log.Error(
+			"Не удалось обновить ревьюверов в фордже",
+			zap.String("pr_id", prId),
+			zap.String("forge", link.Forge),
+			zap.Error(err),
+		)
+	}
+}
+
+// logins переводит user_id в логины форджа. Пользователей без сопоставления
+// в ответе нет: их user_id не логин форджа, и фордж отклонил бы весь запрос.
+// Пустые user_id пропускаются.
+func (n *Notifier) logins(ctx context.Context, link *models.ForgePullRequest, userIds ...string) (map[string]string, error) {
+	ids := make([]string, 0, len(userIds))
+	for _, id := range userIds {
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	logins, err := n.Repo.ForgeLogins(ctx, link.Forge, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	unmapped := make([]string, 0)
+	for _, id := range ids {
+		if _, ok := logins[id]; !ok {
+			unmapped = append(unmapped, id)
+		}
+	}
+	if len(unmapped) > 0 {
+		logger.Log.Warn(
+			"Пользователи не сопоставлены с логинами форджа, пропускаем",
+			zap.String("pr_id", link.PullRequestId),
+			zap.String("forge", link.Forge),
+			zap.Strings("user_ids", unmapped),
+		)
+	}
+
+	return logins, nil
+}
@@ -0,0 +1,229 @@
+package integrations_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/integrations"
+	"github.com/andro-kes/avito_test/internal/integrations/github"
+	logger "github.com/andro-kes/avito_test/internal/log"
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/repo"
+	"github.com/andro-kes/avito_test/internal/repo/memory"
+	"github.com/andro-kes/avito_test/internal/service"
+)
+
+type stubForgeRepo struct {
+	repo.ForgeRepo
+	links  map[string]models.ForgePullRequest
+	logins map[string]string
+}
+
+func (s *stubForgeRepo) GetPRLink(_ context.Context, prId string) (*models.ForgePullRequest, error) {
+	link, ok := s.links[prId]
+	if !ok {
+		return nil, prerrors.ErrNotFound
+	}
+	return &link, nil
+}
+
+func (s *stubForgeRepo) ForgeLogins(_ context.Context, _ string, userIds []string) (map[string]string, error) {
+	out := make(map[string]string)
+	for _, id := range userIds {
+		if login, ok := s.logins[id]; ok {
+			out[id] = login
+		}
+	}
+	return out, nil
+}
+
+func TestNotifier(t *testing.T) {
+	logger.Init()
+
+	var mu sync.Mutex
+	var calls []string
+	var requested [][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Reviewers []string `json:"reviewers"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		if r.Method == http.MethodPost && body.Reviewers != nil {
+			requested = append(requested, body.Reviewers)
+		}
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	stub := &stubForgeRepo{
+		links: map[string]models.ForgePullRequest{
+			"github:org/repo!5": {PullRequestId: "github:org/repo!5", Forge: github.Forge, Project: "org/repo", Number: 5},
+		},
+		logins: map[string]string{"u2": "bob-gh"},
+	}
+	notifier := integrations.NewNotifier(stub, map[string]integrations.ForgeClient{
+		github.Forge: github.NewClient(srv.URL, "token"),
+	})
+
+	// PR без связи с форджем пропускается
+	notifier.ReviewersAssigned("pr-local", []string{"u2"})
+	notifier.ReviewersAssigned("github:org/repo!5", []string{"u2", "u3"})
+	notifier.Wait()
+
+	require.Equal(t, []string{"POST /repos/org/repo/pulls/5/requested_reviewers"}, calls)
+	// u3 не сопоставлен с логином GitHub и не отправляется, чтобы не сорвать запрос
+	require.Equal(t, [][]string{{"bob-gh"}}, requested)
+
+	calls = nil
+	stub.logins["u3"] = "carol-gh"
+	notifier.ReviewerReplaced("github:org/repo!5", "u2", "u3")
+	notifier.Wait()
+
+	require.Equal(t, []string{
+		"DELETE /repos/org/repo/pulls/5/requested_reviewers",
+		"POST /repos/org/repo/pulls/5/requested_reviewers",
+		"POST /repos/org/repo/issues/5/comments",
+	}, calls)
+
+	// Замена на несопоставленного пользователя только снимает старого ревьювера
+	calls = nil
+	notifier.ReviewerReplaced("github:org/repo!5", "u3", "u4")
+	notifier.Wait()
+
+	require.Equal(t, []string{
+		"DELETE /repos/org/repo/pulls/5/requested_reviewers",
+		"POST /repos/org/repo/issues/5/comments",
+	}, calls)
+}
+
+// reviewerListClient, как клиент GitLab, читает список ревьюверов и
+// перезаписывает его целиком.
+type reviewerListClient struct {
+	mu        sync.Mutex
+	reviewers []string
+}
+
+func (c *reviewerListClient) list() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.reviewers)
+}
+
+func (c *reviewerListClient) store(reviewers []string) {
+	time.Sleep(10 * time.Millisecond)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reviewers = reviewers
+}
+
+func (c *reviewerListClient) RequestReviewers(_ context.Context, _ models.ForgePullRequest, logins []string) error {
+	c.store(append(c.list(), logins...))
+	return nil
+}
+
+func (c *reviewerListClient) RemoveReviewer(_ context.Context, _ models.ForgePullRequest, login string) error {
+	c.store(slices.DeleteFunc(c.list(), func(r string) bool { return r == login }))
+	return nil
+}
+
+func (c *reviewerListClient) PostComment(context.Context, models.ForgePullRequest, string) error {
+	return nil
+}
+
+// Замены двух ревьюверов одного PR не затирают друг друга.
+func TestNotifierSerializesPR(t *testing.T) {
+	logger.Init()
+
+	stub := &stubForgeRepo{
+		links: map[string]models.ForgePullRequest{
+			"gitlab:org/repo!5": {PullRequestId: "gitlab:org/repo!5", Forge: "gitlab", Project: "org/repo", Number: 5},
+		},
+		logins: map[string]string{"u2": "u2", "u3": "u3", "u4": "u4", "u5": "u5"},
+	}
+	client := &reviewerListClient{reviewers: []string{"u2", "u3"}}
+	notifier := integrations.NewNotifier(stub, map[string]integrations.ForgeClient{"gitlab": client})
+
+	notifier.ReviewerReplaced("gitlab:org/repo!5", "u2", "u4")
+	notifier.ReviewerReplaced("gitlab:org/repo!5", "u3", "u5")
+	notifier.Wait()
+
+	require.Equal(t, []string{"u4", "u5"}, client.list())
+}
+
+// linkCheckingNotifier проверяет связь PR с форджем в момент уведомления,
+// не дожидаясь горутины Notifier.
+type linkCheckingNotifier struct {
+	*integrations.Notifier
+	repo     repo.ForgeRepo
+	linkErrs []error
+}
+
+func (n *linkCheckingNotifier) ReviewersAssigned(prId string, reviewers []string) {
+	_, err := n.repo.GetPRLink(context.Background(), prId)
+	n.linkErrs = append(n.linkErrs, err)
+	n.Notifier.ReviewersAssigned(prId, reviewers)
+}
+
+// Уведомление о ревьюверах нового PR форджа отправляется после коммита
+// транзакции, в которой PR связан с форджем, и поэтому находит связь.
+func TestNotifierSeesLinkOfCreatedPR(t *testing.T) {
+	logger.Init()
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	st := memory.NewStorage()
+	require.NoError(t, service.NewTeamService(st).CreateTeamWithMembers(ctx, models.Team{
+		TeamName: "backend",
+		Members: []models.TeamMember{
+			{UserID: "u1", Username: "Alice", IsActive: true},
+			{UserID: "u2", Username: "Bob", IsActive: true},
+		},
+	}))
+	require.NoError(t, st.Forge.UpsertIdentity(ctx, models.ForgeIdentity{Forge: github.Forge, Login: "bob-gh", UserId: "u2"}))
+
+	notifier := &linkCheckingNotifier{
+		Notifier: integrations.NewNotifier(st.Forge, map[string]integrations.ForgeClient{
+			github.Forge: github.NewClient(srv.URL, "token"),
+		}),
+		repo: st.Forge,
+	}
+	prService := service.NewPRService(st)
+	prService.Notifier = notifier
+	forge := service.NewForgeService(st, prService)
+
+	status, err := forge.HandleEvent(ctx, &models.ForgeEvent{
+		Forge:       github.Forge,
+		Action:      models.ForgeActionOpen,
+		Project:     "org/repo",
+		Number:      5,
+		Title:       "Add search",
+		AuthorLogin: "u1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, service.ForgeEventProcessed, status)
+	notifier.Wait()
+
+	require.Equal(t, []error{nil}, notifier.linkErrs)
+	require.Equal(t, []string{"POST /repos/org/repo/pulls/5/requested_reviewers"}, calls)
+}
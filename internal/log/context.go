@@ -0,0 +1,41 @@
+package logger
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type loggerKey struct{}
+
+// WithLogger кладёт в ctx логгер запроса.
+func WithLogger(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// With добавляет поля к логгеру запроса из ctx.
+func With(ctx context.Context, fields ...zap.Field) context.Context {
+	return WithLogger(ctx, fromContext(ctx).With(fields...))
+}
+
+func fromContext(ctx context.Context) *zap.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*zap.Logger); ok {
+		return l
+	}
+	return Log
+}
+
+// Ctx возвращает логгер запроса из ctx (или глобальный) с trace_id и span_id
+// текущего спана, если он есть.
+func Ctx(ctx context.Context) *zap.Logger {
+	l := fromContext(ctx)
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return l
+	}
+	return l.With(
+		zap.String("trace_id", sc.TraceID().String()),
+		zap.String("span_id", sc.SpanID().String()),
+	)
+}
@@ -2,13 +2,17 @@ package logger
 
 import (
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 var Log *zap.Logger
 
+// Level — уровень логирования, который можно менять без пересоздания логгера.
+var Level = zap.NewAtomicLevelAt(zap.DebugLevel)
+
 func Init() {
 	config := zap.NewProductionConfig()
-	config.Level = zap.NewAtomicLevelAt(zap.DebugLevel)
+	config.Level = Level
 
 	logger, err := config.Build()
 	if err != nil {
@@ -21,6 +25,16 @@ func Init() {
 	Log = logger
 }
 
+// SetLevel меняет уровень логирования: debug, info, warn, error.
+func SetLevel(level string) error {
+	l, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	Level.SetLevel(l)
+	return nil
+}
+
 func Close() {
 	Log.Sync()
 }
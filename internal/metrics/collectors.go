@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	logger "github.com/andro-kes/avito_test/internal/log"
+)
+
+// poolCollector снимает pool.Stat() при каждом опросе.
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquired     *prometheus.Desc
+	idle         *prometheus.Desc
+	total        *prometheus.Desc
+	max          *prometheus.Desc
+	acquireCount *prometheus.Desc
+	acquireTime  *prometheus.Desc
+	acquireWait  *prometheus.Desc
+	emptyAcquire *prometheus.Desc
+}
+
+func NewPoolCollector(pool *pgxpool.Pool) prometheus.Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+	return &poolCollector{
+		pool:         pool,
+		acquired:     desc("acquired_connections", "Connections currently acquired."),
+		idle:         desc("idle_connections", "Idle connections."),
+		total:        desc("total_connections", "Total connections in the pool."),
+		max:          desc("max_connections", "Maximum pool size."),
+		acquireCount: desc("acquires_total", "Successful connection acquires."),
+		acquireTime:  desc("acquire_seconds_total", "Total time spent acquiring connections."),
+		acquireWait:  desc("acquire_wait_seconds_total", "Time spent waiting for a free connection."),
+		emptyAcquire: desc("empty_acquires_total", "Acquires that had to wait for a connection."),
+	}
+}
+
+func (pc *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- pc.acquired
+	ch <- pc.idle
+	ch <- pc.total
+	ch <- pc.max
+	ch <- pc.acquireCount
+	ch <- pc.acquireTime
+	ch <- pc.acquireWait
+	ch <- pc.emptyAcquire
+}
+
+func (pc *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := pc.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(pc.acquired, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(pc.idle, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(pc.total, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(pc.max, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(pc.acquireCount, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(pc.acquireTime, prometheus.CounterValue, s.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(pc.acquireWait, prometheus.CounterValue, s.EmptyAcquireWaitTime().Seconds())
+	ch <- prometheus.MustNewConstMetric(pc.emptyAcquire, prometheus.CounterValue, float64(s.EmptyAcquireCount()))
+}
+
+// OpenPRsCounter считает открытые PR по команде автора; его реализует repo.StatsRepo
+// любого хранилища.
+type OpenPRsCounter interface {
+	OpenPRsByTeam(ctx context.Context) (map[string]int, error)
+}
+
+// openPRsCollector считает открытые PR по команде автора запросом при каждом опросе.
+type openPRsCollector struct {
+	counter OpenPRsCounter
+	timeout time.Duration
+	desc    *prometheus.Desc
+}
+
+func NewOpenPRsCollector(counter OpenPRsCounter) prometheus.Collector {
+	return &openPRsCollector{
+		counter: counter,
+		timeout: 2 * time.Second,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "open_pull_requests"),
+			"Open pull requests by author's team.",
+			[]string{"team"}, nil,
+		),
+	}
+}
+
+func (oc *openPRsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- oc.desc
+}
+
+func (oc *openPRsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), oc.timeout)
+	defer cancel()
+
+	open, err := oc.counter.OpenPRsByTeam(ctx)
+	if err != nil {
+		logger.Log.Error("failed to collect open pull requests", zap.Error(err))
+		return
+	}
+	for team, count := range open {
+		ch <- prometheus.MustNewConstMetric(oc.desc, prometheus.GaugeValue, float64(count), team)
+	}
+}
+
+// Register регистрирует метрики хранилища. Пул pgx есть только у Postgres:
+// при pool == nil его метрики не регистрируются.
+func Register(counter OpenPRsCounter, pool *pgxpool.Pool) {
+	prometheus.MustRegister(NewOpenPRsCollector(counter))
+	if pool != nil {
+		prometheus.MustRegister(NewPoolCollector(pool))
+	}
+}
@@ -0,0 +1,69 @@
+// Package metrics — метрики сервиса в формате Prometheus.
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "pr_reviewer"
+
+var (
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	PRsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pull_requests_created_total",
+		Help:      "Pull requests created.",
+	})
+
+	ReviewersAssigned = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reviewers_assigned_total",
+		Help:      "Reviewers assigned to new pull requests.",
+	})
+
+	Reassignments = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reassignments_total",
+		Help:      "Reviewer reassignments by reason.",
+	}, []string{"reason"})
+
+	NoCandidate = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "no_candidate_total",
+		Help:      "Reassignments that found no candidate: failed with NO_CANDIDATE or, on deactivation, left the PR without a replacement.",
+	})
+)
+
+// Причины переназначения для Reassignments.
+const (
+	ReasonManual      = "manual"
+	ReasonDeactivated = "deactivated"
+)
+
+// Middleware измеряет длительность запросов. Маршрут берётся из шаблона gin,
+// чтобы id в пути не раздували число серий; ненайденные маршруты идут как "unmatched".
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		HTTPRequestDuration.
+			WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware())
+	router.GET("/team/get/", func(c *gin.Context) { c.Status(404) })
+
+	for _, path := range []string{"/team/get/?team_name=a", "/team/get/?team_name=b", "/missing"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+	}
+
+	// Запросы к одному маршруту с разными параметрами попадают в одну серию,
+	// ненайденные маршруты — в "unmatched"
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(HTTPRequestDuration)
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+
+	counts := make(map[string]uint64)
+	for _, m := range families[0].GetMetric() {
+		labels := make(map[string]string)
+		for _, l := range m.GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+		counts[labels["route"]+" "+labels["status"]] = m.GetHistogram().GetSampleCount()
+	}
+	require.Equal(t, map[string]uint64{"/team/get/ 404": 2, "unmatched 404": 1}, counts)
+}
+
+type openPRsFunc func(ctx context.Context) (map[string]int, error)
+
+func (f openPRsFunc) OpenPRsByTeam(ctx context.Context) (map[string]int, error) {
+	return f(ctx)
+}
+
+func TestOpenPRsCollector(t *testing.T) {
+	collector := NewOpenPRsCollector(openPRsFunc(func(context.Context) (map[string]int, error) {
+		return map[string]int{"backend": 2, "frontend": 1}, nil
+	}))
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collector)
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+
+	open := make(map[string]float64)
+	for _, m := range families[0].GetMetric() {
+		open[m.GetLabel()[0].GetValue()] = m.GetGauge().GetValue()
+	}
+	require.Equal(t, map[string]float64{"backend": 2, "frontend": 1}, open)
+}
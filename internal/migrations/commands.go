@@ -0,0 +1,235 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	logger "github.com/andro-kes/avito_test/internal/log"
+)
+
+// Down откатывает n последних применённых миграций, начиная с самой новой.
+// Возвращает откаченные миграции.
+func Down(ctx context.Context, pool *pgxpool.Pool, n int) ([]Migration, error) {
+	var done []Migration
+	err := withLock(ctx, pool, func(conn *pgx.Conn) error {
+		migrations, applied, err := prepare(ctx, conn)
+		if err != nil {
+			return err
+		}
+		byVersion := indexByVersion(migrations)
+
+		for _, version := range sortedVersions(applied, true) {
+			if len(done) == n {
+				break
+			}
+			if err := down(ctx, conn, byVersion, version); err != nil {
+				return err
+			}
+			done = append(done, byVersion[version])
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Goto приводит схему к версии version: откатывает применённые миграции
+// новее неё и применяет недостающие до неё включительно. version = 0
+// откатывает все миграции.
+func Goto(ctx context.Context, pool *pgxpool.Pool, version int) (applied, reverted []Migration, err error) {
+	err = withLock(ctx, pool, func(conn *pgx.Conn) error {
+		migrations, current, err := prepare(ctx, conn)
+		if err != nil {
+			return err
+		}
+		byVersion := indexByVersion(migrations)
+		if _, ok := byVersion[version]; !ok && version != 0 {
+			return fmt.Errorf("migration %d not found", version)
+		}
+
+		for _, v := range sortedVersions(current, true) {
+			if v <= version {
+				continue
+			}
+			if err := down(ctx, conn, byVersion, v); err != nil {
+				return err
+			}
+			reverted = append(reverted, byVersion[v])
+		}
+
+		for _, m := range migrations {
+			if _, ok := current[m.Version]; ok || m.Version > version {
+				continue
+			}
+			if err := up(ctx, conn, m); err != nil {
+				return err
+			}
+			applied = append(applied, m)
+		}
+		return nil
+	})
+	return applied, reverted, err
+}
+
+func down(ctx context.Context, q querier, byVersion map[int]Migration, version int) error {
+	migration, ok := byVersion[version]
+	if !ok {
+		return fmt.Errorf("migration %d is applied but not embedded in this build, cannot revert it", version)
+	}
+	if strings.TrimSpace(migration.DownSQL) == "" {
+		return fmt.Errorf("migration %d %s has no down migration", version, migration.Name)
+	}
+
+	logger.Log.Info("Reverting migration",
+		zap.Int("version", migration.Version),
+		zap.String("name", migration.Name),
+		zap.Bool("transaction", !migration.DownNoTransaction))
+
+	err := run(ctx, q, migration.DownSQL, migration.DownNoTransaction, func(q querier) error {
+		_, err := q.Exec(ctx, "DELETE FROM schema_migrations WHERE version = $1", version)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+	}
+
+	logger.Log.Info("Migration reverted successfully",
+		zap.Int("version", migration.Version))
+	return nil
+}
+
+// State — состояние одной миграции для `migrate status`.
+type State struct {
+	Version int
+	Name    string
+	// AppliedAt — nil, если миграция не применена.
+	AppliedAt *time.Time
+	// Embedded — false для применённых версий, которых нет в этой сборке.
+	Embedded bool
+	// Modified — файл миграции изменился после применения.
+	Modified bool
+	checksum string
+}
+
+// List возвращает встроенные и применённые миграции по возрастанию версий.
+func List(ctx context.Context, pool *pgxpool.Pool) ([]State, error) {
+	if err := createMigrationsTable(ctx, pool); err != nil {
+		return nil, err
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := pool.Query(ctx, "SELECT version, name, applied_at, COALESCE(checksum, '') FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	states := make(map[int]*State)
+	for rows.Next() {
+		var (
+			st        State
+			appliedAt time.Time
+		)
+		if err := rows.Scan(&st.Version, &st.Name, &appliedAt, &st.checksum); err != nil {
+			return nil, err
+		}
+		st.AppliedAt = &appliedAt
+		states[st.Version] = &st
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, m := range migrations {
+		st, ok := states[m.Version]
+		if !ok {
+			st = &State{Version: m.Version}
+			states[m.Version] = st
+		}
+		st.Name = m.Name
+		st.Embedded = true
+		st.Modified = st.checksum != "" && st.checksum != m.Checksum
+	}
+
+	out := make([]State, 0, len(states))
+	for _, v := range sortedVersions(states, false) {
+		out = append(out, *states[v])
+	}
+	return out, nil
+}
+
+var migrationName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Create создаёт в dir пустую пару файлов миграции со следующим номером
+// версии и возвращает их пути. Новые файлы попадут в бинарник при следующей
+// сборке, если dir — каталог встроенных миграций.
+func Create(dir, name string) (upPath, downPath string, err error) {
+	if !migrationName.MatchString(name) {
+		return "", "", fmt.Errorf("invalid migration name %q: use lowercase letters, digits and underscores", name)
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return "", "", err
+	}
+	next := 0
+	for _, m := range migrations {
+		next = max(next, m.Version)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", "", err
+	}
+	for _, entry := range entries {
+		var version int
+		if _, err := fmt.Sscanf(entry.Name(), "%d_", &version); err == nil {
+			next = max(next, version)
+		}
+	}
+	next++
+
+	base := fmt.Sprintf("%06d_%s", next, name)
+	upPath = filepath.Join(dir, base+".up.sql")
+	downPath = filepath.Join(dir, base+".down.sql")
+	if err := os.WriteFile(upPath, []byte("-- "+base+": применение\n"), 0o644); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(downPath, []byte("-- "+base+": откат\n"), 0o644); err != nil {
+		return "", "", err
+	}
+	return upPath, downPath, nil
+}
+
+func indexByVersion(migrations []Migration) map[int]Migration {
+	m := make(map[int]Migration, len(migrations))
+	for _, migration := range migrations {
+		m[migration.Version] = migration
+	}
+	return m
+}
+
+func sortedVersions[V any](m map[int]V, desc bool) []int {
+	versions := make([]int, 0, len(m))
+	for v := range m {
+		versions = append(versions, v)
+	}
+	sort.Ints(versions)
+	if desc {
+		sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+	}
+	return versions
+}
@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var migrationFile = regexp.MustCompile(`^\d+_.+\.(up|down)\.sql$`)
+
+// TestNoMigrationCopies проверяет, что встроенные миграции — единственный
+// источник схемы Postgres: любая другая копия файла миграции в репозитории
+// должна совпадать со встроенной. У SQLite своя схема и свои миграции.
+func TestNoMigrationCopies(t *testing.T) {
+	root, err := filepath.Abs("../..")
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(root, "go.mod"))
+	embedded, err := filepath.Abs("migrations")
+	require.NoError(t, err)
+	sqlite := filepath.Join(root, "internal", "repo", "sqlite", "migrations")
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path == embedded || path == sqlite || d.Name() == ".git" || d.Name() == "vendor" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !migrationFile.MatchString(d.Name()) {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(root, path)
+		want, err := fs.ReadFile(migrationsFS, "migrations/"+d.Name())
+		if err != nil {
+			t.Errorf("%s is not an embedded migration; keep migrations only in internal/migrations/migrations", rel)
+			return nil
+		}
+		got, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if string(got) != string(want) {
+			t.Errorf("%s diverges from internal/migrations/migrations/%s", rel, d.Name())
+		}
+		return nil
+	})
+	require.NoError(t, err)
+}
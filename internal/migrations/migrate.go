@@ -2,84 +2,235 @@ package migrations
 
 import (
 	"context"
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
 	"fmt"
 	"io/fs"
-	"path/filepath"
 	"sort"
 	"strings"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
-	logger "github.com/andro-kes/avito_test/internal/log"
 	"go.uber.org/zap"
+
+	logger "github.com/andro-kes/avito_test/internal/log"
 )
 
 //go:embed all:migrations
 var migrationsFS embed.FS
 
+// noTransactionDirective в первой строке файла миграции отключает
+// транзакцию — для команд вроде CREATE INDEX CONCURRENTLY.
+const noTransactionDirective = "-- migrate:no-transaction"
+
+// lockID — ключ advisory lock, под которым выполняются миграции, чтобы
+// одновременно запущенные реплики не применяли их дважды.
+const lockID int64 = 0x61766974_6f6d6967
+
 type Migration struct {
 	Version int
 	Name    string
 	UpSQL   string
 	DownSQL string
+	// Checksum — SHA-256 файла .up.sql; сохраняется при применении и
+	// сверяется при каждом запуске.
+	Checksum string
+	// NoTransaction и DownNoTransaction — файл .up.sql или .down.sql
+	// начинается с noTransactionDirective.
+	NoTransaction     bool
+	DownNoTransaction bool
 }
 
+// querier — общее у *pgxpool.Pool и *pgx.Conn.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// ApplyMigrations применяет все неприменённые миграции.
 func ApplyMigrations(ctx context.Context, pool *pgxpool.Pool) error {
-	if err := createMigrationsTable(ctx, pool); err != nil {
+	_, err := Up(ctx, pool, 0)
+	return err
+}
+
+// Up применяет n ближайших неприменённых миграций по возрастанию версий;
+// n <= 0 — все. Возвращает применённые миграции.
+func Up(ctx context.Context, pool *pgxpool.Pool, n int) ([]Migration, error) {
+	var done []Migration
+	err := withLock(ctx, pool, func(conn *pgx.Conn) error {
+		migrations, applied, err := prepare(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			if n > 0 && len(done) == n {
+				break
+			}
+			if _, ok := applied[migration.Version]; ok {
+				logger.Log.Info("Migration already applied",
+					zap.Int("version", migration.Version),
+					zap.String("name", migration.Name))
+				continue
+			}
+
+			if err := up(ctx, conn, migration); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// withLock выполняет fn на отдельном соединении под advisory lock.
+// Блокировка сессионная, поэтому все запросы fn идут через conn.
+func withLock(ctx context.Context, pool *pgxpool.Pool, fn func(conn *pgx.Conn) error) error {
+	c, err := pool.Acquire(ctx)
+	if err != nil {
 		return err
 	}
+	defer c.Release()
+
+	logger.Log.Info("Waiting for migration lock")
+	if _, err := c.Exec(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() {
+		ctx := context.WithoutCancel(ctx)
+		if _, err := c.Exec(ctx, "SELECT pg_advisory_unlock($1)", lockID); err != nil {
+			// Блокировка снимается вместе с сессией.
+			_ = c.Conn().Close(ctx)
+		}
+	}()
+
+	return fn(c.Conn())
+}
+
+// prepare создаёт таблицу учёта, загружает встроенные миграции и проверяет,
+// что применённые файлы не менялись.
+func prepare(ctx context.Context, q querier) ([]Migration, map[int]string, error) {
+	if err := createMigrationsTable(ctx, q); err != nil {
+		return nil, nil, err
+	}
 
 	migrations, err := loadMigrations()
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	applied, err := getAppliedMigrations(ctx, pool)
+	applied, err := getAppliedMigrations(ctx, q)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	for _, migration := range migrations {
-		if applied[migration.Version] {
-			logger.Log.Info("Migration already applied", 
-				zap.Int("version", migration.Version),
-				zap.String("name", migration.Name))
+	if err := verifyChecksums(ctx, q, migrations, applied); err != nil {
+		return nil, nil, err
+	}
+	return migrations, applied, nil
+}
+
+func verifyChecksums(ctx context.Context, q querier, migrations []Migration, applied map[int]string) error {
+	var modified []string
+	for _, m := range migrations {
+		checksum, ok := applied[m.Version]
+		if !ok {
 			continue
 		}
-
-		logger.Log.Info("Applying migration", 
-			zap.Int("version", migration.Version),
-			zap.String("name", migration.Name))
-
-		if err := applyMigration(ctx, pool, migration); err != nil {
-			return err
+		if checksum == "" {
+			// Применена до появления контрольных сумм: запоминаем текущую.
+			if _, err := q.Exec(ctx,
+				"UPDATE schema_migrations SET checksum = $1 WHERE version = $2",
+				m.Checksum, m.Version); err != nil {
+				return err
+			}
+			applied[m.Version] = m.Checksum
+			continue
 		}
-
-		if err := markMigrationApplied(ctx, pool, migration.Version, migration.Name); err != nil {
-			return err
+		if checksum != m.Checksum {
+			modified = append(modified, fmt.Sprintf("%06d_%s", m.Version, m.Name))
 		}
+	}
+	if len(modified) > 0 {
+		return fmt.Errorf("applied migrations were modified: %s; restore the original files and add a new migration instead",
+			strings.Join(modified, ", "))
+	}
+	return nil
+}
+
+func up(ctx context.Context, q querier, migration Migration) error {
+	logger.Log.Info("Applying migration",
+		zap.Int("version", migration.Version),
+		zap.String("name", migration.Name),
+		zap.Bool("transaction", !migration.NoTransaction))
 
-		logger.Log.Info("Migration applied successfully", 
-			zap.Int("version", migration.Version))
+	err := run(ctx, q, migration.UpSQL, migration.NoTransaction, func(q querier) error {
+		_, err := q.Exec(ctx,
+			"INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)",
+			migration.Version, migration.Name, migration.Checksum)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
 	}
 
+	logger.Log.Info("Migration applied successfully",
+		zap.Int("version", migration.Version))
 	return nil
 }
 
-func createMigrationsTable(ctx context.Context, pool *pgxpool.Pool) error {
+// run выполняет sql и запись в schema_migrations в одной транзакции.
+// Без транзакции каждый оператор выполняется отдельно, а запись делается
+// только после успеха всех операторов.
+func run(ctx context.Context, q querier, sql string, noTx bool, record func(querier) error) error {
+	if noTx {
+		for _, stmt := range splitStatements(sql) {
+			if _, err := q.Exec(ctx, stmt); err != nil {
+				return err
+			}
+		}
+		return record(q)
+	}
+
+	return pgx.BeginFunc(ctx, q, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, sql); err != nil {
+			return err
+		}
+		return record(tx)
+	})
+}
+
+func createMigrationsTable(ctx context.Context, q querier) error {
 	sql := `
 	CREATE TABLE IF NOT EXISTS schema_migrations (
 		version INTEGER PRIMARY KEY,
 		name VARCHAR(255) NOT NULL,
-		applied_at TIMESTAMP NOT NULL DEFAULT NOW()
-	)
+		applied_at TIMESTAMP NOT NULL DEFAULT NOW(),
+		checksum VARCHAR(64)
+	);
+	ALTER TABLE schema_migrations ADD COLUMN IF NOT EXISTS checksum VARCHAR(64);
 	`
-	_, err := pool.Exec(ctx, sql)
+	_, err := q.Exec(ctx, sql)
 	return err
 }
 
 func loadMigrations() ([]Migration, error) {
-	entries, err := fs.ReadDir(migrationsFS, "migrations")
+	fsys, err := fs.Sub(migrationsFS, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return Load(fsys)
+}
+
+// Load читает миграции из корня fsys — пары NNNNNN_name.up.sql и
+// NNNNNN_name.down.sql — и возвращает их по возрастанию версий.
+// Нужна хранилищам со своим набором миграций.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
 	if err != nil {
 		return nil, err
 	}
@@ -116,15 +267,20 @@ func loadMigrations() ([]Migration, error) {
 			}
 		}
 
-		content, err := fs.ReadFile(migrationsFS, filepath.Join("migrations", entry.Name()))
+		content, err := fs.ReadFile(fsys, entry.Name())
 		if err != nil {
 			return nil, err
 		}
 
+		m := migrationMap[version]
 		if strings.HasSuffix(entry.Name(), ".up.sql") {
-			migrationMap[version].UpSQL = string(content)
+			sum := sha256.Sum256(content)
+			m.UpSQL = string(content)
+			m.Checksum = hex.EncodeToString(sum[:])
+			m.NoTransaction = hasNoTransaction(m.UpSQL)
 		} else if strings.HasSuffix(entry.Name(), ".down.sql") {
-			migrationMap[version].DownSQL = string(content)
+			m.DownSQL = string(content)
+			m.DownNoTransaction = hasNoTransaction(m.DownSQL)
 		}
 	}
 
@@ -143,34 +299,79 @@ func loadMigrations() ([]Migration, error) {
 	return migrations, nil
 }
 
-func getAppliedMigrations(ctx context.Context, pool *pgxpool.Pool) (map[int]bool, error) {
-	rows, err := pool.Query(ctx, "SELECT version FROM schema_migrations")
+func hasNoTransaction(sql string) bool {
+	first, _, _ := strings.Cut(strings.TrimSpace(sql), "\n")
+	return strings.TrimSpace(first) == noTransactionDirective
+}
+
+// getAppliedMigrations возвращает применённые версии и их контрольные суммы;
+// пустая сумма — миграция применена до их появления.
+func getAppliedMigrations(ctx context.Context, q querier) (map[int]string, error) {
+	rows, err := q.Query(ctx, "SELECT version, COALESCE(checksum, '') FROM schema_migrations")
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	applied := make(map[int]bool)
+	applied := make(map[int]string)
 	for rows.Next() {
-		var version int
-		if err := rows.Scan(&version); err != nil {
+		var (
+			version  int
+			checksum string
+		)
+		if err := rows.Scan(&version, &checksum); err != nil {
 			return nil, err
 		}
-		applied[version] = true
+		applied[version] = checksum
 	}
 
 	return applied, rows.Err()
 }
 
-func applyMigration(ctx context.Context, pool *pgxpool.Pool, migration Migration) error {
-	_, err := pool.Exec(ctx, migration.UpSQL)
-	return err
+// Status сравнивает встроенные миграции с применёнными в БД.
+type Status struct {
+	// Latest — последняя встроенная версия, Current — последняя применённая.
+	Latest  int `json:"latest"`
+	Current int `json:"current"`
+	// Pending — встроенные, но не применённые версии; Unknown — применённые,
+	// которых нет среди встроенных (БД обновлена более новой сборкой).
+	Pending []int `json:"pending"`
+	Unknown []int `json:"unknown"`
+	// Modified — применённые версии, файлы которых изменились после применения.
+	Modified []int `json:"modified"`
 }
 
-func markMigrationApplied(ctx context.Context, pool *pgxpool.Pool, version int, name string) error {
-	_, err := pool.Exec(ctx,
-		"INSERT INTO schema_migrations (version, name) VALUES ($1, $2) ON CONFLICT (version) DO NOTHING",
-		version, name)
-	return err
-}
+func GetStatus(ctx context.Context, pool *pgxpool.Pool) (*Status, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
 
+	applied, err := getAppliedMigrations(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{Pending: make([]int, 0), Unknown: make([]int, 0), Modified: make([]int, 0)}
+	embedded := make(map[int]bool, len(migrations))
+	for _, m := range migrations {
+		embedded[m.Version] = true
+		status.Latest = max(status.Latest, m.Version)
+		checksum, ok := applied[m.Version]
+		switch {
+		case !ok:
+			status.Pending = append(status.Pending, m.Version)
+		case checksum != "" && checksum != m.Checksum:
+			status.Modified = append(status.Modified, m.Version)
+		}
+	}
+	for version := range applied {
+		status.Current = max(status.Current, version)
+		if !embedded[version] {
+			status.Unknown = append(status.Unknown, version)
+		}
+	}
+	sort.Ints(status.Unknown)
+
+	return status, nil
+}
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS forge_deliveries;
+DROP TABLE IF EXISTS forge_pull_requests;
+DROP TABLE IF EXISTS forge_identities;
+
+DELETE FROM pull_requests WHERE status = 'CLOSED';
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS closed_at;
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS is_draft;
+ALTER TABLE pull_requests DROP CONSTRAINT IF EXISTS pull_requests_status_check;
+ALTER TABLE pull_requests ADD CONSTRAINT pull_requests_status_check CHECK (status IN ('OPEN', 'MERGED'));
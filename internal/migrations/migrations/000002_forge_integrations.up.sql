@@ -0,0 +1,32 @@
+-- Статус CLOSED и черновики для PR, пришедших из внешних форджей
+ALTER TABLE pull_requests DROP CONSTRAINT IF EXISTS pull_requests_status_check;
+ALTER TABLE pull_requests ADD CONSTRAINT pull_requests_status_check CHECK (status IN ('OPEN', 'MERGED', 'CLOSED'));
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS is_draft BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS closed_at TIMESTAMP;
+
+-- Соответствие логинов во внешних форджах пользователям сервиса
+CREATE TABLE IF NOT EXISTS forge_identities (
+    forge VARCHAR(32) NOT NULL,
+    login VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    PRIMARY KEY (forge, login)
+);
+
+CREATE INDEX IF NOT EXISTS idx_forge_identities_user_id ON forge_identities(user_id);
+
+-- Связь PR сервиса с merge/pull request во внешнем фордже
+CREATE TABLE IF NOT EXISTS forge_pull_requests (
+    pull_request_id VARCHAR(255) PRIMARY KEY REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    forge VARCHAR(32) NOT NULL,
+    project VARCHAR(255) NOT NULL,
+    number BIGINT NOT NULL,
+    CONSTRAINT unique_forge_pr UNIQUE (forge, project, number)
+);
+
+-- Уже обработанные доставки вебхуков (идемпотентность)
+CREATE TABLE IF NOT EXISTS forge_deliveries (
+    forge VARCHAR(32) NOT NULL,
+    delivery_id VARCHAR(255) NOT NULL,
+    received_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (forge, delivery_id)
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_pr_author_created_at;
+DROP INDEX IF EXISTS idx_pr_status_created_at;
+DROP INDEX IF EXISTS idx_pr_name;
+DROP INDEX IF EXISTS idx_pr_merged_at;
+DROP INDEX IF EXISTS idx_pr_created_at;
@@ -0,0 +1,23 @@
+-- История назначений ревьюверов. Текущие назначения — строки без unassigned_at,
+-- они повторяют pull_requests.assigned_reviewers.
+CREATE TABLE IF NOT EXISTS pr_reviewers (
+    id BIGSERIAL PRIMARY KEY,
+    pull_request_id VARCHAR(255) NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    user_id VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    assigned_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    unassigned_at TIMESTAMP,
+    decision VARCHAR(20) NOT NULL DEFAULT 'PENDING' CHECK (decision IN ('PENDING', 'APPROVED', 'CHANGES_REQUESTED')),
+    decided_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pr_reviewers_current
+    ON pr_reviewers(pull_request_id, user_id) WHERE unassigned_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_user_id ON pr_reviewers(user_id, assigned_at);
+
+-- Уже назначенные ревьюверы считаются назначенными при создании PR
+INSERT INTO pr_reviewers (pull_request_id, user_id, assigned_at)
+SELECT pr.pull_request_id, r.user_id, pr.created_at
+FROM pull_requests pr
+CROSS JOIN LATERAL unnest(pr.assigned_reviewers) AS r(user_id)
+WHERE EXISTS (SELECT 1 FROM users u WHERE u.user_id = r.user_id)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,2 @@
+-- Заголовки сохранённого ответа, которые повторяются вместе с телом
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS response_headers JSONB;
@@ -0,0 +1,83 @@
+package migrations
+
+import "strings"
+
+// splitStatements делит SQL на отдельные операторы по точке с запятой,
+// пропуская строки, идентификаторы в кавычках, комментарии и
+// $-кавычки тел функций. Нужен миграциям без транзакции: несколько
+// операторов в одном запросе Postgres всё равно выполняет в неявной
+// транзакции.
+func splitStatements(sql string) []string {
+	var (
+		stmts []string
+		start int
+	)
+	flush := func(end int) {
+		if stmt := strings.TrimSpace(sql[start:end]); stmt != "" && !onlyComments(stmt) {
+			stmts = append(stmts, stmt)
+		}
+		start = end + 1
+	}
+
+	for i := 0; i < len(sql); i++ {
+		switch c := sql[i]; {
+		case c == '\'' || c == '"':
+			if j := strings.IndexByte(sql[i+1:], c); j >= 0 {
+				i += j + 1
+			} else {
+				i = len(sql)
+			}
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			if j := strings.IndexByte(sql[i:], '\n'); j >= 0 {
+				i += j
+			} else {
+				i = len(sql)
+			}
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			if j := strings.Index(sql[i+2:], "*/"); j >= 0 {
+				i += j + 3
+			} else {
+				i = len(sql)
+			}
+		case c == '$':
+			tag := dollarTag(sql[i:])
+			if tag == "" {
+				continue
+			}
+			if j := strings.Index(sql[i+len(tag):], tag); j >= 0 {
+				i += len(tag) + j + len(tag) - 1
+			} else {
+				i = len(sql)
+			}
+		case c == ';':
+			flush(i)
+		}
+	}
+	if start < len(sql) {
+		flush(len(sql))
+	}
+	return stmts
+}
+
+// dollarTag возвращает открывающую кавычку вида $$ или $tag$ в начале s.
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if c == '$' {
+			return s[:i+1]
+		}
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 1 && c >= '0' && c <= '9') {
+			return ""
+		}
+	}
+	return ""
+}
+
+func onlyComments(stmt string) bool {
+	for _, line := range strings.Split(stmt, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitStatements(t *testing.T) {
+	sql := `-- migrate:no-transaction
+CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_a ON t (a);
+-- комментарий; с точкой с запятой
+INSERT INTO t (s) VALUES ('a;b'), ("c;d");
+CREATE FUNCTION f() RETURNS void AS $body$ BEGIN PERFORM 1; END $body$ LANGUAGE plpgsql;
+/* блок; */ SELECT $$x;y$$
+`
+	require.Equal(t, []string{
+		"-- migrate:no-transaction\nCREATE INDEX CONCURRENTLY IF NOT EXISTS idx_a ON t (a)",
+		"-- комментарий; с точкой с запятой\nINSERT INTO t (s) VALUES ('a;b'), (\"c;d\")",
+		"CREATE FUNCTION f() RETURNS void AS $body$ BEGIN PERFORM 1; END $body$ LANGUAGE plpgsql",
+		"/* блок; */ SELECT $$x;y$$",
+	}, splitStatements(sql))
+
+	require.Empty(t, splitStatements("-- только комментарий\n"))
+}
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	for i, m := range migrations {
+		require.Len(t, m.Checksum, 64, m.Name)
+		require.NotEmpty(t, m.DownSQL, m.Name)
+		if i > 0 {
+			require.Greater(t, m.Version, migrations[i-1].Version)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Difference — расхождение живой схемы с ожидаемой. Object — "table",
+// "column", "index" или "constraint"; пустой Expected означает лишний
+// объект, пустой Actual — отсутствующий.
+type Difference struct {
+	Object   string
+	Name     string
+	Expected string
+	Actual   string
+}
+
+func (d Difference) String() string {
+	switch {
+	case d.Actual == "":
+		return fmt.Sprintf("missing %s %s: %s", d.Object, d.Name, d.Expected)
+	case d.Expected == "":
+		return fmt.Sprintf("unexpected %s %s: %s", d.Object, d.Name, d.Actual)
+	}
+	return fmt.Sprintf("%s %s differs: expected %s, got %s", d.Object, d.Name, d.Expected, d.Actual)
+}
+
+// concurrently убирается при построении ожидаемой схемы: она строится
+// в транзакции, где CONCURRENTLY запрещён, а для пустых таблиц он не нужен.
+var concurrently = regexp.MustCompile(`(?i)\s+CONCURRENTLY\b`)
+
+// Verify сравнивает схему БД с той, что получается после всех встроенных
+// миграций. Ожидаемая схема строится во временной схеме в транзакции,
+// которая затем откатывается, поэтому БД не меняется.
+func Verify(ctx context.Context, pool *pgxpool.Pool) ([]Difference, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []Difference
+	err = pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+		var live string
+		if err := tx.QueryRow(ctx, "SELECT current_schema()").Scan(&live); err != nil {
+			return err
+		}
+		actual, err := introspect(ctx, tx, live)
+		if err != nil {
+			return err
+		}
+
+		tmp := fmt.Sprintf("migrate_verify_%d", time.Now().UnixNano())
+		if _, err := tx.Exec(ctx, fmt.Sprintf("CREATE SCHEMA %s; SET LOCAL search_path TO %s", tmp, tmp)); err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			sql := m.UpSQL
+			if m.NoTransaction {
+				sql = concurrently.ReplaceAllString(sql, "")
+			}
+			if _, err := tx.Exec(ctx, sql); err != nil {
+				return fmt.Errorf("build expected schema: migration %d %s: %w", m.Version, m.Name, err)
+			}
+		}
+		expected, err := introspect(ctx, tx, tmp)
+		if err != nil {
+			return err
+		}
+
+		diffs = diffSchemas(expected, actual)
+		// Временная схема не должна остаться в БД.
+		return errRollback
+	})
+	if err != nil && !errors.Is(err, errRollback) {
+		return nil, err
+	}
+	return diffs, nil
+}
+
+var errRollback = errors.New("rollback")
+
+// schemaObjects — объекты схемы по виду и имени с описанием, не зависящим
+// от имени схемы.
+type schemaObjects map[string]map[string]string
+
+func introspect(ctx context.Context, tx pgx.Tx, schema string) (schemaObjects, error) {
+	objects := schemaObjects{"table": {}, "column": {}, "index": {}, "constraint": {}}
+	strip := func(s string) string { return strings.ReplaceAll(s, schema+".", "") }
+
+	queries := []struct {
+		object string
+		sql    string
+	}{
+		{"table", `
+			SELECT table_name, table_type, ''
+			FROM information_schema.tables
+			WHERE table_schema = $1 AND table_name <> 'schema_migrations'`},
+		{"column", `
+			SELECT table_name || '.' || column_name,
+				udt_name || COALESCE('(' || character_maximum_length || ')', '')
+					|| CASE WHEN is_nullable = 'NO' THEN ' NOT NULL' ELSE '' END,
+				COALESCE(' DEFAULT ' || column_default, '')
+			FROM information_schema.columns
+			WHERE table_schema = $1 AND table_name <> 'schema_migrations'`},
+		{"index", `
+			SELECT indexname, indexdef, ''
+			FROM pg_indexes
+			WHERE schemaname = $1 AND tablename <> 'schema_migrations'`},
+		{"constraint", `
+			SELECT cl.relname || '.' || c.conname, pg_get_constraintdef(c.oid), ''
+			FROM pg_constraint c
+			JOIN pg_class cl ON cl.oid = c.conrelid
+			JOIN pg_namespace n ON n.oid = c.connamespace
+			WHERE n.nspname = $1 AND c.contype IN ('p', 'u', 'f', 'c', 'x')
+				AND cl.relname <> 'schema_migrations'`},
+	}
+
+	for _, q := range queries {
+		rows, err := tx.Query(ctx, q.sql, schema)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var name, def, extra string
+			if err := rows.Scan(&name, &def, &extra); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			objects[q.object][name] = strip(def + extra)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return objects, nil
+}
+
+func diffSchemas(expected, actual schemaObjects) []Difference {
+	var diffs []Difference
+	for _, object := range []string{"table", "column", "index", "constraint"} {
+		exp, act := expected[object], actual[object]
+		names := maps.Clone(exp)
+		maps.Copy(names, act)
+
+		for _, name := range slices.Sorted(maps.Keys(names)) {
+			e, inExp := exp[name]
+			a, inAct := act[name]
+			switch {
+			case !inAct:
+				diffs = append(diffs, Difference{Object: object, Name: name, Expected: e})
+			case !inExp:
+				diffs = append(diffs, Difference{Object: object, Name: name, Actual: a})
+			case e != a:
+				diffs = append(diffs, Difference{Object: object, Name: name, Expected: e, Actual: a})
+			}
+		}
+	}
+	return diffs
+}
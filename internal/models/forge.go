@@ -0,0 +1,38 @@
+package models
+
+// Действия над merge/pull request во внешнем фордже, которые понимает сервис.
+const (
+	ForgeActionOpen   = "open"
+	ForgeActionReopen = "reopen"
+	ForgeActionClose  = "close"
+	ForgeActionMerge  = "merge"
+	ForgeActionDraft  = "draft"
+)
+
+// ForgeEvent — событие форджа, приведённое к общему виду.
+// Разбор payload конкретного форджа живёт в internal/integrations.
+type ForgeEvent struct {
+	Forge       string
+	DeliveryID  string
+	Action      string
+	Project     string
+	Number      int64
+	Title       string
+	AuthorLogin string
+	IsDraft     bool
+	// TeamName — команда, из которой назначаются ревьюверы; пусто — команда автора.
+	TeamName string
+}
+
+type ForgeIdentity struct {
+	Forge  string `json:"forge" binding:"oneof=github gitlab"`
+	Login  string `json:"login" binding:"notblank"`
+	UserId string `json:"user_id" binding:"notblank"`
+}
+
+type ForgePullRequest struct {
+	PullRequestId string
+	Forge         string
+	Project       string
+	Number        int64
+}
@@ -0,0 +1,19 @@
+package models
+
+const (
+	HealthUp   = "up"
+	HealthDown = "down"
+)
+
+type ComponentHealth struct {
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+	Details any    `json:"details,omitempty"`
+}
+
+// HealthReport — общий статус и статусы компонентов; общий статус up,
+// только если up все компоненты.
+type HealthReport struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentHealth `json:"components"`
+}
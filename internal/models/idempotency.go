@@ -0,0 +1,13 @@
+package models
+
+import "net/http"
+
+type IdempotencyRecord struct {
+	Key         string
+	Fingerprint string
+	// StatusCode равен 0, пока первый запрос с этим ключом не завершился.
+	StatusCode int
+	// Header — заголовки ответа, которые повторяются вместе с телом.
+	Header http.Header
+	Body   []byte
+}
@@ -8,18 +8,127 @@ type PullRequest struct {
 	AuthorId          string     `json:"author_id" db:"author_id"`
 	Status            string     `json:"status" db:"status"`
 	AssignedReviewers []string   `json:"assigned_reviewers" db:"assigned_reviewers"`
-	CreatedAt         *time.Time `db:"created_at"`
-	MergedAt          *time.Time `db:"merged_at"`
+	IsDraft           bool       `json:"is_draft" db:"is_draft"`
+	CreatedAt         *time.Time `json:"created_at" db:"created_at"`
+	MergedAt          *time.Time `json:"merged_at" db:"merged_at"`
 }
 
 type PullRequestShort struct {
-	PullRequestId   string `json:"pull_request_id"`
-	PullRequestName string `json:"pull_request_name"`
-	AuthorId        string `json:"author_id"`
+	PullRequestId   string `json:"pull_request_id" binding:"notblank,max=255"`
+	PullRequestName string `json:"pull_request_name" binding:"notblank,max=255"`
+	AuthorId        string `json:"author_id" binding:"notblank,max=255,user_exists"`
 	Status          string `json:"status"`
 }
 
+type MergePRRequest struct {
+	PullRequestId string `json:"pull_request_id" binding:"notblank"`
+}
+
 type ReassignRequest struct {
-	PullRequestId string `json:"pull_request_id"`
-	OldUserId     string `json:"old_user_id"`
+	PullRequestId string `json:"pull_request_id" binding:"notblank"`
+	OldUserId     string `json:"old_user_id" binding:"notblank"`
+}
+
+type ReviewDecisionRequest struct {
+	PullRequestId string `json:"pull_request_id" binding:"notblank"`
+	UserId        string `json:"user_id" binding:"notblank"`
+	Decision      string `json:"decision" binding:"oneof=APPROVED CHANGES_REQUESTED"`
+}
+
+// PRImportResult — итог импорта одной строки пакета.
+type PRImportResult struct {
+	Index         int    `json:"index"`
+	PullRequestId string `json:"pull_request_id,omitempty"`
+	Status        string `json:"status"`
+	Code          string `json:"code,omitempty"`
+	Message       string `json:"message,omitempty"`
+}
+
+const (
+	PRImportImported = "imported"
+	PRImportFailed   = "failed"
+)
+
+// Поля сортировки /pullRequest/list/.
+const (
+	PRSortCreatedAt = "created_at"
+	PRSortMergedAt  = "merged_at"
+	PRSortName      = "pull_request_name"
+	PRSortId        = "pull_request_id"
+)
+
+// PRListFilter — условия выборки PR. Пустые поля не фильтруют,
+// нижние границы дат включаются, верхние — нет.
+type PRListFilter struct {
+	Status     string
+	AuthorId   string
+	ReviewerId string
+	TeamName   string
+	Name       string
+
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	MergedFrom  *time.Time
+	MergedTo    *time.Time
+
+	Sort string
+	Desc bool
+	// Limit 0 — без ограничения.
+	Limit int
+	After *PRCursor
+}
+
+// PRCursor — позиция keyset-пагинации: значение поля сортировки
+// и id последнего отданного PR.
+type PRCursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d,omitempty"`
+	Value string `json:"v,omitempty"`
+	Id    string `json:"id"`
+}
+
+type PRListPage struct {
+	PullRequests []PullRequest `json:"pull_requests"`
+	NextCursor   string        `json:"next_cursor,omitempty"`
+	HasMore      bool          `json:"has_more"`
+}
+
+// Решения ревьювера по PR.
+const (
+	ReviewPending          = "PENDING"
+	ReviewApproved         = "APPROVED"
+	ReviewChangesRequested = "CHANGES_REQUESTED"
+)
+
+// PRReviewer — назначенный ревьювер с данными пользователя.
+type PRReviewer struct {
+	User
+	AssignedAt *time.Time `json:"assigned_at"`
+	Decision   string     `json:"decision"`
+}
+
+// PullRequestDetails — PR с раскрытыми автором и ревьюверами.
+type PullRequestDetails struct {
+	PullRequestId     string       `json:"pull_request_id"`
+	PullRequestName   string       `json:"pull_request_name"`
+	Author            User         `json:"author"`
+	Status            string       `json:"status"`
+	AssignedReviewers []PRReviewer `json:"assigned_reviewers"`
+	IsDraft           bool         `json:"is_draft"`
+	CreatedAt         *time.Time   `json:"created_at"`
+	MergedAt          *time.Time   `json:"merged_at"`
+}
+
+// ReviewQuery — параметры /users/getReview/.
+type ReviewQuery struct {
+	Status string
+	Since  *time.Time
+	Limit  int
+	Cursor string
+}
+
+type ReviewPage struct {
+	PullRequests []PullRequestShort
+	NextCursor   string
+	HasMore      bool
 }
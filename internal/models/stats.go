@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// StatsWindow — полуинтервал [From, To), за который считается статистика.
+type StatsWindow struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// ReviewLoad — показатели ревью за окно; OpenLoad — текущее число открытых PR
+// на ревью, от окна не зависит.
+type ReviewLoad struct {
+	AssignmentsReceived int `json:"assignments_received"`
+	ReviewsCompleted    int `json:"reviews_completed"`
+	// MedianTimeToFirstReview — медиана по PR от создания до первого решения
+	// ревьюверов, в секундах. Учитываются PR, первое решение по которым в окне.
+	MedianTimeToFirstReview *float64 `json:"median_time_to_first_review_seconds"`
+	ReassignedAway          int      `json:"reassigned_away"`
+	OpenLoad                int      `json:"open_load"`
+}
+
+type ReviewerStats struct {
+	User
+	ReviewLoad
+}
+
+type TeamReviewStats struct {
+	TeamName string `json:"team_name"`
+	ReviewLoad
+}
+
+type ReviewerStatsReport struct {
+	Window StatsWindow       `json:"window"`
+	Users  []ReviewerStats   `json:"users"`
+	Teams  []TeamReviewStats `json:"teams"`
+}
+
+// PRThroughput — PR команды за неделю. Неделя — дата понедельника в часовом поясе отчёта.
+type PRThroughput struct {
+	TeamName string `json:"team_name"`
+	Week     string `json:"week"`
+	Opened   int    `json:"opened"`
+	Merged   int    `json:"merged"`
+	Closed   int    `json:"closed"`
+	// Время от открытия до мержа PR, смёрженных за неделю, в секундах.
+	MedianOpenToMerge *float64 `json:"median_open_to_merge_seconds"`
+	P90OpenToMerge    *float64 `json:"p90_open_to_merge_seconds"`
+	// ReviewerCounts — число открытых за неделю PR по количеству ревьюверов.
+	ReviewerCounts map[int]int `json:"reviewer_count_distribution"`
+}
+
+type PRThroughputReport struct {
+	Window   StatsWindow    `json:"window"`
+	Timezone string         `json:"timezone"`
+	Weeks    []PRThroughput `json:"weeks"`
+}
@@ -1,12 +1,54 @@
 package models
 
 type TeamMember struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	IsActive bool   `json:"is_active"`
+	UserID   string `json:"user_id" yaml:"user_id" binding:"notblank,max=255"`
+	Username string `json:"username" yaml:"username" binding:"notblank,max=255"`
+	IsActive bool   `json:"is_active" yaml:"is_active"`
 }
 
+// Team проверяется и на уникальность user_id среди участников (см. handlers).
 type Team struct {
-	TeamName string       `json:"team_name"`
-	Members  []TeamMember `json:"members"`
+	TeamName string       `json:"team_name" yaml:"team_name" binding:"notblank,max=255"`
+	Members  []TeamMember `json:"members" yaml:"members" binding:"required,dive"`
+}
+
+// OrgDocument — состав всех команд, которым обмениваются импорт и экспорт.
+type OrgDocument struct {
+	Teams []Team `json:"teams" yaml:"teams"`
+}
+
+type UserChange struct {
+	UserId   string `json:"user_id"`
+	Username string `json:"username,omitempty"`
+	FromTeam string `json:"from_team,omitempty"`
+	ToTeam   string `json:"to_team,omitempty"`
+}
+
+// OrgDiff — изменения, которые внесёт импорт состава команд.
+type OrgDiff struct {
+	TeamsCreated     []string     `json:"teams_created"`
+	UsersAdded       []UserChange `json:"users_added"`
+	UsersMoved       []UserChange `json:"users_moved"`
+	UsersRenamed     []UserChange `json:"users_renamed"`
+	UsersActivated   []string     `json:"users_activated"`
+	UsersDeactivated []string     `json:"users_deactivated"`
+}
+
+func (d *OrgDiff) Empty() bool {
+	return len(d.TeamsCreated) == 0 && len(d.UsersAdded) == 0 && len(d.UsersMoved) == 0 &&
+		len(d.UsersRenamed) == 0 && len(d.UsersActivated) == 0 && len(d.UsersDeactivated) == 0
+}
+
+type PRReassignment struct {
+	PullRequestId string `json:"pull_request_id"`
+	OldUserId     string `json:"old_user_id"`
+	// NewUserId пуст, если замены не нашлось и ревьювер просто снят.
+	NewUserId string `json:"new_user_id"`
+}
+
+// OrgSyncPlan — изменения, которые вносит синхронизация с желаемым состоянием.
+type OrgSyncPlan struct {
+	DryRun        bool             `json:"dry_run"`
+	Diff          *OrgDiff         `json:"diff"`
+	Reassignments []PRReassignment `json:"reassignments"`
 }
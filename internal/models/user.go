@@ -1,8 +1,16 @@
 package models
 
 type User struct {
-	UserId   string `json:"user_id"`
+	UserId   string `json:"user_id" binding:"notblank"`
 	Username string `json:"username"`
 	TeamName string `json:"team_name"`
 	IsActive bool   `json:"is_active"`
 }
+
+// Deactivation — итог деактивации пользователей.
+type Deactivation struct {
+	Deactivated   []string         `json:"deactivated"`
+	Reassignments []PRReassignment `json:"reassignments"`
+	// Unreplaced — открытые PR, где снятому ревьюверу не нашлось замены.
+	Unreplaced []string `json:"unreplaced_pull_requests"`
+}
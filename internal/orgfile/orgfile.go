@@ -0,0 +1,158 @@
+// Package orgfile читает и пишет состав команд в JSON, YAML и CSV.
+package orgfile
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/andro-kes/avito_test/internal/models"
+)
+
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatCSV  = "csv"
+)
+
+var csvHeader = []string{"team_name", "user_id", "username", "is_active"}
+
+var ErrUnknownFormat = errors.New("unknown format")
+
+// DetectFormat определяет формат по явному имени, MIME-типу или расширению файла.
+func DetectFormat(hint string) (string, error) {
+	hint = strings.ToLower(strings.TrimSpace(hint))
+	if i := strings.IndexByte(hint, ';'); i >= 0 {
+		hint = strings.TrimSpace(hint[:i])
+	}
+	if ext := filepath.Ext(hint); ext != "" && !strings.Contains(hint, "/") {
+		hint = ext[1:]
+	}
+
+	switch hint {
+	case "", "json", "application/json":
+		return FormatJSON, nil
+	case "yaml", "yml", "application/yaml", "application/x-yaml", "text/yaml":
+		return FormatYAML, nil
+	case "csv", "text/csv":
+		return FormatCSV, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnknownFormat, hint)
+}
+
+func ContentType(format string) string {
+	switch format {
+	case FormatYAML:
+		return "application/yaml"
+	case FormatCSV:
+		return "text/csv"
+	}
+	return "application/json"
+}
+
+func Decode(r io.Reader, format string) (*models.OrgDocument, error) {
+	var doc models.OrgDocument
+	var err error
+	switch format {
+	case FormatJSON:
+		err = json.NewDecoder(r).Decode(&doc)
+	case FormatYAML:
+		err = yaml.NewDecoder(r).Decode(&doc)
+	case FormatCSV:
+		doc.Teams, err = decodeCSV(r)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &doc, nil
+}
+
+func Encode(w io.Writer, format string, doc *models.OrgDocument) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+		return enc.Close()
+	case FormatCSV:
+		return encodeCSV(w, doc.Teams)
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+}
+
+func decodeCSV(r io.Reader) ([]models.Team, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(csvHeader)
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return []models.Team{}, nil
+	}
+	if strings.Join(records[0], ",") != strings.Join(csvHeader, ",") {
+		return nil, fmt.Errorf("csv header must be %s", strings.Join(csvHeader, ","))
+	}
+
+	teams := make([]models.Team, 0)
+	index := make(map[string]int)
+	for line, rec := range records[1:] {
+		isActive := true
+		if rec[3] != "" {
+			isActive, err = strconv.ParseBool(rec[3])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid is_active %q", line+2, rec[3])
+			}
+		}
+
+		i, ok := index[rec[0]]
+		if !ok {
+			i = len(teams)
+			index[rec[0]] = i
+			teams = append(teams, models.Team{TeamName: rec[0], Members: make([]models.TeamMember, 0)})
+		}
+		teams[i].Members = append(teams[i].Members, models.TeamMember{
+			UserID:   rec[1],
+			Username: rec[2],
+			IsActive: isActive,
+		})
+	}
+
+	return teams, nil
+}
+
+func encodeCSV(w io.Writer, teams []models.Team) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, team := range teams {
+		for _, m := range team.Members {
+			rec := []string{team.TeamName, m.UserID, m.Username, strconv.FormatBool(m.IsActive)}
+			if err := writer.Write(rec); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,65 @@
+package orgfile
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/andro-kes/avito_test/internal/models"
+)
+
+func TestRoundTrip(t *testing.T) {
+	doc := &models.OrgDocument{Teams: []models.Team{
+		{TeamName: "backend", Members: []models.TeamMember{
+			{UserID: "u1", Username: "Alice", IsActive: true},
+			{UserID: "u2", Username: "Bob", IsActive: false},
+		}},
+		{TeamName: "payments", Members: []models.TeamMember{
+			{UserID: "u3", Username: "Charlie", IsActive: true},
+		}},
+	}}
+
+	for _, format := range []string{FormatJSON, FormatYAML, FormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, Encode(&buf, format, doc))
+
+			decoded, err := Decode(&buf, format)
+			require.NoError(t, err)
+			require.Equal(t, doc, decoded)
+		})
+	}
+}
+
+func TestDecodeCSV(t *testing.T) {
+	input := "team_name,user_id,username,is_active\nbackend,u1,Alice,\nbackend,u2,Bob,false\n"
+	doc, err := Decode(strings.NewReader(input), FormatCSV)
+	require.NoError(t, err)
+	require.Len(t, doc.Teams, 1)
+	require.True(t, doc.Teams[0].Members[0].IsActive)
+	require.False(t, doc.Teams[0].Members[1].IsActive)
+
+	_, err = Decode(strings.NewReader("team,user\nbackend,u1\n"), FormatCSV)
+	require.Error(t, err)
+}
+
+func TestDetectFormat(t *testing.T) {
+	cases := map[string]string{
+		"":                                FormatJSON,
+		"application/json; charset=utf-8": FormatJSON,
+		"YAML":                            FormatYAML,
+		"org.yml":                         FormatYAML,
+		"text/csv":                        FormatCSV,
+		"teams.csv":                       FormatCSV,
+	}
+	for hint, want := range cases {
+		got, err := DetectFormat(hint)
+		require.NoError(t, err, hint)
+		require.Equal(t, want, got, hint)
+	}
+
+	_, err := DetectFormat("xml")
+	require.ErrorIs(t, err, ErrUnknownFormat)
+}
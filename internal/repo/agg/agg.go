@@ -0,0 +1,254 @@
+// Package agg считает статистику ревью в Go — для хранилищ, где нет
+// percentile_cont и date_trunc. Результаты совпадают с запросами Postgres.
+package agg
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/andro-kes/avito_test/internal/models"
+)
+
+// Review — одно назначение ревьювера из истории pr_reviewers.
+// Решения по PR должны попасть целиком, даже принятые до окна:
+// по ним ищется первое решение.
+type Review struct {
+	UserId        string
+	PullRequestId string
+	PRCreatedAt   time.Time
+	AssignedAt    time.Time
+	UnassignedAt  *time.Time
+	DecidedAt     *time.Time
+}
+
+// firstDecision — первое решение по PR и время создания PR.
+type firstDecision struct {
+	at, created time.Time
+}
+
+// load копит показатели ревью за окно так же, как одноимённые
+// агрегаты по pr_reviewers в Postgres.
+type load struct {
+	assigned, completed, reassigned int
+	// first — первое решение по каждому PR среди назначений группы.
+	first map[string]firstDecision
+}
+
+func (l *load) add(r Review, w models.StatsWindow) {
+	if inWindow(r.AssignedAt, w) {
+		l.assigned++
+	}
+	if r.DecidedAt != nil {
+		if inWindow(*r.DecidedAt, w) {
+			l.completed++
+		}
+		l.decide(r.PullRequestId, firstDecision{at: *r.DecidedAt, created: r.PRCreatedAt})
+	}
+	if r.UnassignedAt != nil && inWindow(*r.UnassignedAt, w) {
+		l.reassigned++
+	}
+}
+
+func (l *load) decide(prId string, d firstDecision) {
+	if l.first == nil {
+		l.first = make(map[string]firstDecision)
+	}
+	if cur, ok := l.first[prId]; !ok || d.at.Before(cur.at) {
+		l.first[prId] = d
+	}
+}
+
+func (l *load) merge(o *load) {
+	l.assigned += o.assigned
+	l.completed += o.completed
+	l.reassigned += o.reassigned
+	for prId, d := range o.first {
+		l.decide(prId, d)
+	}
+}
+
+// result считает медиану времени до первого ревью по PR, первое решение
+// по которым принято в окне w: от создания PR до этого решения.
+func (l *load) result(w models.StatsWindow) models.ReviewLoad {
+	firstReview := make([]float64, 0, len(l.first))
+	for _, d := range l.first {
+		if inWindow(d.at, w) {
+			firstReview = append(firstReview, d.at.Sub(d.created).Seconds())
+		}
+	}
+
+	return models.ReviewLoad{
+		AssignmentsReceived:     l.assigned,
+		ReviewsCompleted:        l.completed,
+		MedianTimeToFirstReview: percentile(firstReview, 0.5),
+		ReassignedAway:          l.reassigned,
+	}
+}
+
+// byUser собирает показатели каждого из users; назначения остальных
+// пользователей пропускаются.
+func byUser(w models.StatsWindow, users []models.User, reviews []Review) map[string]*load {
+	loads := make(map[string]*load, len(users))
+	for _, u := range users {
+		loads[u.UserId] = &load{}
+	}
+	for _, r := range reviews {
+		if l, ok := loads[r.UserId]; ok {
+			l.add(r, w)
+		}
+	}
+	return loads
+}
+
+// ReviewerStats считает показатели каждого из users по истории reviews;
+// openLoad — число открытых PR, где пользователь сейчас ревьювер.
+func ReviewerStats(w models.StatsWindow, users []models.User, reviews []Review, openLoad map[string]int) []models.ReviewerStats {
+	loads := byUser(w, users, reviews)
+
+	stats := make([]models.ReviewerStats, 0, len(users))
+	for _, u := range users {
+		s := models.ReviewerStats{User: u, ReviewLoad: loads[u.UserId].result(w)}
+		s.OpenLoad = openLoad[u.UserId]
+		stats = append(stats, s)
+	}
+	slices.SortFunc(stats, func(a, b models.ReviewerStats) int {
+		if c := cmp.Compare(b.ReviewsCompleted, a.ReviewsCompleted); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(b.AssignmentsReceived, a.AssignmentsReceived); c != 0 {
+			return c
+		}
+		return strings.Compare(a.UserId, b.UserId)
+	})
+
+	return stats
+}
+
+// TeamReviewStats — те же показатели, сложенные по командам users.
+func TeamReviewStats(w models.StatsWindow, users []models.User, reviews []Review) []models.TeamReviewStats {
+	loads := byUser(w, users, reviews)
+
+	teams := make(map[string]*load)
+	for _, u := range users {
+		t, ok := teams[u.TeamName]
+		if !ok {
+			t = &load{}
+			teams[u.TeamName] = t
+		}
+		t.merge(loads[u.UserId])
+	}
+
+	stats := make([]models.TeamReviewStats, 0, len(teams))
+	for team, l := range teams {
+		stats = append(stats, models.TeamReviewStats{TeamName: team, ReviewLoad: l.result(w)})
+	}
+	slices.SortFunc(stats, func(a, b models.TeamReviewStats) int {
+		return strings.Compare(a.TeamName, b.TeamName)
+	})
+
+	return stats
+}
+
+// PR — события одного PR для недельной статистики.
+type PR struct {
+	TeamName  string
+	CreatedAt time.Time
+	MergedAt  *time.Time
+	ClosedAt  *time.Time
+	Reviewers int
+}
+
+// Throughput группирует открытия, мержи и закрытия prs по команде и неделе.
+// Время хранится в UTC, недели отсчитываются в часовом поясе loc.
+func Throughput(w models.StatsWindow, loc *time.Location, prs []PR) []models.PRThroughput {
+	type bucket struct {
+		team, week string
+	}
+	type events struct {
+		opened, merged, closed int
+		mergeSeconds           []float64
+		reviewers              map[int]int
+	}
+
+	buckets := make(map[bucket]*events)
+	at := func(team string, t time.Time) *events {
+		b := bucket{team: team, week: week(t, loc)}
+		e, ok := buckets[b]
+		if !ok {
+			e = &events{reviewers: make(map[int]int)}
+			buckets[b] = e
+		}
+		return e
+	}
+
+	for _, pr := range prs {
+		if inWindow(pr.CreatedAt, w) {
+			e := at(pr.TeamName, pr.CreatedAt)
+			e.opened++
+			e.reviewers[pr.Reviewers]++
+		}
+		if pr.MergedAt != nil && inWindow(*pr.MergedAt, w) {
+			e := at(pr.TeamName, *pr.MergedAt)
+			e.merged++
+			e.mergeSeconds = append(e.mergeSeconds, pr.MergedAt.Sub(pr.CreatedAt).Seconds())
+		}
+		if pr.ClosedAt != nil && inWindow(*pr.ClosedAt, w) {
+			at(pr.TeamName, *pr.ClosedAt).closed++
+		}
+	}
+
+	stats := make([]models.PRThroughput, 0, len(buckets))
+	for b, e := range buckets {
+		stats = append(stats, models.PRThroughput{
+			TeamName:          b.team,
+			Week:              b.week,
+			Opened:            e.opened,
+			Merged:            e.merged,
+			Closed:            e.closed,
+			MedianOpenToMerge: percentile(e.mergeSeconds, 0.5),
+			P90OpenToMerge:    percentile(e.mergeSeconds, 0.9),
+			ReviewerCounts:    e.reviewers,
+		})
+	}
+	slices.SortFunc(stats, func(a, b models.PRThroughput) int {
+		if c := strings.Compare(a.TeamName, b.TeamName); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Week, b.Week)
+	})
+
+	return stats
+}
+
+func inWindow(t time.Time, w models.StatsWindow) bool {
+	return !t.Before(w.From) && t.Before(w.To)
+}
+
+// week возвращает дату понедельника недели t в часовом поясе loc,
+// как date_trunc('week', ...).
+func week(t time.Time, loc *time.Location) string {
+	local := t.In(loc)
+	monday := local.AddDate(0, 0, -(int(local.Weekday())+6)%7)
+	return monday.Format(time.DateOnly)
+}
+
+// percentile — percentile_cont: линейная интерполяция между соседними
+// значениями. Для пустой выборки — nil.
+func percentile(values []float64, p float64) *float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	pos := p * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	v := sorted[lower]
+	if upper := lower + 1; upper < len(sorted) {
+		v += (sorted[upper] - v) * (pos - float64(lower))
+	}
+	return &v
+}
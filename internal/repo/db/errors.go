@@ -0,0 +1,55 @@
+package db
+
+import (
+	"errors"
+
+	pgxv5 "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+)
+
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+	notNullViolation    = "23502"
+	checkViolation      = "23514"
+)
+
+// Classify переводит ошибки pgx в доменные, сохраняя исходную как причину:
+// нет строк и нарушение внешнего ключа — NOT_FOUND, нарушение уникальности —
+// CONFLICT, нарушение NOT NULL и CHECK — VALIDATION_ERROR.
+// Доменные и неизвестные ошибки возвращаются без изменений.
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var perr *prerrors.Error
+	if errors.As(err, &perr) {
+		return err
+	}
+	if errors.Is(err, pgxv5.ErrNoRows) {
+		return prerrors.Wrap(prerrors.ErrNotFound, err)
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	switch pgErr.Code {
+	case uniqueViolation:
+		return prerrors.Wrap(prerrors.ErrConflict, err)
+	case foreignKeyViolation:
+		return prerrors.Wrap(prerrors.ErrNotFound, err)
+	case notNullViolation, checkViolation:
+		return prerrors.Wrap(prerrors.ErrValidation, err)
+	}
+	return err
+}
+
+// IsUniqueViolation сообщает, что err — нарушение уникального ограничения.
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
@@ -2,16 +2,52 @@ package db
 
 import (
 	"context"
+	"errors"
 
 	pgxv5 "github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/andro-kes/avito_test/internal/tracing"
 )
 
+// Querier — транзакция, открытая Tx.RunInTx. Сервисы только передают её
+// репозиториям того же хранилища; что за ней стоит, знает лишь хранилище.
+// Реализуют его только типы со встроенным TxMarker, поэтому голый *sql.Tx,
+// pgx.Tx или пул вместо транзакции не скомпилируется.
 type Querier interface {
+	querier()
+}
+
+// TxMarker встраивается в транзакцию хранилища и делает её Querier.
+type TxMarker struct{}
+
+func (TxMarker) querier() {}
+
+// PgxQuerier — транзакция Postgres, с которой работают репозитории пакета repo.
+type PgxQuerier interface {
 	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
 	Query(ctx context.Context, sql string, args ...any) (pgxv5.Rows, error)
 	QueryRow(ctx context.Context, sql string, args ...any) pgxv5.Row
+	CopyFrom(ctx context.Context, tableName pgxv5.Identifier, columnNames []string, rowSrc pgxv5.CopyFromSource) (int64, error)
+}
+
+// pgxTx — транзакция Postgres, которую RunInTx передаёт как Querier.
+type pgxTx struct {
+	pgxv5.Tx
+	TxMarker
+}
+
+// ErrForeignQuerier — в репозиторий передана транзакция другого хранилища.
+var ErrForeignQuerier = errors.New("querier belongs to another storage")
+
+// Pgx возвращает q как транзакцию Postgres.
+func Pgx(q Querier) (PgxQuerier, error) {
+	t, ok := q.(pgxTx)
+	if !ok {
+		return nil, ErrForeignQuerier
+	}
+	return t.Tx, nil
 }
 
 type Tx interface {
@@ -28,16 +64,55 @@ func NewTx(pool *pgxpool.Pool) Tx {
 	}
 }
 
-func (t *tx) RunInTx(ctx context.Context, fn func(ctx context.Context, q Querier) error) error {
+func (t *tx) RunInTx(ctx context.Context, fn func(ctx context.Context, q Querier) error) (err error) {
+	ctx, span := tracing.Start(ctx, "db.RunInTx")
+	defer func() { tracing.End(span, err) }()
+
 	px, err := t.Pool.Begin(ctx)
 	if err != nil {
 		return err
 	}
 	defer px.Rollback(ctx)
 
-	if err := fn(ctx, px); err != nil {
+	txCtx, runHooks := WithAfterCommit(ctx)
+	if err := fn(txCtx, pgxTx{Tx: px}); err != nil {
+		return err
+	}
+
+	if err := px.Commit(ctx); err != nil {
 		return err
 	}
 
-	return px.Commit(ctx)
+	runHooks()
+	return nil
+}
+
+type afterCommitKey struct{}
+
+type afterCommitHooks struct {
+	fns []func()
+}
+
+func (h *afterCommitHooks) run() {
+	for _, fn := range h.fns {
+		fn()
+	}
+}
+
+// WithAfterCommit возвращает контекст транзакции, в котором AfterCommit
+// копит хуки, и функцию, вызывающую их после коммита. Нужна реализациям Tx.
+func WithAfterCommit(ctx context.Context) (context.Context, func()) {
+	hooks := &afterCommitHooks{}
+	return context.WithValue(ctx, afterCommitKey{}, hooks), hooks.run
+}
+
+// AfterCommit откладывает fn до успешного коммита транзакции из ctx.
+// При откате fn не вызывается, вне транзакции вызывается сразу.
+func AfterCommit(ctx context.Context, fn func()) {
+	hooks, ok := ctx.Value(afterCommitKey{}).(*afterCommitHooks)
+	if !ok {
+		fn()
+		return
+	}
+	hooks.fns = append(hooks.fns, fn)
 }
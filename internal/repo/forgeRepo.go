@@ -0,0 +1,143 @@
+package repo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/repo/db"
+)
+
+type forgeRepo struct {
+	Pool *pgxpool.Pool
+}
+
+func NewForgeRepo(pool *pgxpool.Pool) ForgeRepo {
+	return &forgeRepo{
+		Pool: pool,
+	}
+}
+
+// ResolveUser ищет пользователя по явному соответствию логина,
+// а если его нет — по совпадению логина с user_id.
+func (fr *forgeRepo) ResolveUser(ctx context.Context, forge, login string) (string, error) {
+	const sql = `
+	SELECT user_id FROM (
+		SELECT user_id, 0 AS priority FROM forge_identities WHERE forge = $1 AND login = $2
+		UNION ALL
+		SELECT user_id, 1 AS priority FROM users WHERE user_id = $2
+	) candidates
+	ORDER BY priority
+	LIMIT 1
+	`
+
+	var userId string
+	err := fr.Pool.QueryRow(ctx, sql, forge, login).Scan(&userId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", prerrors.ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return userId, nil
+}
+
+func (fr *forgeRepo) UpsertIdentity(ctx context.Context, identity models.ForgeIdentity) error {
+	const sql = `
+	INSERT INTO forge_identities (forge, login, user_id)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (forge, login) DO UPDATE
+	SET user_id = EXCLUDED.user_id
+	`
+
+	_, err := fr.Pool.Exec(ctx, sql, identity.Forge, identity.Login, identity.UserId)
+	return err
+}
+
+// ClaimDelivery помечает доставку обработанной в транзакции q: откат
+// транзакции снимает отметку. Возвращает false, если такая доставка уже была;
+// параллельная доставка того же события ждёт коммита или отката первой.
+func (fr *forgeRepo) ClaimDelivery(ctx context.Context, q db.Querier, forge, deliveryId string) (bool, error) {
+	pq, err := db.Pgx(q)
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := pq.Exec(
+		ctx,
+		"INSERT INTO forge_deliveries (forge, delivery_id) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+		forge, deliveryId,
+	)
+	if err != nil {
+		return false, db.Classify(err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// LinkPR связывает PR с форджем в транзакции q; повторная связь ничего не меняет.
+func (fr *forgeRepo) LinkPR(ctx context.Context, q db.Querier, link models.ForgePullRequest) error {
+	pq, err := db.Pgx(q)
+	if err != nil {
+		return err
+	}
+
+	const sql = `
+	INSERT INTO forge_pull_requests (pull_request_id, forge, project, number)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (pull_request_id) DO NOTHING
+	`
+
+	_, err = pq.Exec(ctx, sql, link.PullRequestId, link.Forge, link.Project, link.Number)
+	return db.Classify(err)
+}
+
+func (fr *forgeRepo) GetPRLink(ctx context.Context, prId string) (*models.ForgePullRequest, error) {
+	var link models.ForgePullRequest
+	err := fr.Pool.QueryRow(
+		ctx,
+		"SELECT pull_request_id, forge, project, number FROM forge_pull_requests WHERE pull_request_id = $1",
+		prId,
+	).Scan(&link.PullRequestId, &link.Forge, &link.Project, &link.Number)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, prerrors.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &link, nil
+}
+
+// ForgeLogins возвращает логины пользователей в фордже.
+// Пользователей без явного сопоставления в ответе нет.
+func (fr *forgeRepo) ForgeLogins(ctx context.Context, forge string, userIds []string) (map[string]string, error) {
+	const sql = `
+	SELECT DISTINCT ON (user_id) user_id, login
+	FROM forge_identities
+	WHERE forge = $1 AND user_id = ANY($2)
+	ORDER BY user_id, login
+	`
+
+	rows, err := fr.Pool.Query(ctx, sql, forge, userIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logins := make(map[string]string, len(userIds))
+	for rows.Next() {
+		var userId, login string
+		if err := rows.Scan(&userId, &login); err != nil {
+			return nil, err
+		}
+		logins[userId] = login
+	}
+
+	return logins, rows.Err()
+}
@@ -0,0 +1,100 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/models"
+)
+
+type idempotencyRepo struct {
+	Pool *pgxpool.Pool
+}
+
+func NewIdempotencyRepo(pool *pgxpool.Pool) IdempotencyRepo {
+	return &idempotencyRepo{
+		Pool: pool,
+	}
+}
+
+// Reserve занимает ключ под новый запрос. Возвращает false, если ключ
+// уже занят и ещё не истёк — тогда запись нужно прочитать через Get.
+func (ir *idempotencyRepo) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (bool, error) {
+	_, err := ir.Pool.Exec(
+		ctx,
+		"DELETE FROM idempotency_keys WHERE idempotency_key = $1 AND expires_at <= NOW()",
+		key,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	const sql = `
+	INSERT INTO idempotency_keys (idempotency_key, fingerprint, expires_at)
+	VALUES ($1, $2, NOW() + $3 * INTERVAL '1 second')
+	ON CONFLICT (idempotency_key) DO NOTHING
+	`
+
+	tag, err := ir.Pool.Exec(ctx, sql, key, fingerprint, ttl.Seconds())
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (ir *idempotencyRepo) Get(ctx context.Context, key string) (*models.IdempotencyRecord, error) {
+	const sql = `
+	SELECT idempotency_key, fingerprint, COALESCE(status_code, 0), response_headers, COALESCE(response_body, ''::bytea)
+	FROM idempotency_keys
+	WHERE idempotency_key = $1
+	`
+
+	var rec models.IdempotencyRecord
+	err := ir.Pool.QueryRow(ctx, sql, key).Scan(&rec.Key, &rec.Fingerprint, &rec.StatusCode, &rec.Header, &rec.Body)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, prerrors.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &rec, nil
+}
+
+func (ir *idempotencyRepo) Complete(ctx context.Context, key string, statusCode int, header http.Header, body []byte) error {
+	headers, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	_, err = ir.Pool.Exec(
+		ctx,
+		"UPDATE idempotency_keys SET status_code = $2, response_headers = $3, response_body = $4 WHERE idempotency_key = $1",
+		key, statusCode, headers, body,
+	)
+	return err
+}
+
+func (ir *idempotencyRepo) Release(ctx context.Context, key string) error {
+	_, err := ir.Pool.Exec(
+		ctx,
+		"DELETE FROM idempotency_keys WHERE idempotency_key = $1 AND status_code IS NULL",
+		key,
+	)
+	return err
+}
+
+func (ir *idempotencyRepo) PurgeExpired(ctx context.Context) (int64, error) {
+	tag, err := ir.Pool.Exec(ctx, "DELETE FROM idempotency_keys WHERE expires_at <= NOW()")
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/repo/db"
+)
+
+type forgeRepo struct {
+	store *store
+}
+
+// ResolveUser ищет пользователя по явному соответствию логина,
+// а если его нет — по совпадению логина с user_id.
+func (fr *forgeRepo) ResolveUser(ctx context.Context, forge, login string) (string, error) {
+	d := fr.store.read()
+	if userId, ok := d.identities[identityKey{Forge: forge, Login: login}]; ok {
+		return userId, nil
+	}
+	if _, ok := d.users[login]; ok {
+		return login, nil
+	}
+	return "", prerrors.ErrNotFound
+}
+
+func (fr *forgeRepo) UpsertIdentity(ctx context.Context, identity models.ForgeIdentity) error {
+	return fr.store.write(ctx, func(d *data) error {
+		if _, ok := d.users[identity.UserId]; !ok {
+			return prerrors.ErrNotFound
+		}
+		d.identities[identityKey{Forge: identity.Forge, Login: identity.Login}] = identity.UserId
+		return nil
+	})
+}
+
+// ClaimDelivery помечает доставку обработанной в транзакции q: откат
+// транзакции снимает отметку. Возвращает false, если такая доставка уже была.
+func (fr *forgeRepo) ClaimDelivery(ctx context.Context, q db.Querier, forge, deliveryId string) (bool, error) {
+	d, err := fr.store.txData(q)
+	if err != nil {
+		return false, err
+	}
+
+	key := deliveryKey{Forge: forge, DeliveryId: deliveryId}
+	if _, ok := d.deliveries[key]; ok {
+		return false, nil
+	}
+	d.deliveries[key] = struct{}{}
+	return true, nil
+}
+
+// LinkPR связывает PR с форджем в транзакции q; повторная связь ничего не меняет.
+func (fr *forgeRepo) LinkPR(ctx context.Context, q db.Querier, link models.ForgePullRequest) error {
+	d, err := fr.store.txData(q)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := d.prs[link.PullRequestId]; !ok {
+		return prerrors.ErrNotFound
+	}
+	if _, ok := d.prLinks[link.PullRequestId]; ok {
+		return nil
+	}
+	for _, l := range d.prLinks {
+		if l.Forge == link.Forge && l.Project == link.Project && l.Number == link.Number {
+			return prerrors.ErrConflict
+		}
+	}
+	d.prLinks[link.PullRequestId] = link
+	return nil
+}
+
+func (fr *forgeRepo) GetPRLink(ctx context.Context, prId string) (*models.ForgePullRequest, error) {
+	link, ok := fr.store.read().prLinks[prId]
+	if !ok {
+		return nil, prerrors.ErrNotFound
+	}
+	return &link, nil
+}
+
+// ForgeLogins возвращает логины пользователей в фордже.
+// Пользователей без явного сопоставления в ответе нет.
+func (fr *forgeRepo) ForgeLogins(ctx context.Context, forge string, userIds []string) (map[string]string, error) {
+	d := fr.store.read()
+	wanted := make(map[string]struct{}, len(userIds))
+	for _, id := range userIds {
+		wanted[id] = struct{}{}
+	}
+
+	// При нескольких логинах берётся наименьший, как DISTINCT ON ... ORDER BY login.
+	logins := make(map[string]string, len(userIds))
+	for key, userId := range d.identities {
+		if key.Forge != forge {
+			continue
+		}
+		if _, ok := wanted[userId]; !ok {
+			continue
+		}
+		if l, ok := logins[userId]; !ok || key.Login < l {
+			logins[userId] = key.Login
+		}
+	}
+
+	return logins, nil
+}
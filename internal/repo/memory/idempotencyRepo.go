@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"time"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/models"
+)
+
+type idempotencyRepo struct {
+	store *store
+}
+
+// Reserve занимает ключ под новый запрос. Возвращает false, если ключ
+// уже занят и ещё не истёк — тогда запись нужно прочитать через Get.
+func (ir *idempotencyRepo) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (bool, error) {
+	reserved := false
+	err := ir.store.write(ctx, func(d *data) error {
+		at := now()
+		if k, ok := d.idempotency[key]; ok && at.Before(k.ExpiresAt) {
+			return nil
+		}
+		d.idempotency[key] = idempotencyKey{
+			IdempotencyRecord: models.IdempotencyRecord{Key: key, Fingerprint: fingerprint},
+			ExpiresAt:         at.Add(ttl),
+		}
+		reserved = true
+		return nil
+	})
+	return reserved, err
+}
+
+func (ir *idempotencyRepo) Get(ctx context.Context, key string) (*models.IdempotencyRecord, error) {
+	k, ok := ir.store.read().idempotency[key]
+	if !ok {
+		return nil, prerrors.ErrNotFound
+	}
+
+	rec := k.IdempotencyRecord
+	rec.Header = rec.Header.Clone()
+	rec.Body = slices.Clone(rec.Body)
+	return &rec, nil
+}
+
+func (ir *idempotencyRepo) Complete(ctx context.Context, key string, statusCode int, header http.Header, body []byte) error {
+	return ir.store.write(ctx, func(d *data) error {
+		k, ok := d.idempotency[key]
+		if !ok {
+			return nil
+		}
+		k.StatusCode = statusCode
+		k.Header = header.Clone()
+		k.Body = slices.Clone(body)
+		d.idempotency[key] = k
+		return nil
+	})
+}
+
+func (ir *idempotencyRepo) Release(ctx context.Context, key string) error {
+	return ir.store.write(ctx, func(d *data) error {
+		if k, ok := d.idempotency[key]; ok && k.StatusCode == 0 {
+			delete(d.idempotency, key)
+		}
+		return nil
+	})
+}
+
+func (ir *idempotencyRepo) PurgeExpired(ctx context.Context) (int64, error) {
+	var purged int64
+	err := ir.store.write(ctx, func(d *data) error {
+		at := now()
+		for key, k := range d.idempotency {
+			if !at.Before(k.ExpiresAt) {
+				delete(d.idempotency, key)
+				purged++
+			}
+		}
+		return nil
+	})
+	return purged, err
+}
@@ -0,0 +1,15 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/andro-kes/avito_test/internal/repo"
+	"github.com/andro-kes/avito_test/internal/repo/memory"
+	"github.com/andro-kes/avito_test/internal/repo/repotest"
+)
+
+func TestStorage(t *testing.T) {
+	repotest.Run(t, func(*testing.T) *repo.Storage {
+		return memory.NewStorage()
+	})
+}
@@ -0,0 +1,582 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/repo/db"
+)
+
+type prRepo struct {
+	store *store
+}
+
+var prStatuses = []string{"OPEN", "MERGED", "CLOSED"}
+
+func (p *prRepo) CreatePR(ctx context.Context, q db.Querier, pr *models.PullRequestShort, reviewers []string) (*models.PullRequest, error) {
+	d, err := p.store.txData(q)
+	if err != nil {
+		return nil, err
+	}
+
+	status := "OPEN"
+	if pr.Status != "" {
+		status = pr.Status
+	}
+	if !slices.Contains(prStatuses, status) {
+		return nil, prerrors.Validation("unknown status %q", status)
+	}
+	if _, ok := d.prs[pr.PullRequestId]; ok {
+		return nil, prerrors.ErrPRExists
+	}
+	if _, ok := d.users[pr.AuthorId]; !ok {
+		return nil, prerrors.ErrNotFound
+	}
+	for _, r := range reviewers {
+		if _, ok := d.users[r]; !ok {
+			return nil, prerrors.ErrNotFound
+		}
+	}
+
+	createdAt := now()
+	stored := pullRequest{PullRequest: models.PullRequest{
+		PullRequestId:     pr.PullRequestId,
+		PullRequestName:   pr.PullRequestName,
+		AuthorId:          pr.AuthorId,
+		Status:            status,
+		AssignedReviewers: append(make([]string, 0, len(reviewers)), reviewers...),
+		CreatedAt:         &createdAt,
+	}}
+	d.prs[pr.PullRequestId] = stored
+	d.syncReviewerHistory(pr.PullRequestId, stored.AssignedReviewers, createdAt)
+
+	return stored.model(), nil
+}
+
+func (p *prRepo) FindActiveReviewers(ctx context.Context, q db.Querier, authorId string) ([]string, error) {
+	d, err := p.store.txData(q)
+	if err != nil {
+		return nil, err
+	}
+
+	author, ok := d.users[authorId]
+	if !ok {
+		return nil, nil
+	}
+	return d.activeUsers(author.TeamName, authorId), nil
+}
+
+func (p *prRepo) FindActiveReviewersInTeam(ctx context.Context, q db.Querier, teamName, authorId string) ([]string, error) {
+	d, err := p.store.txData(q)
+	if err != nil {
+		return nil, err
+	}
+	return d.activeUsers(teamName, authorId), nil
+}
+
+// activeUsers возвращает активных участников команды, кроме exclude.
+func (d *data) activeUsers(team, exclude string) []string {
+	var active []string
+	for _, u := range d.users {
+		if u.TeamName == team && u.IsActive && u.UserId != exclude {
+			active = append(active, u.UserId)
+		}
+	}
+	slices.Sort(active)
+	return active
+}
+
+func (p *prRepo) CheckExistingPR(ctx context.Context, q db.Querier, id string) (bool, error) {
+	d, err := p.store.txData(q)
+	if err != nil {
+		return false, err
+	}
+
+	_, ok := d.prs[id]
+	return ok, nil
+}
+
+func (p *prRepo) MergePR(ctx context.Context, q db.Querier, id string) (*models.PullRequest, error) {
+	return p.update(q, id, func(pr *pullRequest) error {
+		pr.Status = "MERGED"
+		if pr.MergedAt == nil {
+			mergedAt := now()
+			pr.MergedAt = &mergedAt
+		}
+		return nil
+	})
+}
+
+func (p *prRepo) ClosePR(ctx context.Context, q db.Querier, id string) (*models.PullRequest, error) {
+	return p.update(q, id, func(pr *pullRequest) error {
+		if pr.Status == "MERGED" {
+			return prerrors.ErrPRMerged
+		}
+		pr.Status = "CLOSED"
+		if pr.ClosedAt == nil {
+			closedAt := now()
+			pr.ClosedAt = &closedAt
+		}
+		return nil
+	})
+}
+
+func (p *prRepo) ReopenPR(ctx context.Context, q db.Querier, id string) (*models.PullRequest, error) {
+	return p.update(q, id, func(pr *pullRequest) error {
+		if pr.Status == "MERGED" {
+			return prerrors.ErrPRMerged
+		}
+		pr.Status = "OPEN"
+		pr.ClosedAt = nil
+		return nil
+	})
+}
+
+func (p *prRepo) SetDraft(ctx context.Context, q db.Querier, id string, isDraft bool) (*models.PullRequest, error) {
+	return p.update(q, id, func(pr *pullRequest) error {
+		pr.IsDraft = isDraft
+		return nil
+	})
+}
+
+// update меняет PR функцией fn в транзакции q и возвращает результат;
+// PR нет — NOT_FOUND.
+func (p *prRepo) update(q db.Querier, id string, fn func(pr *pullRequest) error) (*models.PullRequest, error) {
+	d, err := p.store.txData(q)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, ok := d.prs[id]
+	if !ok {
+		return nil, prerrors.ErrNotFound
+	}
+	if err := fn(&pr); err != nil {
+		return nil, err
+	}
+	d.prs[id] = pr
+	return pr.model(), nil
+}
+
+func (p *prRepo) IsMerged(ctx context.Context, q db.Querier, id string) error {
+	d, err := p.store.txData(q)
+	if err != nil {
+		return err
+	}
+
+	pr, ok := d.prs[id]
+	if !ok {
+		return prerrors.ErrNotFound
+	}
+	if pr.MergedAt != nil {
+		return prerrors.ErrPRMerged
+	}
+	return nil
+}
+
+func (p *prRepo) FindReplacementReviewers(ctx context.Context, q db.Querier, prID string, oldUserId []string) ([]string, error) {
+	d, err := p.store.txData(q)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, ok := d.prs[prID]
+	if !ok {
+		return nil, nil
+	}
+	teams := make(map[string]struct{}, len(oldUserId))
+	for _, id := range oldUserId {
+		if u, ok := d.users[id]; ok {
+			teams[u.TeamName] = struct{}{}
+		}
+	}
+
+	var replace []string
+	for _, u := range d.users {
+		if _, ok := teams[u.TeamName]; !ok || !u.IsActive || u.UserId == pr.AuthorId {
+			continue
+		}
+		if slices.Contains(oldUserId, u.UserId) || slices.Contains(pr.AssignedReviewers, u.UserId) {
+			continue
+		}
+		replace = append(replace, u.UserId)
+	}
+	slices.Sort(replace)
+	return replace, nil
+}
+
+func (p *prRepo) ReassignReviewer(ctx context.Context, q db.Querier, prId, oldUserId, replacedBy string) (*models.PullRequest, error) {
+	d, err := p.store.txData(q)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, ok := d.prs[prId]
+	if !ok {
+		return nil, prerrors.ErrNotFound
+	}
+	switch pr.Status {
+	case "MERGED":
+		return nil, prerrors.ErrPRMerged
+	case "CLOSED":
+		return nil, prerrors.ErrConflict
+	}
+	i := slices.Index(pr.AssignedReviewers, oldUserId)
+	if i < 0 {
+		return nil, prerrors.ErrNotAssigned
+	}
+	if _, ok := d.users[replacedBy]; !ok {
+		return nil, prerrors.ErrNotFound
+	}
+
+	reviewers := slices.Clone(pr.AssignedReviewers)
+	reviewers[i] = replacedBy
+	pr.AssignedReviewers = reviewers
+	d.prs[prId] = pr
+	d.syncReviewerHistory(prId, reviewers, now())
+
+	return pr.model(), nil
+}
+
+func (p *prRepo) GetListByUsers(ctx context.Context, q db.Querier, ids []string) ([]models.PullRequest, error) {
+	d, err := p.store.txData(q)
+	if err != nil {
+		return make([]models.PullRequest, 0), err
+	}
+
+	prs := make([]models.PullRequest, 0)
+	for _, pr := range d.prs {
+		if pr.Status != "OPEN" {
+			continue
+		}
+		if slices.ContainsFunc(pr.AssignedReviewers, func(r string) bool { return slices.Contains(ids, r) }) {
+			prs = append(prs, *pr.model())
+		}
+	}
+	slices.SortFunc(prs, func(a, b models.PullRequest) int {
+		return strings.Compare(a.PullRequestId, b.PullRequestId)
+	})
+	return prs, nil
+}
+
+func (p *prRepo) ChangeDeactivatedReviewers(ctx context.Context, q db.Querier, prId string, replaced []string) error {
+	d, err := p.store.txData(q)
+	if err != nil {
+		return err
+	}
+
+	pr, ok := d.prs[prId]
+	if !ok {
+		return prerrors.ErrNotFound
+	}
+	for _, r := range replaced {
+		if _, ok := d.users[r]; !ok {
+			return prerrors.ErrNotFound
+		}
+	}
+
+	pr.AssignedReviewers = append(make([]string, 0, len(replaced)), replaced...)
+	d.prs[prId] = pr
+	d.syncReviewerHistory(prId, pr.AssignedReviewers, now())
+	return nil
+}
+
+// syncReviewerHistory приводит текущие назначения PR к списку reviewers:
+// снятые получают UnassignedAt, новые добавляются с AssignedAt = at.
+func (d *data) syncReviewerHistory(prId string, reviewers []string, at time.Time) {
+	history := slices.Clone(d.reviewers[prId])
+	current := make(map[string]struct{}, len(reviewers))
+	for i := range history {
+		rv := &history[i]
+		if rv.UnassignedAt != nil {
+			continue
+		}
+		if !slices.Contains(reviewers, rv.UserId) {
+			rv.UnassignedAt = &at
+			continue
+		}
+		current[rv.UserId] = struct{}{}
+	}
+	for _, r := range reviewers {
+		if _, ok := current[r]; ok {
+			continue
+		}
+		current[r] = struct{}{}
+		history = append(history, reviewer{UserId: r, AssignedAt: at, Decision: models.ReviewPending})
+	}
+	d.reviewers[prId] = history
+}
+
+// currentReviewer возвращает индекс текущего назначения userId на PR или -1.
+func (d *data) currentReviewer(prId, userId string) int {
+	return slices.IndexFunc(d.reviewers[prId], func(rv reviewer) bool {
+		return rv.UserId == userId && rv.UnassignedAt == nil
+	})
+}
+
+func (p *prRepo) FindExistingPRs(ctx context.Context, q db.Querier, ids []string) (map[string]struct{}, error) {
+	d, err := p.store.txData(q)
+	if err != nil {
+		return nil, err
+	}
+	return existingIds(d.prs, ids), nil
+}
+
+func (p *prRepo) ImportPRs(ctx context.Context, q db.Querier, prs []models.PullRequest) (int64, error) {
+	d, err := p.store.txData(q)
+	if err != nil {
+		return 0, err
+	}
+
+	// Как COPY: одна ошибочная строка отменяет весь пакет.
+	batch := make(map[string]struct{}, len(prs))
+	for _, pr := range prs {
+		if _, ok := d.prs[pr.PullRequestId]; ok {
+			return 0, prerrors.ErrPRExists
+		}
+		if _, ok := batch[pr.PullRequestId]; ok {
+			return 0, prerrors.ErrPRExists
+		}
+		batch[pr.PullRequestId] = struct{}{}
+		if !slices.Contains(prStatuses, pr.Status) || pr.CreatedAt == nil {
+			return 0, prerrors.Validation("invalid pull request %s", pr.PullRequestId)
+		}
+		if _, ok := d.users[pr.AuthorId]; !ok {
+			return 0, prerrors.ErrNotFound
+		}
+		for _, r := range pr.AssignedReviewers {
+			if _, ok := d.users[r]; !ok {
+				return 0, prerrors.ErrNotFound
+			}
+		}
+	}
+
+	for _, pr := range prs {
+		createdAt := timestamp(*pr.CreatedAt)
+		stored := pullRequest{PullRequest: pr}
+		stored.AssignedReviewers = append(make([]string, 0, len(pr.AssignedReviewers)), pr.AssignedReviewers...)
+		stored.CreatedAt = &createdAt
+		if pr.MergedAt != nil {
+			mergedAt := timestamp(*pr.MergedAt)
+			stored.MergedAt = &mergedAt
+		}
+		// Время закрытия в модели не хранится — считаем PR закрытым в момент импорта.
+		if pr.Status == "CLOSED" {
+			closedAt := now()
+			stored.ClosedAt = &closedAt
+		}
+		d.prs[pr.PullRequestId] = stored
+
+		// Импортированные ревьюверы считаются назначенными при создании PR
+		d.syncReviewerHistory(pr.PullRequestId, stored.AssignedReviewers, createdAt)
+	}
+
+	return int64(len(prs)), nil
+}
+
+// GetPRDetails возвращает PR с автором и ревьюверами в порядке assigned_reviewers.
+func (p *prRepo) GetPRDetails(ctx context.Context, id string) (*models.PullRequestDetails, error) {
+	d := p.store.read()
+	pr, ok := d.prs[id]
+	if !ok {
+		return nil, prerrors.ErrNotFound
+	}
+
+	details := &models.PullRequestDetails{
+		PullRequestId:     pr.PullRequestId,
+		PullRequestName:   pr.PullRequestName,
+		Author:            d.users[pr.AuthorId],
+		Status:            pr.Status,
+		AssignedReviewers: make([]models.PRReviewer, 0, len(pr.AssignedReviewers)),
+		IsDraft:           pr.IsDraft,
+		CreatedAt:         pr.CreatedAt,
+		MergedAt:          pr.MergedAt,
+	}
+	for _, userId := range pr.AssignedReviewers {
+		rv := models.PRReviewer{User: d.users[userId], Decision: models.ReviewPending}
+		rv.UserId = userId
+		if i := d.currentReviewer(id, userId); i >= 0 {
+			row := d.reviewers[id][i]
+			rv.AssignedAt = &row.AssignedAt
+			rv.Decision = row.Decision
+		}
+		details.AssignedReviewers = append(details.AssignedReviewers, rv)
+	}
+
+	return details, nil
+}
+
+// SetDecision записывает решение текущего ревьювера; DecidedAt хранит время
+// первого решения, чтобы считать время до первого ревью. Решения по смерженным
+// и закрытым PR не принимаются.
+func (p *prRepo) SetDecision(ctx context.Context, q db.Querier, prId, userId, decision string) error {
+	switch decision {
+	case models.ReviewPending, models.ReviewApproved, models.ReviewChangesRequested:
+	default:
+		return prerrors.Validation("unknown decision %q", decision)
+	}
+
+	d, err := p.store.txData(q)
+	if err != nil {
+		return err
+	}
+
+	pr, ok := d.prs[prId]
+	if !ok {
+		return prerrors.ErrNotFound
+	}
+	switch pr.Status {
+	case "MERGED":
+		return prerrors.ErrPRMerged
+	case "CLOSED":
+		return prerrors.ErrConflict
+	}
+	i := d.currentReviewer(prId, userId)
+	if i < 0 {
+		return prerrors.ErrNotAssigned
+	}
+
+	history := slices.Clone(d.reviewers[prId])
+	history[i].Decision = decision
+	if history[i].DecidedAt == nil {
+		decidedAt := now()
+		history[i].DecidedAt = &decidedAt
+	}
+	d.reviewers[prId] = history
+	return nil
+}
+
+func (p *prRepo) ListPRs(ctx context.Context, f models.PRListFilter) ([]models.PullRequest, error) {
+	key, ok := prSortKeys[f.Sort]
+	if !ok {
+		return nil, prerrors.ErrInvalidQuery
+	}
+
+	var after *sortKey
+	if c := f.After; c != nil {
+		k, err := cursorKey(f.Sort, c)
+		if err != nil {
+			return nil, prerrors.Wrap(prerrors.ErrInvalidQuery, err)
+		}
+		after = &k
+	}
+
+	d := p.store.read()
+	name := strings.ToLower(f.Name)
+	prs := make([]models.PullRequest, 0, max(f.Limit, 4))
+	for _, pr := range d.prs {
+		switch {
+		case f.Status != "" && pr.Status != f.Status,
+			f.AuthorId != "" && pr.AuthorId != f.AuthorId,
+			f.ReviewerId != "" && !slices.Contains(pr.AssignedReviewers, f.ReviewerId),
+			f.TeamName != "" && d.users[pr.AuthorId].TeamName != f.TeamName,
+			name != "" && !strings.Contains(strings.ToLower(pr.PullRequestName), name),
+			!inRange(pr.CreatedAt, f.CreatedFrom, f.CreatedTo),
+			!inRange(pr.MergedAt, f.MergedFrom, f.MergedTo),
+			// Сортировка по merged_at имеет смысл только для смёрженных PR
+			f.Sort == models.PRSortMergedAt && pr.MergedAt == nil:
+			continue
+		}
+		if after != nil {
+			c := compareKeys(key(&pr.PullRequest), *after)
+			if f.Desc && c >= 0 || !f.Desc && c <= 0 {
+				continue
+			}
+		}
+		prs = append(prs, *pr.model())
+	}
+
+	slices.SortFunc(prs, func(a, b models.PullRequest) int {
+		c := compareKeys(key(&a), key(&b))
+		if f.Desc {
+			return -c
+		}
+		return c
+	})
+	if f.Limit > 0 && len(prs) > f.Limit {
+		prs = prs[:f.Limit]
+	}
+
+	return prs, nil
+}
+
+// sortKey — значение поля сортировки и id PR для keyset-пагинации.
+type sortKey struct {
+	at    time.Time
+	value string
+	id    string
+}
+
+var prSortKeys = map[string]func(pr *models.PullRequest) sortKey{
+	models.PRSortCreatedAt: func(pr *models.PullRequest) sortKey {
+		return sortKey{at: timeOrZero(pr.CreatedAt), id: pr.PullRequestId}
+	},
+	models.PRSortMergedAt: func(pr *models.PullRequest) sortKey {
+		return sortKey{at: timeOrZero(pr.MergedAt), id: pr.PullRequestId}
+	},
+	models.PRSortName: func(pr *models.PullRequest) sortKey {
+		return sortKey{value: pr.PullRequestName, id: pr.PullRequestId}
+	},
+	models.PRSortId: func(pr *models.PullRequest) sortKey {
+		return sortKey{id: pr.PullRequestId}
+	},
+}
+
+func compareKeys(a, b sortKey) int {
+	if c := a.at.Compare(b.at); c != 0 {
+		return c
+	}
+	if c := strings.Compare(a.value, b.value); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.id, b.id)
+}
+
+// cursorKey разбирает курсор так же, как Postgres приводит его значение
+// к типу колонки сортировки.
+func cursorKey(sort string, c *models.PRCursor) (sortKey, error) {
+	switch sort {
+	case models.PRSortCreatedAt, models.PRSortMergedAt:
+		at, err := time.Parse("2006-01-02T15:04:05.999999", c.Value)
+		return sortKey{at: at, id: c.Id}, err
+	case models.PRSortName:
+		return sortKey{value: c.Value, id: c.Id}, nil
+	}
+	return sortKey{id: c.Id}, nil
+}
+
+// inRange: нижняя граница включается, верхняя — нет; пустое время вне
+// любого заданного диапазона.
+func inRange(t, from, to *time.Time) bool {
+	if from == nil && to == nil {
+		return true
+	}
+	if t == nil {
+		return false
+	}
+	return (from == nil || !t.Before(*from)) && (to == nil || t.Before(*to))
+}
+
+func timeOrZero(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+// timestamp приводит время к виду колонки TIMESTAMP: UTC с точностью до микросекунд.
+func timestamp(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Microsecond)
+}
+
+// model возвращает копию PR, которую вызывающий может менять.
+func (pr pullRequest) model() *models.PullRequest {
+	m := pr.PullRequest
+	m.AssignedReviewers = append(make([]string, 0, len(pr.AssignedReviewers)), pr.AssignedReviewers...)
+	return &m
+}
@@ -0,0 +1,107 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/repo/agg"
+)
+
+type statsRepo struct {
+	store *store
+}
+
+// teamUsers возвращает пользователей команды teamName (пусто — всех).
+func (d *data) teamUsers(teamName string) []models.User {
+	users := make([]models.User, 0)
+	for _, u := range d.users {
+		if teamName == "" || u.TeamName == teamName {
+			users = append(users, u)
+		}
+	}
+	return users
+}
+
+func (d *data) reviews() []agg.Review {
+	reviews := make([]agg.Review, 0)
+	for prId, history := range d.reviewers {
+		var createdAt time.Time
+		if pr, ok := d.prs[prId]; ok && pr.CreatedAt != nil {
+			createdAt = *pr.CreatedAt
+		}
+		for _, rv := range history {
+			reviews = append(reviews, agg.Review{
+				UserId:        rv.UserId,
+				PullRequestId: prId,
+				PRCreatedAt:   createdAt,
+				AssignedAt:    rv.AssignedAt,
+				UnassignedAt:  rv.UnassignedAt,
+				DecidedAt:     rv.DecidedAt,
+			})
+		}
+	}
+	return reviews
+}
+
+func (sr *statsRepo) ReviewerStats(ctx context.Context, w models.StatsWindow, teamName string) ([]models.ReviewerStats, error) {
+	d := sr.store.read()
+
+	openLoad := make(map[string]int)
+	for _, pr := range d.prs {
+		if pr.Status != "OPEN" {
+			continue
+		}
+		for _, r := range pr.AssignedReviewers {
+			openLoad[r]++
+		}
+	}
+
+	return agg.ReviewerStats(w, d.teamUsers(teamName), d.reviews(), openLoad), nil
+}
+
+func (sr *statsRepo) TeamReviewStats(ctx context.Context, w models.StatsWindow, teamName string) ([]models.TeamReviewStats, error) {
+	d := sr.store.read()
+	return agg.TeamReviewStats(w, d.teamUsers(teamName), d.reviews()), nil
+}
+
+func (sr *statsRepo) PRThroughput(ctx context.Context, w models.StatsWindow, tz, teamName string) ([]models.PRThroughput, error) {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, err
+	}
+
+	d := sr.store.read()
+	prs := make([]agg.PR, 0, len(d.prs))
+	for _, pr := range d.prs {
+		team := d.users[pr.AuthorId].TeamName
+		if teamName != "" && team != teamName {
+			continue
+		}
+		prs = append(prs, agg.PR{
+			TeamName:  team,
+			CreatedAt: *pr.CreatedAt,
+			MergedAt:  pr.MergedAt,
+			ClosedAt:  pr.ClosedAt,
+			Reviewers: len(pr.AssignedReviewers),
+		})
+	}
+
+	return agg.Throughput(w, loc, prs), nil
+}
+
+// OpenPRsByTeam считает открытые PR по команде автора.
+func (sr *statsRepo) OpenPRsByTeam(ctx context.Context) (map[string]int, error) {
+	d := sr.store.read()
+
+	open := make(map[string]int)
+	for _, pr := range d.prs {
+		if pr.Status != "OPEN" {
+			continue
+		}
+		if author, ok := d.users[pr.AuthorId]; ok {
+			open[author.TeamName]++
+		}
+	}
+	return open, nil
+}
@@ -0,0 +1,192 @@
+// Package memory — хранилище в памяти процесса с той же семантикой, что и
+// Postgres: транзакции, уникальность, внешние ключи и ошибки NOT_FOUND.
+// Подходит для тестов и локального запуска; данные теряются при остановке.
+package memory
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/repo"
+	"github.com/andro-kes/avito_test/internal/repo/db"
+	"github.com/andro-kes/avito_test/internal/tracing"
+)
+
+// NewStorage возвращает пустое хранилище в памяти.
+func NewStorage() *repo.Storage {
+	s := newStore()
+	return &repo.Storage{
+		Backend:     repo.BackendMemory,
+		PRs:         &prRepo{store: s},
+		Teams:       &teamRepo{store: s},
+		Users:       &userRepo{store: s},
+		Stats:       &statsRepo{store: s},
+		Forge:       &forgeRepo{store: s},
+		Idempotency: &idempotencyRepo{store: s},
+		Tx:          s,
+	}
+}
+
+// data — содержимое всех таблиц. Зафиксированная версия никогда не меняется:
+// транзакция правит копию и подменяет ею зафиксированную при коммите, поэтому
+// значения в картах заменяются целиком, а срезы в них не изменяются на месте.
+type data struct {
+	teams map[string]struct{}
+	users map[string]models.User
+	prs   map[string]pullRequest
+	// reviewers — история назначений по PR в порядке добавления.
+	reviewers map[string][]reviewer
+
+	identities map[identityKey]string
+	deliveries map[deliveryKey]struct{}
+	prLinks    map[string]models.ForgePullRequest
+
+	idempotency map[string]idempotencyKey
+}
+
+type pullRequest struct {
+	models.PullRequest
+	ClosedAt *time.Time
+}
+
+type reviewer struct {
+	UserId       string
+	AssignedAt   time.Time
+	UnassignedAt *time.Time
+	Decision     string
+	DecidedAt    *time.Time
+}
+
+type identityKey struct {
+	Forge string
+	Login string
+}
+
+type deliveryKey struct {
+	Forge      string
+	DeliveryId string
+}
+
+type idempotencyKey struct {
+	models.IdempotencyRecord
+	ExpiresAt time.Time
+}
+
+func (d *data) clone() *data {
+	return &data{
+		teams:       maps.Clone(d.teams),
+		users:       maps.Clone(d.users),
+		prs:         maps.Clone(d.prs),
+		reviewers:   maps.Clone(d.reviewers),
+		identities:  maps.Clone(d.identities),
+		deliveries:  maps.Clone(d.deliveries),
+		prLinks:     maps.Clone(d.prLinks),
+		idempotency: maps.Clone(d.idempotency),
+	}
+}
+
+// store хранит зафиксированные данные. Читатели берут их без блокировок,
+// пишущие транзакции выполняются по одной.
+type store struct {
+	writeMu   sync.Mutex
+	committed atomic.Pointer[data]
+}
+
+func newStore() *store {
+	s := &store{}
+	s.committed.Store(&data{
+		teams:       make(map[string]struct{}),
+		users:       make(map[string]models.User),
+		prs:         make(map[string]pullRequest),
+		reviewers:   make(map[string][]reviewer),
+		identities:  make(map[identityKey]string),
+		deliveries:  make(map[deliveryKey]struct{}),
+		prLinks:     make(map[string]models.ForgePullRequest),
+		idempotency: make(map[string]idempotencyKey),
+	})
+	return s
+}
+
+type txKey struct{}
+
+// RunInTx выполняет fn над копией данных и фиксирует её, если fn не вернула
+// ошибку. Транзакция, открытая внутри другой, продолжает внешнюю: отдельного
+// соединения здесь нет, и ожидание внешней транзакции заблокировало бы её навсегда.
+func (s *store) RunInTx(ctx context.Context, fn func(ctx context.Context, q db.Querier) error) (err error) {
+	ctx, span := tracing.Start(ctx, "db.RunInTx")
+	defer func() { tracing.End(span, err) }()
+
+	if t := s.txFrom(ctx); t != nil {
+		return fn(ctx, t)
+	}
+
+	ctx, runHooks := db.WithAfterCommit(ctx)
+	if err := s.update(ctx, fn); err != nil {
+		return err
+	}
+
+	runHooks()
+	return nil
+}
+
+func (s *store) update(ctx context.Context, fn func(ctx context.Context, q db.Querier) error) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	t := &tx{store: s, data: s.committed.Load().clone()}
+	if err := fn(context.WithValue(ctx, txKey{}, t), t); err != nil {
+		return err
+	}
+
+	s.committed.Store(t.data)
+	return nil
+}
+
+// read выполняет запрос вне транзакции — над зафиксированными данными.
+func (s *store) read() *data {
+	return s.committed.Load()
+}
+
+// write выполняет одиночное изменение вне явной транзакции: как и запрос
+// к пулу, оно фиксируется сразу. Ошибка fn отменяет изменение целиком.
+func (s *store) write(ctx context.Context, fn func(d *data) error) error {
+	if t := s.txFrom(ctx); t != nil {
+		return fn(t.data)
+	}
+	return s.update(ctx, func(_ context.Context, q db.Querier) error {
+		return fn(q.(*tx).data)
+	})
+}
+
+// txData возвращает данные транзакции q, полученной из RunInTx.
+func (s *store) txData(q db.Querier) (*data, error) {
+	t, ok := q.(*tx)
+	if !ok || t.store != s {
+		return nil, db.ErrForeignQuerier
+	}
+	return t.data, nil
+}
+
+func (s *store) txFrom(ctx context.Context) *tx {
+	t, ok := ctx.Value(txKey{}).(*tx)
+	if !ok || t.store != s {
+		return nil
+	}
+	return t
+}
+
+// now — текущее время с точностью колонки TIMESTAMP.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// tx — открытая транзакция: копия данных, которая станет зафиксированной.
+type tx struct {
+	db.TxMarker
+	store *store
+	data  *data
+}
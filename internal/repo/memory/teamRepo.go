@@ -0,0 +1,100 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/repo/db"
+)
+
+type teamRepo struct {
+	store *store
+}
+
+func (tr *teamRepo) CheckUnique(ctx context.Context, name string) error {
+	if _, ok := tr.store.read().teams[name]; ok {
+		return prerrors.ErrTeamExists
+	}
+	return nil
+}
+
+func (tr *teamRepo) CreateTeam(ctx context.Context, q db.Querier, name string) error {
+	d, err := tr.store.txData(q)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := d.teams[name]; ok {
+		return prerrors.ErrTeamExists
+	}
+	d.teams[name] = struct{}{}
+	return nil
+}
+
+func (tr *teamRepo) GetTeam(ctx context.Context, name string) (*models.Team, error) {
+	d := tr.store.read()
+	if _, ok := d.teams[name]; !ok {
+		return nil, prerrors.ErrNotFound
+	}
+
+	return &models.Team{
+		TeamName: name,
+		Members:  d.members(name),
+	}, nil
+}
+
+// EnsureTeam создаёт команду, если её ещё нет. Возвращает true, если команда создана.
+func (tr *teamRepo) EnsureTeam(ctx context.Context, q db.Querier, name string) (bool, error) {
+	d, err := tr.store.txData(q)
+	if err != nil {
+		return false, err
+	}
+
+	if _, ok := d.teams[name]; ok {
+		return false, nil
+	}
+	d.teams[name] = struct{}{}
+	return true, nil
+}
+
+// ListTeams возвращает все команды с участниками, упорядоченные по имени.
+func (tr *teamRepo) ListTeams(ctx context.Context, q db.Querier) ([]models.Team, error) {
+	d, err := tr.store.txData(q)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(d.teams))
+	for name := range d.teams {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	teams := make([]models.Team, 0, len(names))
+	for _, name := range names {
+		teams = append(teams, models.Team{TeamName: name, Members: d.members(name)})
+	}
+	return teams, nil
+}
+
+// members возвращает участников команды по возрастанию user_id.
+func (d *data) members(team string) []models.TeamMember {
+	members := make([]models.TeamMember, 0)
+	for _, u := range d.users {
+		if u.TeamName != team {
+			continue
+		}
+		members = append(members, models.TeamMember{
+			UserID:   u.UserId,
+			Username: u.Username,
+			IsActive: u.IsActive,
+		})
+	}
+	slices.SortFunc(members, func(a, b models.TeamMember) int {
+		return strings.Compare(a.UserID, b.UserID)
+	})
+	return members
+}
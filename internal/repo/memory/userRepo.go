@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/repo/db"
+)
+
+type userRepo struct {
+	store *store
+}
+
+func (ur *userRepo) UpsertUser(ctx context.Context, q db.Querier, name string, m models.TeamMember) error {
+	d, err := ur.store.txData(q)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := d.teams[name]; !ok {
+		return prerrors.ErrNotFound
+	}
+	d.users[m.UserID] = models.User{
+		UserId:   m.UserID,
+		Username: m.Username,
+		TeamName: name,
+		IsActive: m.IsActive,
+	}
+	return nil
+}
+
+func (ur *userRepo) SetIsActive(ctx context.Context, q db.Querier, userId string, isActive bool) error {
+	d, err := ur.store.txData(q)
+	if err != nil {
+		return err
+	}
+
+	user, ok := d.users[userId]
+	if !ok {
+		return prerrors.ErrNotFound
+	}
+	user.IsActive = isActive
+	d.users[userId] = user
+	return nil
+}
+
+func (ur *userRepo) GetUser(ctx context.Context, userId string) (*models.User, error) {
+	user, ok := ur.store.read().users[userId]
+	if !ok {
+		return nil, prerrors.ErrNotFound
+	}
+	return &user, nil
+}
+
+func (ur *userRepo) CountReview(ctx context.Context, userId string) (int, error) {
+	d := ur.store.read()
+	if _, ok := d.users[userId]; !ok {
+		return 0, prerrors.ErrNotFound
+	}
+
+	cnt := 0
+	for _, pr := range d.prs {
+		if slices.Contains(pr.AssignedReviewers, userId) {
+			cnt++
+		}
+	}
+	return cnt, nil
+}
+
+func (ur *userRepo) DeactivateUsers(ctx context.Context, q db.Querier, userIds []string) error {
+	d, err := ur.store.txData(q)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range userIds {
+		if user, ok := d.users[id]; ok {
+			user.IsActive = false
+			d.users[id] = user
+		}
+	}
+	return nil
+}
+
+func (ur *userRepo) FindExistingUsers(ctx context.Context, q db.Querier, userIds []string) (map[string]struct{}, error) {
+	d, err := ur.store.txData(q)
+	if err != nil {
+		return nil, err
+	}
+	return existingIds(d.users, userIds), nil
+}
+
+// existingIds возвращает те из ids, что есть среди ключей m.
+func existingIds[V any](m map[string]V, ids []string) map[string]struct{} {
+	found := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := m[id]; ok {
+			found[id] = struct{}{}
+		}
+	}
+	return found
+}
+
+// LockTeammates ничего не блокирует: пишущие транзакции и так идут по одной.
+func (ur *userRepo) LockTeammates(ctx context.Context, q db.Querier, userIds []string) error {
+	_, err := ur.store.txData(q)
+	return err
+}
@@ -2,8 +2,12 @@ package repo
 
 import (
 	"context"
+	"errors"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	prerrors "github.com/andro-kes/avito_test/internal/errors"
@@ -22,6 +26,11 @@ func NewPRRepo(pool *pgxpool.Pool) PRRepo {
 }
 
 func (p *prRepo) CreatePR(ctx context.Context, q db.Querier, pr *models.PullRequestShort, reviewers []string) (*models.PullRequest, error) {
+	pq, err := db.Pgx(q)
+	if err != nil {
+		return nil, err
+	}
+
 	status := "OPEN"
 	if pr.Status != "" {
 		status = pr.Status
@@ -36,24 +45,39 @@ func (p *prRepo) CreatePR(ctx context.Context, q db.Querier, pr *models.PullRequ
     VALUES
     ($1, $2, $3, $4, COALESCE($5::text[], '{}'), $6, $7)
 	RETURNING 
-	pull_request_id, pull_request_name, author_id, status, assigned_reviewers, created_at, merged_at;
+	pull_request_id, pull_request_name, author_id, status, assigned_reviewers, is_draft, created_at, merged_at;
 	`
 
 	var pullRequest models.PullRequest
-	err := q.QueryRow(
+	err = pq.QueryRow(
 		ctx,
 		sql,
 		pr.PullRequestId, pr.PullRequestName, pr.AuthorId, status, reviewers, time.Now(), nil,
 	).Scan(
 		&pullRequest.PullRequestId, &pullRequest.PullRequestName,
 		&pullRequest.AuthorId, &pullRequest.Status,
-		&pullRequest.AssignedReviewers, &pullRequest.CreatedAt, &pullRequest.MergedAt,
+		&pullRequest.AssignedReviewers, &pullRequest.IsDraft, &pullRequest.CreatedAt, &pullRequest.MergedAt,
 	)
+	if db.IsUniqueViolation(err) {
+		return nil, prerrors.Wrap(prerrors.ErrPRExists, err)
+	}
+	if err != nil {
+		return nil, db.Classify(err)
+	}
 
+	err = syncReviewerHistory(ctx, pq, pullRequest.PullRequestId, pullRequest.AssignedReviewers)
 	return &pullRequest, err
 }
 
-func (p *prRepo) FindActiveReviewers(ctx context.Context, authorId string) ([]string, error) {
+// FindActiveReviewers блокирует найденных кандидатов до конца транзакции:
+// их деактивация дождётся коммита и увидит назначение. FOR NO KEY UPDATE
+// конфликтует с UPDATE пользователя, но не с проверками внешних ключей.
+func (p *prRepo) FindActiveReviewers(ctx context.Context, q db.Querier, authorId string) ([]string, error) {
+	pq, err := db.Pgx(q)
+	if err != nil {
+		return nil, err
+	}
+
 	sql := `
         SELECT user_id
         FROM users
@@ -64,9 +88,11 @@ func (p *prRepo) FindActiveReviewers(ctx context.Context, authorId string) ([]st
         )
         AND is_active = TRUE
         AND user_id <> $1
+        ORDER BY user_id
+        FOR NO KEY UPDATE
     `
 
-	rows, err := p.Pool.Query(ctx, sql, authorId)
+	rows, err := pq.Query(ctx, sql, authorId)
 	if err != nil {
 		return nil, err
 	}
@@ -88,9 +114,52 @@ func (p *prRepo) FindActiveReviewers(ctx context.Context, authorId string) ([]st
 	return active, nil
 }
 
-func (p *prRepo) CheckExistingPR(ctx context.Context, id string) (bool, error) {
+func (p *prRepo) FindActiveReviewersInTeam(ctx context.Context, q db.Querier, teamName, authorId string) ([]string, error) {
+	pq, err := db.Pgx(q)
+	if err != nil {
+		return nil, err
+	}
+
+	sql := `
+        SELECT user_id
+        FROM users
+        WHERE team_name = $1
+        AND is_active = TRUE
+        AND user_id <> $2
+        ORDER BY user_id
+        FOR NO KEY UPDATE
+    `
+
+	rows, err := pq.Query(ctx, sql, teamName, authorId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var active []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		active = append(active, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return active, nil
+}
+
+func (p *prRepo) CheckExistingPR(ctx context.Context, q db.Querier, id string) (bool, error) {
+	pq, err := db.Pgx(q)
+	if err != nil {
+		return false, err
+	}
+
 	var exists bool
-	err := p.Pool.QueryRow(
+	err = pq.QueryRow(
 		ctx,
 		"SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = $1)",
 		id,
@@ -101,7 +170,12 @@ func (p *prRepo) CheckExistingPR(ctx context.Context, id string) (bool, error) {
 	return exists, nil
 }
 
-func (p *prRepo) MergePR(ctx context.Context, id string) (*models.PullRequest, error) {
+func (p *prRepo) MergePR(ctx context.Context, q db.Querier, id string) (*models.PullRequest, error) {
+	pq, err := db.Pgx(q)
+	if err != nil {
+		return nil, err
+	}
+
 	const sql = `
 	UPDATE pull_requests
 	SET
@@ -114,11 +188,12 @@ func (p *prRepo) MergePR(ctx context.Context, id string) (*models.PullRequest, e
 		author_id,
 		status,
 		assigned_reviewers,
+		is_draft,
 		merged_at
 	`
 
 	var pr models.PullRequest
-	err := p.Pool.QueryRow(
+	err = pq.QueryRow(
 		ctx,
 		sql,
 		id,
@@ -128,32 +203,145 @@ func (p *prRepo) MergePR(ctx context.Context, id string) (*models.PullRequest, e
 		&pr.AuthorId,
 		&pr.Status,
 		&pr.AssignedReviewers,
+		&pr.IsDraft,
+		&pr.MergedAt,
+	)
+	if err != nil {
+		return nil, db.Classify(err)
+	}
+
+	return &pr, nil
+}
+
+func (p *prRepo) ClosePR(ctx context.Context, q db.Querier, id string) (*models.PullRequest, error) {
+	const sql = `
+	UPDATE pull_requests
+	SET
+		status = 'CLOSED',
+		closed_at = COALESCE(closed_at, NOW())
+	WHERE pull_request_id = $1
+	AND status <> 'MERGED'
+	RETURNING
+		pull_request_id,
+		pull_request_name,
+		author_id,
+		status,
+		assigned_reviewers,
+		is_draft,
+		merged_at
+	`
+
+	return p.updateStatus(ctx, q, sql, id)
+}
+
+func (p *prRepo) ReopenPR(ctx context.Context, q db.Querier, id string) (*models.PullRequest, error) {
+	const sql = `
+	UPDATE pull_requests
+	SET
+		status = 'OPEN',
+		closed_at = NULL
+	WHERE pull_request_id = $1
+	AND status <> 'MERGED'
+	RETURNING
+		pull_request_id,
+		pull_request_name,
+		author_id,
+		status,
+		assigned_reviewers,
+		is_draft,
+		merged_at
+	`
+
+	return p.updateStatus(ctx, q, sql, id)
+}
+
+func (p *prRepo) SetDraft(ctx context.Context, q db.Querier, id string, isDraft bool) (*models.PullRequest, error) {
+	const sql = `
+	UPDATE pull_requests
+	SET is_draft = $2
+	WHERE pull_request_id = $1
+	RETURNING
+		pull_request_id,
+		pull_request_name,
+		author_id,
+		status,
+		assigned_reviewers,
+		is_draft,
+		merged_at
+	`
+
+	return p.updateStatus(ctx, q, sql, id, isDraft)
+}
+
+// updateStatus выполняет UPDATE ... RETURNING в транзакции q и различает
+// отсутствующий PR и PR, который уже смержен. UPDATE блокирует строку PR
+// так же, как IsMerged, и держит её до коммита.
+func (p *prRepo) updateStatus(ctx context.Context, q db.Querier, sql, id string, args ...any) (*models.PullRequest, error) {
+	pq, err := db.Pgx(q)
+	if err != nil {
+		return nil, err
+	}
+
+	var pr models.PullRequest
+	err = pq.QueryRow(ctx, sql, append([]any{id}, args...)...).Scan(
+		&pr.PullRequestId,
+		&pr.PullRequestName,
+		&pr.AuthorId,
+		&pr.Status,
+		&pr.AssignedReviewers,
+		&pr.IsDraft,
 		&pr.MergedAt,
 	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(isMerged(ctx, pq, id), prerrors.ErrPRMerged) {
+			return nil, prerrors.ErrPRMerged
+		}
+		return nil, prerrors.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &pr, nil
+}
 
-	return &pr, err
+// IsMerged блокирует строку PR до конца транзакции: пока она открыта,
+// PR не смержат и не переназначат в другой.
+func (p *prRepo) IsMerged(ctx context.Context, q db.Querier, id string) error {
+	pq, err := db.Pgx(q)
+	if err != nil {
+		return err
+	}
+	return isMerged(ctx, pq, id)
 }
 
-func (p *prRepo) IsMerged(ctx context.Context, id string) error {
-	var isMerged bool
-	err := p.Pool.QueryRow(
+func isMerged(ctx context.Context, q db.PgxQuerier, id string) error {
+	var merged bool
+	err := q.QueryRow(
 		ctx,
-		"SELECT merged_at IS NOT NULL FROM pull_requests WHERE pull_request_id = $1",
+		"SELECT merged_at IS NOT NULL FROM pull_requests WHERE pull_request_id = $1 FOR NO KEY UPDATE",
 		id,
-	).Scan(&isMerged)
+	).Scan(&merged)
 
 	if err != nil {
-		return err
+		return db.Classify(err)
 	}
 
-	if isMerged {
+	if merged {
 		return prerrors.ErrPRMerged
 	}
 
 	return nil
 }
 
-func (p *prRepo) FindReplacementReviewers(ctx context.Context, prID string, oldUserId []string) ([]string, error) {
+// FindReplacementReviewers не блокирует кандидатов: вызывающий уже заблокировал
+// их через LockTeammates, до PR.
+func (p *prRepo) FindReplacementReviewers(ctx context.Context, q db.Querier, prID string, oldUserId []string) ([]string, error) {
+	pq, err := db.Pgx(q)
+	if err != nil {
+		return nil, err
+	}
+
 	sql := `
 	SELECT u.user_id
 	FROM users u
@@ -164,9 +352,10 @@ func (p *prRepo) FindReplacementReviewers(ctx context.Context, prID string, oldU
 	AND u.user_id <> pr.author_id
 	AND u.user_id <> ALL($2)
 	AND u.user_id <> ALL(pr.assigned_reviewers)
+	ORDER BY u.user_id
     `
 
-	rows, err := p.Pool.Query(ctx, sql, prID, oldUserId)
+	rows, err := pq.Query(ctx, sql, prID, oldUserId)
 	if err != nil {
 		return nil, err
 	}
@@ -189,14 +378,28 @@ func (p *prRepo) FindReplacementReviewers(ctx context.Context, prID string, oldU
 }
 
 func (p *prRepo) ReassignReviewer(ctx context.Context, q db.Querier, prId, oldUserId, replacedBy string) (*models.PullRequest, error) {
-	var isAssigned bool
-	err := q.QueryRow(
+	pq, err := db.Pgx(q)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		status     string
+		isAssigned bool
+	)
+	err = pq.QueryRow(
 		ctx,
-		"SELECT $1 = ANY(assigned_reviewers) FROM pull_requests WHERE pull_request_id = $2",
+		"SELECT status, $1 = ANY(assigned_reviewers) FROM pull_requests WHERE pull_request_id = $2 FOR NO KEY UPDATE",
 		oldUserId, prId,
-	).Scan(&isAssigned)
+	).Scan(&status, &isAssigned)
 	if err != nil {
-		return nil, prerrors.ErrNotFound
+		return nil, db.Classify(err)
+	}
+	switch status {
+	case "MERGED":
+		return nil, prerrors.ErrPRMerged
+	case "CLOSED":
+		return nil, prerrors.ErrConflict
 	}
 	if !isAssigned {
 		return nil, prerrors.ErrNotAssigned
@@ -212,7 +415,7 @@ func (p *prRepo) ReassignReviewer(ctx context.Context, q db.Querier, prId, oldUs
 	`
 
 	var pr models.PullRequest
-	err = q.QueryRow(
+	err = pq.QueryRow(
 		ctx,
 		sql,
 		oldUserId, replacedBy, prId,
@@ -224,57 +427,34 @@ func (p *prRepo) ReassignReviewer(ctx context.Context, q db.Querier, prId, oldUs
 		&pr.AssignedReviewers,
 	)
 
+	// Нет строки — PR уже не открыт.
 	if err != nil {
+		return nil, db.Classify(err)
+	}
+
+	if err := syncReviewerHistory(ctx, pq, pr.PullRequestId, pr.AssignedReviewers); err != nil {
 		return nil, err
 	}
 
 	return &pr, nil
 }
 
-func (p *prRepo) GetReview(ctx context.Context, userId string) ([]models.PullRequestShort, error) {
-	const sql = `
-	SELECT 
-	pull_request_id, pull_request_name, author_id, status
-	FROM pull_requests
-	WHERE $1 = ANY(assigned_reviewers)
-	`
-
-	prs := make([]models.PullRequestShort, 0, 4)
-	rows, err := p.Pool.Query(
-		ctx,
-		sql,
-		userId,
-	)
+func (p *prRepo) GetListByUsers(ctx context.Context, q db.Querier, ids []string) ([]models.PullRequest, error) {
+	pq, err := db.Pgx(q)
 	if err != nil {
-		return prs, err
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var pr models.PullRequestShort
-		if err := rows.Scan(
-			&pr.PullRequestId,
-			&pr.PullRequestName,
-			&pr.AuthorId,
-			&pr.Status,
-		); err != nil {
-			return prs, err
-		}
-		prs = append(prs, pr)
+		return nil, err
 	}
 
-	return prs, nil
-}
-
-func (p *prRepo) GetListByUsers(ctx context.Context, ids []string) ([]models.PullRequest, error) {
 	const sql = `
 	SELECT pull_request_id, assigned_reviewers, author_id 
 	FROM pull_requests 
 	WHERE status='OPEN' AND assigned_reviewers && $1::text[]
+	ORDER BY pull_request_id
+	FOR NO KEY UPDATE
 	`
 
 	prs := make([]models.PullRequest, 0)
-	rows, err := p.Pool.Query(ctx, sql, ids)
+	rows, err := pq.Query(ctx, sql, ids)
 	if err != nil {
 		return prs, err
 	}
@@ -293,13 +473,342 @@ func (p *prRepo) GetListByUsers(ctx context.Context, ids []string) ([]models.Pul
 	return prs, nil
 }
 
+// existingIds возвращает те из ids, что нашлись запросом sql с параметром $1 = ids.
+func existingIds(ctx context.Context, q db.PgxQuerier, sql string, ids []string) (map[string]struct{}, error) {
+	rows, err := q.Query(ctx, sql, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	found := make(map[string]struct{}, len(ids))
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		found[id] = struct{}{}
+	}
+
+	return found, rows.Err()
+}
+
 func (p *prRepo) ChangeDeactivatedReviewers(ctx context.Context, q db.Querier, prId string, replaced []string) error {
-	_, err := q.Exec(
+	pq, err := db.Pgx(q)
+	if err != nil {
+		return err
+	}
+
+	_, err = pq.Exec(
 		ctx,
 		"UPDATE pull_requests SET assigned_reviewers = $1 WHERE pull_request_id = $2",
 		replaced,
 		prId,
 	)
+	if err != nil {
+		return err
+	}
 
+	return syncReviewerHistory(ctx, pq, prId, replaced)
+}
+
+// syncReviewerHistory приводит текущие назначения в pr_reviewers к списку reviewers:
+// снятые получают unassigned_at, новые добавляются с assigned_at = NOW().
+func syncReviewerHistory(ctx context.Context, q db.PgxQuerier, prId string, reviewers []string) error {
+	_, err := q.Exec(
+		ctx,
+		`UPDATE pr_reviewers SET unassigned_at = NOW()
+		WHERE pull_request_id = $1 AND unassigned_at IS NULL AND user_id <> ALL($2::text[])`,
+		prId, reviewers,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(
+		ctx,
+		`INSERT INTO pr_reviewers (pull_request_id, user_id)
+		SELECT $1, r FROM unnest($2::text[]) AS r
+		ON CONFLICT DO NOTHING`,
+		prId, reviewers,
+	)
 	return err
 }
+
+func (p *prRepo) FindExistingPRs(ctx context.Context, q db.Querier, ids []string) (map[string]struct{}, error) {
+	pq, err := db.Pgx(q)
+	if err != nil {
+		return nil, err
+	}
+
+	return existingIds(ctx, pq, "SELECT pull_request_id FROM pull_requests WHERE pull_request_id = ANY($1)", ids)
+}
+
+func (p *prRepo) ImportPRs(ctx context.Context, q db.Querier, prs []models.PullRequest) (int64, error) {
+	pq, err := db.Pgx(q)
+	if err != nil {
+		return 0, err
+	}
+
+	columns := []string{
+		"pull_request_id", "pull_request_name", "author_id", "status",
+		"assigned_reviewers", "is_draft", "created_at", "merged_at", "closed_at",
+	}
+
+	n, err := pq.CopyFrom(
+		ctx,
+		pgx.Identifier{"pull_requests"},
+		columns,
+		pgx.CopyFromSlice(len(prs), func(i int) ([]any, error) {
+			pr := prs[i]
+			// Время закрытия в модели не хранится — считаем PR закрытым в момент импорта.
+			var closedAt *time.Time
+			if pr.Status == "CLOSED" {
+				now := time.Now()
+				closedAt = &now
+			}
+			return []any{
+				pr.PullRequestId, pr.PullRequestName, pr.AuthorId, pr.Status,
+				pr.AssignedReviewers, pr.IsDraft, pr.CreatedAt, pr.MergedAt, closedAt,
+			}, nil
+		}),
+	)
+	if err != nil {
+		return n, err
+	}
+
+	// Импортированные ревьюверы считаются назначенными при создании PR
+	ids := make([]string, len(prs))
+	for i := range prs {
+		ids[i] = prs[i].PullRequestId
+	}
+	_, err = pq.Exec(
+		ctx,
+		`INSERT INTO pr_reviewers (pull_request_id, user_id, assigned_at)
+		SELECT pr.pull_request_id, r, pr.created_at
+		FROM pull_requests pr
+		CROSS JOIN LATERAL unnest(pr.assigned_reviewers) AS r
+		WHERE pr.pull_request_id = ANY($1)
+		ON CONFLICT DO NOTHING`,
+		ids,
+	)
+	return n, err
+}
+
+// GetPRDetails возвращает PR с автором и ревьюверами одним запросом:
+// по строке на ревьювера в порядке assigned_reviewers.
+func (p *prRepo) GetPRDetails(ctx context.Context, id string) (*models.PullRequestDetails, error) {
+	const sql = `
+	SELECT
+	pr.pull_request_id, pr.pull_request_name, pr.status, pr.is_draft, pr.created_at, pr.merged_at,
+	a.user_id, a.username, a.team_name, a.is_active,
+	ar.user_id, r.username, r.team_name, r.is_active, rv.assigned_at, COALESCE(rv.decision, 'PENDING')
+	FROM pull_requests pr
+	INNER JOIN users a ON a.user_id = pr.author_id
+	LEFT JOIN LATERAL unnest(pr.assigned_reviewers) WITH ORDINALITY AS ar(user_id, ord) ON TRUE
+	LEFT JOIN users r ON r.user_id = ar.user_id
+	LEFT JOIN pr_reviewers rv
+		ON rv.pull_request_id = pr.pull_request_id AND rv.user_id = ar.user_id AND rv.unassigned_at IS NULL
+	WHERE pr.pull_request_id = $1
+	ORDER BY ar.ord
+	`
+
+	rows, err := p.Pool.Query(ctx, sql, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pr *models.PullRequestDetails
+	for rows.Next() {
+		var (
+			row      models.PullRequestDetails
+			userId   *string
+			username *string
+			teamName *string
+			isActive *bool
+			reviewer models.PRReviewer
+		)
+		if err := rows.Scan(
+			&row.PullRequestId, &row.PullRequestName, &row.Status, &row.IsDraft, &row.CreatedAt, &row.MergedAt,
+			&row.Author.UserId, &row.Author.Username, &row.Author.TeamName, &row.Author.IsActive,
+			&userId, &username, &teamName, &isActive, &reviewer.AssignedAt, &reviewer.Decision,
+		); err != nil {
+			return nil, err
+		}
+
+		if pr == nil {
+			row.AssignedReviewers = make([]models.PRReviewer, 0, 2)
+			pr = &row
+		}
+		if userId == nil {
+			continue
+		}
+
+		reviewer.UserId = *userId
+		if username != nil {
+			reviewer.Username = *username
+			reviewer.TeamName = *teamName
+			reviewer.IsActive = *isActive
+		}
+		pr.AssignedReviewers = append(pr.AssignedReviewers, reviewer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if pr == nil {
+		return nil, prerrors.ErrNotFound
+	}
+
+	return pr, nil
+}
+
+// SetDecision записывает решение текущего ревьювера; decided_at хранит время
+// первого решения, чтобы считать время до первого ревью. PR блокируется
+// до коммита, чтобы ревьювера не сняли и PR не смержили, пока записывается
+// решение. Решения по смерженным и закрытым PR не принимаются.
+func (p *prRepo) SetDecision(ctx context.Context, q db.Querier, prId, userId, decision string) error {
+	pq, err := db.Pgx(q)
+	if err != nil {
+		return err
+	}
+
+	var status string
+	err = pq.QueryRow(
+		ctx,
+		"SELECT status FROM pull_requests WHERE pull_request_id = $1 FOR NO KEY UPDATE",
+		prId,
+	).Scan(&status)
+	if err != nil {
+		return db.Classify(err)
+	}
+	switch status {
+	case "MERGED":
+		return prerrors.ErrPRMerged
+	case "CLOSED":
+		return prerrors.ErrConflict
+	}
+
+	const sql = `
+	UPDATE pr_reviewers
+	SET decision = $3, decided_at = COALESCE(decided_at, NOW())
+	WHERE pull_request_id = $1 AND user_id = $2 AND unassigned_at IS NULL
+	`
+
+	tag, err := pq.Exec(ctx, sql, prId, userId, decision)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return prerrors.ErrNotAssigned
+	}
+	return nil
+}
+
+// prSortColumns — допустимые поля сортировки и тип значения курсора.
+var prSortColumns = map[string]string{
+	models.PRSortCreatedAt: "timestamp",
+	models.PRSortMergedAt:  "timestamp",
+	models.PRSortName:      "text",
+	models.PRSortId:        "text",
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (p *prRepo) ListPRs(ctx context.Context, f models.PRListFilter) ([]models.PullRequest, error) {
+	sortCol := f.Sort
+	valueType, ok := prSortColumns[sortCol]
+	if !ok {
+		return nil, prerrors.ErrInvalidQuery
+	}
+
+	where := make([]string, 0, 8)
+	args := make([]any, 0, 12)
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if f.Status != "" {
+		where = append(where, "status = "+arg(f.Status))
+	}
+	if f.AuthorId != "" {
+		where = append(where, "author_id = "+arg(f.AuthorId))
+	}
+	if f.ReviewerId != "" {
+		where = append(where, "assigned_reviewers @> ARRAY["+arg(f.ReviewerId)+"]::text[]")
+	}
+	if f.TeamName != "" {
+		where = append(where, "author_id IN (SELECT user_id FROM users WHERE team_name = "+arg(f.TeamName)+")")
+	}
+	if f.Name != "" {
+		where = append(where, "pull_request_name ILIKE '%' || "+arg(likeEscaper.Replace(f.Name))+" || '%'")
+	}
+	if f.CreatedFrom != nil {
+		where = append(where, "created_at >= "+arg(*f.CreatedFrom))
+	}
+	if f.CreatedTo != nil {
+		where = append(where, "created_at < "+arg(*f.CreatedTo))
+	}
+	if f.MergedFrom != nil {
+		where = append(where, "merged_at >= "+arg(*f.MergedFrom))
+	}
+	if f.MergedTo != nil {
+		where = append(where, "merged_at < "+arg(*f.MergedTo))
+	}
+	// Сортировка по merged_at имеет смысл только для смёрженных PR
+	if sortCol == models.PRSortMergedAt {
+		where = append(where, "merged_at IS NOT NULL")
+	}
+
+	cmp, dir := ">", "ASC"
+	if f.Desc {
+		cmp, dir = "<", "DESC"
+	}
+	order := "pull_request_id " + dir
+	if sortCol != models.PRSortId {
+		order = sortCol + " " + dir + ", " + order
+	}
+
+	if c := f.After; c != nil {
+		if sortCol == models.PRSortId {
+			where = append(where, "pull_request_id "+cmp+" "+arg(c.Id))
+		} else {
+			where = append(where, "("+sortCol+", pull_request_id) "+cmp+
+				" ("+arg(c.Value)+"::"+valueType+", "+arg(c.Id)+")")
+		}
+	}
+
+	sql := `
+	SELECT
+	pull_request_id, pull_request_name, author_id, status, assigned_reviewers, is_draft, created_at, merged_at
+	FROM pull_requests`
+	if len(where) > 0 {
+		sql += "\n\tWHERE " + strings.Join(where, "\n\tAND ")
+	}
+	sql += "\n\tORDER BY " + order
+	if f.Limit > 0 {
+		sql += "\n\tLIMIT " + arg(f.Limit)
+	}
+
+	rows, err := p.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prs := make([]models.PullRequest, 0, max(f.Limit, 4))
+	for rows.Next() {
+		var pr models.PullRequest
+		if err := rows.Scan(
+			&pr.PullRequestId, &pr.PullRequestName,
+			&pr.AuthorId, &pr.Status,
+			&pr.AssignedReviewers, &pr.IsDraft, &pr.CreatedAt, &pr.MergedAt,
+		); err != nil {
+			return nil, err
+		}
+		prs = append(prs, pr)
+	}
+
+	return prs, rows.Err()
+}
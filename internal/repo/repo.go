@@ -2,28 +2,53 @@ package repo
 
 import (
 	"context"
+	"net/http"
+	"time"
 
 	"github.com/andro-kes/avito_test/internal/models"
 	"github.com/andro-kes/avito_test/internal/repo/db"
 )
 
+// PRRepo — методы с q читают в транзакции и держат прочитанное до коммита:
+// IsMerged, ReassignReviewer, GetListByUsers, смена статуса и SetDecision
+// блокируют PR, а
+// FindActiveReviewers — найденных кандидатов. Блокировки берутся в одном
+// порядке: сначала пользователи (UserRepo.LockTeammates или поиск кандидатов),
+// затем PR, поэтому транзакции не ждут друг друга по кругу.
 type PRRepo interface {
 	CreatePR(ctx context.Context, q db.Querier, pr *models.PullRequestShort, reviewers []string) (*models.PullRequest, error)
-	FindActiveReviewers(ctx context.Context, author_id string) ([]string, error)
-	CheckExistingPR(ctx context.Context, id string) (bool, error)
-	MergePR(ctx context.Context, id string) (*models.PullRequest, error)
-	IsMerged(ctx context.Context, id string) error
-	FindReplacementReviewers(ctx context.Context, prID string, oldUserId []string) ([]string, error)
-	GetReview(ctx context.Context, userId string) ([]models.PullRequestShort, error)
+	FindActiveReviewers(ctx context.Context, q db.Querier, authorId string) ([]string, error)
+	FindActiveReviewersInTeam(ctx context.Context, q db.Querier, teamName, authorId string) ([]string, error)
+	CheckExistingPR(ctx context.Context, q db.Querier, id string) (bool, error)
+	MergePR(ctx context.Context, q db.Querier, id string) (*models.PullRequest, error)
+	ClosePR(ctx context.Context, q db.Querier, id string) (*models.PullRequest, error)
+	ReopenPR(ctx context.Context, q db.Querier, id string) (*models.PullRequest, error)
+	SetDraft(ctx context.Context, q db.Querier, id string, isDraft bool) (*models.PullRequest, error)
+	IsMerged(ctx context.Context, q db.Querier, id string) error
+	FindReplacementReviewers(ctx context.Context, q db.Querier, prID string, oldUserId []string) ([]string, error)
 	ReassignReviewer(ctx context.Context, q db.Querier, prId, oldUserId, replacedBy string) (*models.PullRequest, error)
-	GetListByUsers(ctx context.Context, ids []string) ([]models.PullRequest, error)
+	GetListByUsers(ctx context.Context, q db.Querier, ids []string) ([]models.PullRequest, error)
 	ChangeDeactivatedReviewers(ctx context.Context, q db.Querier, prId string, replaced []string) error
+	FindExistingPRs(ctx context.Context, q db.Querier, ids []string) (map[string]struct{}, error)
+	ImportPRs(ctx context.Context, q db.Querier, prs []models.PullRequest) (int64, error)
+	ListPRs(ctx context.Context, f models.PRListFilter) ([]models.PullRequest, error)
+	GetPRDetails(ctx context.Context, id string) (*models.PullRequestDetails, error)
+	SetDecision(ctx context.Context, q db.Querier, prId, userId, decision string) error
+}
+
+type StatsRepo interface {
+	ReviewerStats(ctx context.Context, w models.StatsWindow, teamName string) ([]models.ReviewerStats, error)
+	TeamReviewStats(ctx context.Context, w models.StatsWindow, teamName string) ([]models.TeamReviewStats, error)
+	PRThroughput(ctx context.Context, w models.StatsWindow, tz, teamName string) ([]models.PRThroughput, error)
+	OpenPRsByTeam(ctx context.Context) (map[string]int, error)
 }
 
 type TeamRepo interface {
 	CheckUnique(ctx context.Context, name string) error
 	CreateTeam(ctx context.Context, q db.Querier, name string) error
 	GetTeam(ctx context.Context, name string) (*models.Team, error)
+	EnsureTeam(ctx context.Context, q db.Querier, name string) (bool, error)
+	ListTeams(ctx context.Context, q db.Querier) ([]models.Team, error)
 }
 
 type UserRepo interface {
@@ -32,4 +57,23 @@ type UserRepo interface {
 	CountReview(ctx context.Context, userId string) (int, error)
 	UpsertUser(ctx context.Context, q db.Querier, name string, m models.TeamMember) error
 	DeactivateUsers(ctx context.Context, q db.Querier, userIds []string) error
+	FindExistingUsers(ctx context.Context, q db.Querier, userIds []string) (map[string]struct{}, error)
+	LockTeammates(ctx context.Context, q db.Querier, userIds []string) error
+}
+
+type ForgeRepo interface {
+	ResolveUser(ctx context.Context, forge, login string) (string, error)
+	UpsertIdentity(ctx context.Context, identity models.ForgeIdentity) error
+	ClaimDelivery(ctx context.Context, q db.Querier, forge, deliveryId string) (bool, error)
+	LinkPR(ctx context.Context, q db.Querier, link models.ForgePullRequest) error
+	GetPRLink(ctx context.Context, prId string) (*models.ForgePullRequest, error)
+	ForgeLogins(ctx context.Context, forge string, userIds []string) (map[string]string, error)
+}
+
+type IdempotencyRepo interface {
+	Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (bool, error)
+	Get(ctx context.Context, key string) (*models.IdempotencyRecord, error)
+	Complete(ctx context.Context, key string, statusCode int, header http.Header, body []byte) error
+	Release(ctx context.Context, key string) error
+	PurgeExpired(ctx context.Context) (int64, error)
 }
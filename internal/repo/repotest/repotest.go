@@ -0,0 +1,993 @@
+// Package repotest — общий набор проверок репозиториев. Каждое хранилище
+// прогоняет его у себя, чтобы сервисы вели себя одинаково на любом из них.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/repo"
+	"github.com/andro-kes/avito_test/internal/repo/db"
+)
+
+// Run прогоняет все проверки; newStorage вызывается для каждой и должен
+// возвращать пустое хранилище.
+func Run(t *testing.T, newStorage func(t *testing.T) *repo.Storage) {
+	cases := []struct {
+		name string
+		fn   func(t *testing.T, st *repo.Storage)
+	}{
+		{"Teams", testTeams},
+		{"Users", testUsers},
+		{"Transactions", testTransactions},
+		{"ConcurrentTransactions", testConcurrentTransactions},
+		{"Locking", testLocking},
+		{"CreatePR", testCreatePR},
+		{"PRStatus", testPRStatus},
+		{"Reassign", testReassign},
+		{"DeactivatedReviewers", testDeactivatedReviewers},
+		{"Decisions", testDecisions},
+		{"ListPRs", testListPRs},
+		{"ImportPRs", testImportPRs},
+		{"Stats", testStats},
+		{"Forge", testForge},
+		{"Idempotency", testIdempotency},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.fn(t, newStorage(t))
+		})
+	}
+}
+
+var errRollback = errors.New("rollback")
+
+func inTx(t *testing.T, st *repo.Storage, fn func(ctx context.Context, q db.Querier) error) error {
+	t.Helper()
+	return st.Tx.RunInTx(context.Background(), fn)
+}
+
+// changePR меняет PR в отдельной транзакции.
+func changePR(t *testing.T, st *repo.Storage, fn func(ctx context.Context, q db.Querier) (*models.PullRequest, error)) (*models.PullRequest, error) {
+	t.Helper()
+	var pr *models.PullRequest
+	err := inTx(t, st, func(ctx context.Context, q db.Querier) error {
+		var err error
+		pr, err = fn(ctx, q)
+		return err
+	})
+	return pr, err
+}
+
+func mergePR(t *testing.T, st *repo.Storage, id string) (*models.PullRequest, error) {
+	t.Helper()
+	return changePR(t, st, func(ctx context.Context, q db.Querier) (*models.PullRequest, error) {
+		return st.PRs.MergePR(ctx, q, id)
+	})
+}
+
+func setDecision(t *testing.T, st *repo.Storage, prId, userId, decision string) error {
+	t.Helper()
+	return inTx(t, st, func(ctx context.Context, q db.Querier) error {
+		return st.PRs.SetDecision(ctx, q, prId, userId, decision)
+	})
+}
+
+func member(id, name string, active bool) models.TeamMember {
+	return models.TeamMember{UserID: id, Username: name, IsActive: active}
+}
+
+func seedTeam(t *testing.T, st *repo.Storage, name string, members ...models.TeamMember) {
+	t.Helper()
+	require.NoError(t, inTx(t, st, func(ctx context.Context, q db.Querier) error {
+		if err := st.Teams.CreateTeam(ctx, q, name); err != nil {
+			return err
+		}
+		for _, m := range members {
+			if err := st.Users.UpsertUser(ctx, q, name, m); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+func createPR(t *testing.T, st *repo.Storage, id, name, author string, reviewers ...string) *models.PullRequest {
+	t.Helper()
+	var pr *models.PullRequest
+	require.NoError(t, inTx(t, st, func(ctx context.Context, q db.Querier) error {
+		var err error
+		pr, err = st.PRs.CreatePR(ctx, q, &models.PullRequestShort{
+			PullRequestId:   id,
+			PullRequestName: name,
+			AuthorId:        author,
+		}, reviewers)
+		return err
+	}))
+	return pr
+}
+
+func reviewerIds(d *models.PullRequestDetails) []string {
+	ids := make([]string, 0, len(d.AssignedReviewers))
+	for _, r := range d.AssignedReviewers {
+		ids = append(ids, r.UserId)
+	}
+	return ids
+}
+
+func testTeams(t *testing.T, st *repo.Storage) {
+	ctx := context.Background()
+
+	require.NoError(t, st.Teams.CheckUnique(ctx, "backend"))
+	seedTeam(t, st, "backend", member("u2", "Bob", true), member("u1", "Alice", false))
+	require.ErrorIs(t, st.Teams.CheckUnique(ctx, "backend"), prerrors.ErrTeamExists)
+
+	err := inTx(t, st, func(ctx context.Context, q db.Querier) error {
+		return st.Teams.CreateTeam(ctx, q, "backend")
+	})
+	require.ErrorIs(t, err, prerrors.ErrTeamExists)
+
+	team, err := st.Teams.GetTeam(ctx, "backend")
+	require.NoError(t, err)
+	require.Equal(t, "backend", team.TeamName)
+	require.Equal(t, []models.TeamMember{member("u1", "Alice", false), member("u2", "Bob", true)}, team.Members)
+
+	_, err = st.Teams.GetTeam(ctx, "missing")
+	require.ErrorIs(t, err, prerrors.ErrNotFound)
+
+	var teams []models.Team
+	require.NoError(t, inTx(t, st, func(ctx context.Context, q db.Querier) error {
+		created, err := st.Teams.EnsureTeam(ctx, q, "backend")
+		require.NoError(t, err)
+		require.False(t, created)
+
+		created, err = st.Teams.EnsureTeam(ctx, q, "empty")
+		require.NoError(t, err)
+		require.True(t, created)
+
+		teams, err = st.Teams.ListTeams(ctx, q)
+		return err
+	}))
+	require.Len(t, teams, 2)
+	require.Equal(t, "backend", teams[0].TeamName)
+	require.Len(t, teams[0].Members, 2)
+	require.Equal(t, models.Team{TeamName: "empty", Members: []models.TeamMember{}}, teams[1])
+}
+
+func testUsers(t *testing.T, st *repo.Storage) {
+	ctx := context.Background()
+	seedTeam(t, st, "backend", member("u1", "Alice", true), member("u2", "Bob", true))
+	seedTeam(t, st, "frontend", member("u3", "Carol", true))
+
+	user, err := st.Users.GetUser(ctx, "u1")
+	require.NoError(t, err)
+	require.Equal(t, &models.User{UserId: "u1", Username: "Alice", TeamName: "backend", IsActive: true}, user)
+
+	_, err = st.Users.GetUser(ctx, "ghost")
+	require.ErrorIs(t, err, prerrors.ErrNotFound)
+
+	require.NoError(t, inTx(t, st, func(ctx context.Context, q db.Querier) error {
+		return st.Users.UpsertUser(ctx, q, "frontend", member("u1", "Alice Smith", false))
+	}))
+	user, err = st.Users.GetUser(ctx, "u1")
+	require.NoError(t, err)
+	require.Equal(t, &models.User{UserId: "u1", Username: "Alice Smith", TeamName: "frontend", IsActive: false}, user)
+
+	err = inTx(t, st, func(ctx context.Context, q db.Querier) error {
+		return st.Users.UpsertUser(ctx, q, "missing", member("u9", "Nobody", true))
+	})
+	require.ErrorIs(t, err, prerrors.ErrNotFound)
+
+	err = inTx(t, st, func(ctx context.Context, q db.Querier) error {
+		return st.Users.SetIsActive(ctx, q, "ghost", false)
+	})
+	require.ErrorIs(t, err, prerrors.ErrNotFound)
+
+	var existing map[string]struct{}
+	require.NoError(t, inTx(t, st, func(ctx context.Context, q db.Querier) error {
+		if err := st.Users.SetIsActive(ctx, q, "u1", true); err != nil {
+			return err
+		}
+		if err := st.Users.DeactivateUsers(ctx, q, []string{"u2", "u3", "ghost"}); err != nil {
+			return err
+		}
+		var err error
+		existing, err = st.Users.FindExistingUsers(ctx, q, []string{"u1", "ghost"})
+		return err
+	}))
+	require.Equal(t, map[string]struct{}{"u1": {}}, existing)
+
+	for id, active := range map[string]bool{"u1": true, "u2": false, "u3": false} {
+		user, err := st.Users.GetUser(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, active, user.IsActive, id)
+	}
+}
+
+func testTransactions(t *testing.T, st *repo.Storage) {
+	ctx := context.Background()
+
+	hookCalled := false
+	err := inTx(t, st, func(ctx context.Context, q db.Querier) error {
+		db.AfterCommit(ctx, func() { hookCalled = true })
+		if err := st.Teams.CreateTeam(ctx, q, "rolled-back"); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+	require.False(t, hookCalled)
+	require.NoError(t, st.Teams.CheckUnique(ctx, "rolled-back"))
+
+	require.NoError(t, inTx(t, st, func(ctx context.Context, q db.Querier) error {
+		db.AfterCommit(ctx, func() { hookCalled = true })
+		if err := st.Teams.CreateTeam(ctx, q, "committed"); err != nil {
+			return err
+		}
+
+		// До коммита изменения не видны вне транзакции
+		_, err := st.Teams.GetTeam(ctx, "committed")
+		require.ErrorIs(t, err, prerrors.ErrNotFound)
+		require.False(t, hookCalled)
+		return nil
+	}))
+	require.True(t, hookCalled)
+
+	team, err := st.Teams.GetTeam(ctx, "committed")
+	require.NoError(t, err)
+	require.Equal(t, "committed", team.TeamName)
+}
+
+func testConcurrentTransactions(t *testing.T, st *repo.Storage) {
+	const workers = 8
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		created int
+		errs    []error
+	)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := inTx(t, st, func(ctx context.Context, q db.Querier) error {
+				return st.Teams.CreateTeam(ctx, q, "contended")
+			})
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				created++
+				return
+			}
+			errs = append(errs, err)
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, 1, created)
+	for _, err := range errs {
+		require.ErrorIs(t, err, prerrors.ErrTeamExists)
+	}
+}
+
+// lockWait — сколько ждать, чтобы убедиться, что изменение заблокировано.
+const lockWait = 200 * time.Millisecond
+
+// whileLocked выполняет lock в транзакции и, не завершая её, запускает
+// concurrent. Проверяет, что concurrent ждёт, пока транзакция не выполнит
+// then и не зафиксируется, и возвращает ошибку concurrent.
+func whileLocked(t *testing.T, st *repo.Storage, lock, then func(ctx context.Context, q db.Querier) error, concurrent func() error) error {
+	t.Helper()
+
+	var lockErr error
+	locked := make(chan struct{})
+	release := make(chan struct{})
+	first := make(chan error, 1)
+	go func() {
+		first <- st.Tx.RunInTx(context.Background(), func(ctx context.Context, q db.Querier) error {
+			lockErr = lock(ctx, q)
+			close(locked)
+			if lockErr != nil {
+				return lockErr
+			}
+			<-release
+			return then(ctx, q)
+		})
+	}()
+
+	<-locked
+	require.NoError(t, lockErr)
+
+	second := make(chan error, 1)
+	go func() {
+		second <- concurrent()
+	}()
+
+	select {
+	case err := <-second:
+		close(release)
+		<-first
+		t.Fatalf("concurrent change did not wait for the lock: %v", err)
+	case <-time.After(lockWait):
+	}
+
+	close(release)
+	require.NoError(t, <-first)
+	return <-second
+}
+
+// deactivate деактивирует ids так же, как сервис: в той же транзакции ищет
+// их открытые PR и складывает найденные в found.
+func deactivate(st *repo.Storage, found *[]models.PullRequest, ids ...string) func() error {
+	return func() error {
+		return st.Tx.RunInTx(context.Background(), func(ctx context.Context, q db.Querier) error {
+			if err := st.Users.DeactivateUsers(ctx, q, ids); err != nil {
+				return err
+			}
+			prs, err := st.PRs.GetListByUsers(ctx, q, ids)
+			*found = prs
+			return err
+		})
+	}
+}
+
+// testLocking проверяет, что прочитанное в транзакции остаётся верным до
+// коммита: конкурирующее изменение ждёт его и видит результат.
+func testLocking(t *testing.T, st *repo.Storage) {
+	seedTeam(t, st, "backend",
+		member("u1", "Alice", true), member("u2", "Bob", true),
+		member("u3", "Carol", true), member("u4", "Dave", true))
+	createPR(t, st, "pr-1", "add search", "u1", "u2")
+
+	// Кандидата на замену не деактивируют, пока переназначение не зафиксировано;
+	// деактивация находит PR, куда он уже назначен
+	var (
+		candidates []string
+		found      []models.PullRequest
+	)
+	err := whileLocked(t, st,
+		func(ctx context.Context, q db.Querier) error {
+			if err := st.Users.LockTeammates(ctx, q, []string{"u2"}); err != nil {
+				return err
+			}
+			var err error
+			candidates, err = st.PRs.FindReplacementReviewers(ctx, q, "pr-1", []string{"u2"})
+			return err
+		},
+		func(ctx context.Context, q db.Querier) error {
+			_, err := st.PRs.ReassignReviewer(ctx, q, "pr-1", "u2", "u3")
+			return err
+		},
+		deactivate(st, &found, "u3"),
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"u3", "u4"}, candidates)
+	require.Len(t, found, 1)
+	require.Equal(t, "pr-1", found[0].PullRequestId)
+	require.Equal(t, []string{"u3"}, found[0].AssignedReviewers)
+
+	// То же для кандидатов в ревьюверы нового PR
+	err = whileLocked(t, st,
+		func(ctx context.Context, q db.Querier) error {
+			var err error
+			candidates, err = st.PRs.FindActiveReviewers(ctx, q, "u1")
+			return err
+		},
+		func(ctx context.Context, q db.Querier) error {
+			_, err := st.PRs.CreatePR(ctx, q, &models.PullRequestShort{
+				PullRequestId: "pr-2", PullRequestName: "fix typo", AuthorId: "u1",
+			}, []string{"u4"})
+			return err
+		},
+		deactivate(st, &found, "u4"),
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"u2", "u4"}, candidates)
+	require.Len(t, found, 1)
+	require.Equal(t, "pr-2", found[0].PullRequestId)
+
+	// PR, проверенный IsMerged, не смержат до конца переназначения
+	var merged *models.PullRequest
+	err = whileLocked(t, st,
+		func(ctx context.Context, q db.Querier) error {
+			return st.PRs.IsMerged(ctx, q, "pr-2")
+		},
+		func(ctx context.Context, q db.Querier) error {
+			_, err := st.PRs.ReassignReviewer(ctx, q, "pr-2", "u4", "u2")
+			return err
+		},
+		func() error {
+			var err error
+			merged, err = mergePR(t, st, "pr-2")
+			return err
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, "MERGED", merged.Status)
+	require.Equal(t, []string{"u2"}, merged.AssignedReviewers)
+
+	// Решение снятого ревьювера ждёт конца переназначения и отклоняется
+	err = whileLocked(t, st,
+		func(ctx context.Context, q db.Querier) error {
+			return st.PRs.IsMerged(ctx, q, "pr-1")
+		},
+		func(ctx context.Context, q db.Querier) error {
+			_, err := st.PRs.ReassignReviewer(ctx, q, "pr-1", "u3", "u2")
+			return err
+		},
+		func() error {
+			return setDecision(t, st, "pr-1", "u3", models.ReviewApproved)
+		},
+	)
+	require.ErrorIs(t, err, prerrors.ErrNotAssigned)
+}
+
+func testCreatePR(t *testing.T, st *repo.Storage) {
+	seedTeam(t, st, "backend",
+		member("u1", "Alice", true), member("u2", "Bob", true),
+		member("u3", "Carol", true), member("u4", "Dave", false))
+	seedTeam(t, st, "frontend", member("u5", "Eve", true))
+
+	require.NoError(t, inTx(t, st, func(ctx context.Context, q db.Querier) error {
+		active, err := st.PRs.FindActiveReviewers(ctx, q, "u1")
+		require.NoError(t, err)
+		require.Equal(t, []string{"u2", "u3"}, active)
+
+		active, err = st.PRs.FindActiveReviewersInTeam(ctx, q, "frontend", "u1")
+		require.NoError(t, err)
+		require.Equal(t, []string{"u5"}, active)
+
+		active, err = st.PRs.FindActiveReviewers(ctx, q, "ghost")
+		require.NoError(t, err)
+		require.Empty(t, active)
+		return nil
+	}))
+
+	pr := createPR(t, st, "pr-1", "add search", "u1", "u2", "u3")
+	require.Equal(t, "pr-1", pr.PullRequestId)
+	require.Equal(t, "add search", pr.PullRequestName)
+	require.Equal(t, "u1", pr.AuthorId)
+	require.Equal(t, "OPEN", pr.Status)
+	require.Equal(t, []string{"u2", "u3"}, pr.AssignedReviewers)
+	require.False(t, pr.IsDraft)
+	require.NotNil(t, pr.CreatedAt)
+	require.Nil(t, pr.MergedAt)
+
+	noReviewers := createPR(t, st, "pr-2", "fix typo", "u5")
+	require.Equal(t, []string{}, noReviewers.AssignedReviewers)
+
+	require.NoError(t, inTx(t, st, func(ctx context.Context, q db.Querier) error {
+		exists, err := st.PRs.CheckExistingPR(ctx, q, "pr-1")
+		require.NoError(t, err)
+		require.True(t, exists)
+		exists, err = st.PRs.CheckExistingPR(ctx, q, "ghost")
+		require.NoError(t, err)
+		require.False(t, exists)
+		return nil
+	}))
+
+	err := inTx(t, st, func(ctx context.Context, q db.Querier) error {
+		_, err := st.PRs.CreatePR(ctx, q, &models.PullRequestShort{
+			PullRequestId: "pr-1", PullRequestName: "again", AuthorId: "u1",
+		}, nil)
+		return err
+	})
+	require.ErrorIs(t, err, prerrors.ErrPRExists)
+
+	err = inTx(t, st, func(ctx context.Context, q db.Querier) error {
+		_, err := st.PRs.CreatePR(ctx, q, &models.PullRequestShort{
+			PullRequestId: "pr-3", PullRequestName: "orphan", AuthorId: "ghost",
+		}, nil)
+		return err
+	})
+	require.ErrorIs(t, err, prerrors.ErrNotFound)
+}
+
+func testPRStatus(t *testing.T, st *repo.Storage) {
+	seedTeam(t, st, "backend", member("u1", "Alice", true), member("u2", "Bob", true))
+	createPR(t, st, "pr-1", "add search", "u1", "u2")
+	createPR(t, st, "pr-2", "fix typo", "u1", "u2")
+
+	pr, err := changePR(t, st, func(ctx context.Context, q db.Querier) (*models.PullRequest, error) {
+		return st.PRs.SetDraft(ctx, q, "pr-1", true)
+	})
+	require.NoError(t, err)
+	require.True(t, pr.IsDraft)
+
+	pr, err = changePR(t, st, func(ctx context.Context, q db.Querier) (*models.PullRequest, error) {
+		return st.PRs.ClosePR(ctx, q, "pr-1")
+	})
+	require.NoError(t, err)
+	require.Equal(t, "CLOSED", pr.Status)
+
+	pr, err = changePR(t, st, func(ctx context.Context, q db.Querier) (*models.PullRequest, error) {
+		return st.PRs.ReopenPR(ctx, q, "pr-1")
+	})
+	require.NoError(t, err)
+	require.Equal(t, "OPEN", pr.Status)
+	require.True(t, pr.IsDraft)
+
+	merged, err := mergePR(t, st, "pr-1")
+	require.NoError(t, err)
+	require.Equal(t, "MERGED", merged.Status)
+	require.Equal(t, []string{"u2"}, merged.AssignedReviewers)
+	require.NotNil(t, merged.MergedAt)
+
+	again, err := mergePR(t, st, "pr-1")
+	require.NoError(t, err)
+	require.True(t, merged.MergedAt.Equal(*again.MergedAt), "merged_at must not change")
+
+	require.NoError(t, inTx(t, st, func(ctx context.Context, q db.Querier) error {
+		require.ErrorIs(t, st.PRs.IsMerged(ctx, q, "pr-1"), prerrors.ErrPRMerged)
+		require.NoError(t, st.PRs.IsMerged(ctx, q, "pr-2"))
+		require.ErrorIs(t, st.PRs.IsMerged(ctx, q, "ghost"), prerrors.ErrNotFound)
+		return nil
+	}))
+
+	_, err = changePR(t, st, func(ctx context.Context, q db.Querier) (*models.PullRequest, error) {
+		return st.PRs.ClosePR(ctx, q, "pr-1")
+	})
+	require.ErrorIs(t, err, prerrors.ErrPRMerged)
+	_, err = changePR(t, st, func(ctx context.Context, q db.Querier) (*models.PullRequest, error) {
+		return st.PRs.ReopenPR(ctx, q, "pr-1")
+	})
+	require.ErrorIs(t, err, prerrors.ErrPRMerged)
+
+	_, err = mergePR(t, st, "ghost")
+	require.ErrorIs(t, err, prerrors.ErrNotFound)
+	_, err = changePR(t, st, func(ctx context.Context, q db.Querier) (*models.PullRequest, error) {
+		return st.PRs.ClosePR(ctx, q, "ghost")
+	})
+	require.ErrorIs(t, err, prerrors.ErrNotFound)
+	_, err = changePR(t, st, func(ctx context.Context, q db.Querier) (*models.PullRequest, error) {
+		return st.PRs.ReopenPR(ctx, q, "ghost")
+	})
+	require.ErrorIs(t, err, prerrors.ErrNotFound)
+	_, err = changePR(t, st, func(ctx context.Context, q db.Querier) (*models.PullRequest, error) {
+		return st.PRs.SetDraft(ctx, q, "ghost", true)
+	})
+	require.ErrorIs(t, err, prerrors.ErrNotFound)
+}
+
+func testReassign(t *testing.T, st *repo.Storage) {
+	ctx := context.Background()
+	seedTeam(t, st, "backend",
+		member("u1", "Alice", true), member("u2", "Bob", true),
+		member("u3", "Carol", true), member("u4", "Dave", true), member("u5", "Frank", false))
+	seedTeam(t, st, "frontend", member("u6", "Eve", true))
+	createPR(t, st, "pr-1", "add search", "u1", "u2", "u3")
+
+	var pr *models.PullRequest
+	require.NoError(t, inTx(t, st, func(ctx context.Context, q db.Querier) error {
+		replacement, err := st.PRs.FindReplacementReviewers(ctx, q, "pr-1", []string{"u2"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"u4"}, replacement)
+
+		pr, err = st.PRs.ReassignReviewer(ctx, q, "pr-1", "u2", "u4")
+		return err
+	}))
+	require.Equal(t, []string{"u4", "u3"}, pr.AssignedReviewers)
+
+	err := inTx(t, st, func(ctx context.Context, q db.Querier) error {
+		_, err := st.PRs.ReassignReviewer(ctx, q, "pr-1", "u2", "u3")
+		return err
+	})
+	require.ErrorIs(t, err, prerrors.ErrNotAssigned)
+
+	err = inTx(t, st, func(ctx context.Context, q db.Querier) error {
+		_, err := st.PRs.ReassignReviewer(ctx, q, "ghost", "u2", "u4")
+		return err
+	})
+	require.ErrorIs(t, err, prerrors.ErrNotFound)
+
+	details, err := st.PRs.GetPRDetails(ctx, "pr-1")
+	require.NoError(t, err)
+	require.Equal(t, models.User{UserId: "u1", Username: "Alice", TeamName: "backend", IsActive: true}, details.Author)
+	require.Equal(t, []string{"u4", "u3"}, reviewerIds(details))
+	for _, r := range details.AssignedReviewers {
+		require.Equal(t, "backend", r.TeamName)
+		require.NotEmpty(t, r.Username)
+		require.NotNil(t, r.AssignedAt)
+		require.Equal(t, models.ReviewPending, r.Decision)
+	}
+
+	_, err = st.PRs.GetPRDetails(ctx, "ghost")
+	require.ErrorIs(t, err, prerrors.ErrNotFound)
+
+	cnt, err := st.Users.CountReview(ctx, "u4")
+	require.NoError(t, err)
+	require.Equal(t, 1, cnt)
+	cnt, err = st.Users.CountReview(ctx, "u2")
+	require.NoError(t, err)
+	require.Equal(t, 0, cnt)
+	_, err = st.Users.CountReview(ctx, "ghost")
+	require.ErrorIs(t, err, prerrors.ErrNotFound)
+
+	// Закрытый и смерженный PR — конфликт, а не NOT_FOUND
+	createPR(t, st, "pr-closed", "closed", "u1", "u2")
+	_, err = changePR(t, st, func(ctx context.Context, q db.Querier) (*models.PullRequest, error) {
+		return st.PRs.ClosePR(ctx, q, "pr-closed")
+	})
+	require.NoError(t, err)
+	createPR(t, st, "pr-merged", "merged", "u1", "u2")
+	_, err = mergePR(t, st, "pr-merged")
+	require.NoError(t, err)
+	for id, want := range map[string]error{"pr-closed": prerrors.ErrConflict, "pr-merged": prerrors.ErrPRMerged} {
+		err = inTx(t, st, func(ctx context.Context, q db.Querier) error {
+			_, err := st.PRs.ReassignReviewer(ctx, q, id, "u2", "u4")
+			return err
+		})
+		require.ErrorIs(t, err, want, id)
+	}
+}
+
+func testDeactivatedReviewers(t *testing.T, st *repo.Storage) {
+	ctx := context.Background()
+	seedTeam(t, st, "backend",
+		member("u1", "Alice", true), member("u2", "Bob", true),
+		member("u3", "Carol", true), member("u4", "Dave", true))
+	createPR(t, st, "pr-1", "add search", "u1", "u2", "u3")
+	createPR(t, st, "pr-2", "fix typo", "u1", "u2")
+	createPR(t, st, "pr-3", "refactor", "u1", "u3")
+	_, err := mergePR(t, st, "pr-2")
+	require.NoError(t, err)
+
+	require.NoError(t, inTx(t, st, func(ctx context.Context, q db.Querier) error {
+		prs, err := st.PRs.GetListByUsers(ctx, q, []string{"u2"})
+		require.NoError(t, err)
+		require.Len(t, prs, 1)
+		require.Equal(t, "pr-1", prs[0].PullRequestId)
+		require.Equal(t, "u1", prs[0].AuthorId)
+		require.Equal(t, []string{"u2", "u3"}, prs[0].AssignedReviewers)
+
+		return st.PRs.ChangeDeactivatedReviewers(ctx, q, "pr-1", []string{"u4", "u3"})
+	}))
+
+	details, err := st.PRs.GetPRDetails(ctx, "pr-1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"u4", "u3"}, reviewerIds(details))
+
+	// Смёрженный PR не трогается
+	details, err = st.PRs.GetPRDetails(ctx, "pr-2")
+	require.NoError(t, err)
+	require.Equal(t, []string{"u2"}, reviewerIds(details))
+}
+
+func testDecisions(t *testing.T, st *repo.Storage) {
+	ctx := context.Background()
+	seedTeam(t, st, "backend",
+		member("u1", "Alice", true), member("u2", "Bob", true),
+		member("u3", "Carol", true), member("u4", "Dave", true))
+	createPR(t, st, "pr-1", "add search", "u1", "u2", "u3")
+
+	require.NoError(t, setDecision(t, st, "pr-1", "u2", models.ReviewApproved))
+	require.ErrorIs(t, setDecision(t, st, "pr-1", "u4", models.ReviewApproved), prerrors.ErrNotAssigned)
+	require.ErrorIs(t, setDecision(t, st, "ghost", "u2", models.ReviewApproved), prerrors.ErrNotFound)
+
+	details, err := st.PRs.GetPRDetails(ctx, "pr-1")
+	require.NoError(t, err)
+	require.Equal(t, models.ReviewApproved, details.AssignedReviewers[0].Decision)
+	require.Equal(t, models.ReviewPending, details.AssignedReviewers[1].Decision)
+
+	// Новый ревьювер начинает с чистого решения
+	require.NoError(t, inTx(t, st, func(ctx context.Context, q db.Querier) error {
+		_, err := st.PRs.ReassignReviewer(ctx, q, "pr-1", "u2", "u4")
+		return err
+	}))
+	details, err = st.PRs.GetPRDetails(ctx, "pr-1")
+	require.NoError(t, err)
+	require.Equal(t, "u4", details.AssignedReviewers[0].UserId)
+	require.Equal(t, models.ReviewPending, details.AssignedReviewers[0].Decision)
+	require.ErrorIs(t, setDecision(t, st, "pr-1", "u2", models.ReviewApproved), prerrors.ErrNotAssigned)
+
+	// Решения по закрытым и смерженным PR не принимаются
+	_, err = changePR(t, st, func(ctx context.Context, q db.Querier) (*models.PullRequest, error) {
+		return st.PRs.ClosePR(ctx, q, "pr-1")
+	})
+	require.NoError(t, err)
+	require.ErrorIs(t, setDecision(t, st, "pr-1", "u4", models.ReviewApproved), prerrors.ErrConflict)
+	_, err = changePR(t, st, func(ctx context.Context, q db.Querier) (*models.PullRequest, error) {
+		return st.PRs.ReopenPR(ctx, q, "pr-1")
+	})
+	require.NoError(t, err)
+	require.NoError(t, setDecision(t, st, "pr-1", "u4", models.ReviewChangesRequested))
+
+	_, err = mergePR(t, st, "pr-1")
+	require.NoError(t, err)
+	require.ErrorIs(t, setDecision(t, st, "pr-1", "u4", models.ReviewApproved), prerrors.ErrPRMerged)
+}
+
+func testListPRs(t *testing.T, st *repo.Storage) {
+	ctx := context.Background()
+	seedTeam(t, st, "backend", member("u1", "Alice", true), member("u2", "Bob", true), member("u3", "Carol", true))
+	seedTeam(t, st, "frontend", member("u4", "Dave", true), member("u5", "Eve", true))
+	createPR(t, st, "pr-a", "fix login", "u1", "u2")
+	createPR(t, st, "pr-b", "add search", "u1", "u3")
+	createPR(t, st, "pr-c", "login page", "u4", "u5")
+	createPR(t, st, "pr-d", "refactor", "u4", "u5")
+	_, err := mergePR(t, st, "pr-d")
+	require.NoError(t, err)
+
+	list := func(f models.PRListFilter) []string {
+		t.Helper()
+		if f.Sort == "" {
+			f.Sort = models.PRSortId
+		}
+		prs, err := st.PRs.ListPRs(ctx, f)
+		require.NoError(t, err)
+		ids := make([]string, 0, len(prs))
+		for _, pr := range prs {
+			ids = append(ids, pr.PullRequestId)
+		}
+		return ids
+	}
+
+	require.Equal(t, []string{"pr-a", "pr-b", "pr-c", "pr-d"}, list(models.PRListFilter{}))
+	require.Equal(t, []string{"pr-a", "pr-b", "pr-c"}, list(models.PRListFilter{Status: "OPEN"}))
+	require.Equal(t, []string{"pr-c", "pr-d"}, list(models.PRListFilter{AuthorId: "u4"}))
+	require.Equal(t, []string{"pr-c", "pr-d"}, list(models.PRListFilter{ReviewerId: "u5"}))
+	require.Equal(t, []string{"pr-a", "pr-b"}, list(models.PRListFilter{TeamName: "backend"}))
+	require.Equal(t, []string{"pr-a", "pr-c"}, list(models.PRListFilter{Name: "LOGIN"}))
+	require.Empty(t, list(models.PRListFilter{Name: "%"}))
+	require.Equal(t, []string{"pr-d"}, list(models.PRListFilter{Sort: models.PRSortMergedAt}))
+
+	future := time.Now().Add(time.Hour)
+	require.Empty(t, list(models.PRListFilter{CreatedFrom: &future}))
+	require.Len(t, list(models.PRListFilter{CreatedTo: &future}), 4)
+
+	require.Equal(t, []string{"pr-b", "pr-a", "pr-c", "pr-d"}, list(models.PRListFilter{Sort: models.PRSortName}))
+	require.Equal(t, []string{"pr-c", "pr-d"}, list(models.PRListFilter{
+		Sort:  models.PRSortName,
+		After: &models.PRCursor{Value: "fix login", Id: "pr-a"},
+	}))
+
+	require.Equal(t, []string{"pr-a", "pr-b"}, list(models.PRListFilter{Limit: 2}))
+	require.Equal(t, []string{"pr-c", "pr-d"}, list(models.PRListFilter{After: &models.PRCursor{Id: "pr-b"}}))
+	require.Equal(t, []string{"pr-d", "pr-c", "pr-b", "pr-a"}, list(models.PRListFilter{Desc: true}))
+	require.Equal(t, []string{"pr-b", "pr-a"}, list(models.PRListFilter{Desc: true, After: &models.PRCursor{Id: "pr-c"}}))
+
+	prs, err := st.PRs.ListPRs(ctx, models.PRListFilter{Sort: models.PRSortCreatedAt})
+	require.NoError(t, err)
+	require.Len(t, prs, 4)
+	after := &models.PRCursor{
+		Value: prs[1].CreatedAt.Format("2006-01-02T15:04:05.999999"),
+		Id:    prs[1].PullRequestId,
+	}
+	rest := list(models.PRListFilter{Sort: models.PRSortCreatedAt, After: after})
+	require.Equal(t, []string{prs[2].PullRequestId, prs[3].PullRequestId}, rest)
+
+	_, err = st.PRs.ListPRs(ctx, models.PRListFilter{Sort: "author_id"})
+	require.ErrorIs(t, err, prerrors.ErrInvalidQuery)
+}
+
+func testImportPRs(t *testing.T, st *repo.Storage) {
+	ctx := context.Background()
+	seedTeam(t, st, "backend", member("u1", "Alice", true), member("u2", "Bob", true), member("u3", "Carol", true))
+	createPR(t, st, "pr-1", "add search", "u1", "u2")
+
+	created := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	merged := created.Add(2 * time.Hour)
+	var n int64
+	require.NoError(t, inTx(t, st, func(ctx context.Context, q db.Querier) error {
+		existing, err := st.PRs.FindExistingPRs(ctx, q, []string{"pr-1", "imp-1"})
+		require.NoError(t, err)
+		require.Equal(t, map[string]struct{}{"pr-1": {}}, existing)
+
+		n, err = st.PRs.ImportPRs(ctx, q, []models.PullRequest{
+			{
+				PullRequestId: "imp-1", PullRequestName: "old feature", AuthorId: "u1",
+				Status: "OPEN", AssignedReviewers: []string{"u2"}, CreatedAt: &created,
+			},
+			{
+				PullRequestId: "imp-2", PullRequestName: "old fix", AuthorId: "u1",
+				Status: "MERGED", AssignedReviewers: []string{"u2", "u3"}, CreatedAt: &created, MergedAt: &merged,
+			},
+		})
+		return err
+	}))
+	require.EqualValues(t, 2, n)
+
+	details, err := st.PRs.GetPRDetails(ctx, "imp-2")
+	require.NoError(t, err)
+	require.Equal(t, "MERGED", details.Status)
+	require.WithinDuration(t, created, *details.CreatedAt, 0)
+	require.WithinDuration(t, merged, *details.MergedAt, 0)
+	require.Equal(t, []string{"u2", "u3"}, reviewerIds(details))
+	for _, r := range details.AssignedReviewers {
+		require.WithinDuration(t, created, *r.AssignedAt, 0)
+	}
+
+	cnt, err := st.Users.CountReview(ctx, "u2")
+	require.NoError(t, err)
+	require.Equal(t, 3, cnt)
+}
+
+func testStats(t *testing.T, st *repo.Storage) {
+	ctx := context.Background()
+	seedTeam(t, st, "backend", member("u1", "Alice", true), member("u2", "Bob", true), member("u3", "Carol", true))
+	seedTeam(t, st, "frontend", member("u4", "Dave", true))
+	createPR(t, st, "pr-1", "add search", "u1", "u2", "u3")
+	require.NoError(t, setDecision(t, st, "pr-1", "u2", models.ReviewApproved))
+
+	now := time.Now().UTC()
+	w := models.StatsWindow{From: now.Add(-24 * time.Hour), To: now.Add(24 * time.Hour)}
+
+	users, err := st.Stats.ReviewerStats(ctx, w, "backend")
+	require.NoError(t, err)
+	require.Len(t, users, 3)
+	require.Equal(t, "u2", users[0].UserId)
+	require.Equal(t, 1, users[0].AssignmentsReceived)
+	require.Equal(t, 1, users[0].ReviewsCompleted)
+	require.NotNil(t, users[0].MedianTimeToFirstReview)
+	require.Equal(t, 1, users[0].OpenLoad)
+	require.Equal(t, "u3", users[1].UserId)
+	require.Equal(t, 1, users[1].AssignmentsReceived)
+	require.Nil(t, users[1].MedianTimeToFirstReview)
+	require.Equal(t, "u1", users[2].UserId)
+	require.Equal(t, 0, users[2].AssignmentsReceived)
+
+	teams, err := st.Stats.TeamReviewStats(ctx, w, "")
+	require.NoError(t, err)
+	require.Len(t, teams, 2)
+	require.Equal(t, "backend", teams[0].TeamName)
+	require.Equal(t, 2, teams[0].AssignmentsReceived)
+	require.Equal(t, 1, teams[0].ReviewsCompleted)
+	require.Equal(t, "frontend", teams[1].TeamName)
+	require.Equal(t, 0, teams[1].AssignmentsReceived)
+
+	weeks, err := st.Stats.PRThroughput(ctx, w, "UTC", "")
+	require.NoError(t, err)
+	require.NotEmpty(t, weeks)
+	opened := 0
+	for _, wk := range weeks {
+		require.Equal(t, "backend", wk.TeamName)
+		opened += wk.Opened
+	}
+	require.Equal(t, 1, opened)
+
+	createPR(t, st, "pr-2", "fix typo", "u4")
+	createPR(t, st, "pr-3", "refactor", "u1", "u2")
+	_, err = mergePR(t, st, "pr-3")
+	require.NoError(t, err)
+	open, err := st.Stats.OpenPRsByTeam(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"backend": 1, "frontend": 1}, open)
+
+	// Время до первого ревью команды считается по PR: решение u3 принято
+	// после решения u2 и на медиану команды не влияет
+	require.NoError(t, setDecision(t, st, "pr-1", "u3", models.ReviewApproved))
+	users, err = st.Stats.ReviewerStats(ctx, w, "backend")
+	require.NoError(t, err)
+	byId := make(map[string]models.ReviewerStats)
+	for _, u := range users {
+		byId[u.UserId] = u
+	}
+	require.NotNil(t, byId["u3"].MedianTimeToFirstReview)
+	require.GreaterOrEqual(t, *byId["u3"].MedianTimeToFirstReview, *byId["u2"].MedianTimeToFirstReview)
+
+	teams, err = st.Stats.TeamReviewStats(ctx, w, "backend")
+	require.NoError(t, err)
+	require.Len(t, teams, 1)
+	require.Equal(t, 2, teams[0].ReviewsCompleted)
+	require.Equal(t, byId["u2"].MedianTimeToFirstReview, teams[0].MedianTimeToFirstReview)
+}
+
+func testForge(t *testing.T, st *repo.Storage) {
+	ctx := context.Background()
+	seedTeam(t, st, "backend", member("u1", "Alice", true), member("u2", "Bob", true))
+	createPR(t, st, "pr-1", "add search", "u1", "u2")
+
+	require.NoError(t, st.Forge.UpsertIdentity(ctx, models.ForgeIdentity{Forge: "gitlab", Login: "alice", UserId: "u1"}))
+
+	userId, err := st.Forge.ResolveUser(ctx, "gitlab", "alice")
+	require.NoError(t, err)
+	require.Equal(t, "u1", userId)
+	userId, err = st.Forge.ResolveUser(ctx, "gitlab", "u2")
+	require.NoError(t, err)
+	require.Equal(t, "u2", userId)
+	_, err = st.Forge.ResolveUser(ctx, "github", "alice")
+	require.ErrorIs(t, err, prerrors.ErrNotFound)
+
+	logins, err := st.Forge.ForgeLogins(ctx, "gitlab", []string{"u1", "u2", "ghost"})
+	require.NoError(t, err)
+	// u2 не сопоставлен: его user_id не выдаётся за логин форджа
+	require.Equal(t, map[string]string{"u1": "alice"}, logins)
+
+	claim := func(deliveryId string, rollback bool) bool {
+		var fresh bool
+		err := inTx(t, st, func(ctx context.Context, q db.Querier) error {
+			var err error
+			fresh, err = st.Forge.ClaimDelivery(ctx, q, "gitlab", deliveryId)
+			if err == nil && rollback {
+				return errRollback
+			}
+			return err
+		})
+		if rollback {
+			require.ErrorIs(t, err, errRollback)
+		} else {
+			require.NoError(t, err)
+		}
+		return fresh
+	}
+	// Откат транзакции снимает отметку доставки
+	require.True(t, claim("d1", true))
+	require.True(t, claim("d1", false))
+	require.False(t, claim("d1", false))
+
+	link := models.ForgePullRequest{PullRequestId: "pr-1", Forge: "gitlab", Project: "group/backend", Number: 7}
+	err = inTx(t, st, func(ctx context.Context, q db.Querier) error {
+		require.NoError(t, st.Forge.LinkPR(ctx, q, link))
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+	_, err = st.Forge.GetPRLink(ctx, "pr-1")
+	require.ErrorIs(t, err, prerrors.ErrNotFound)
+
+	// Повторная доставка вебхука связывает PR ещё раз — без ошибки
+	for range 2 {
+		require.NoError(t, inTx(t, st, func(ctx context.Context, q db.Querier) error {
+			return st.Forge.LinkPR(ctx, q, link)
+		}))
+	}
+	got, err := st.Forge.GetPRLink(ctx, "pr-1")
+	require.NoError(t, err)
+	require.Equal(t, &link, got)
+	_, err = st.Forge.GetPRLink(ctx, "ghost")
+	require.ErrorIs(t, err, prerrors.ErrNotFound)
+}
+
+func testIdempotency(t *testing.T, st *repo.Storage) {
+	ctx := context.Background()
+
+	reserved, err := st.Idempotency.Reserve(ctx, "k1", "fp", time.Hour)
+	require.NoError(t, err)
+	require.True(t, reserved)
+	reserved, err = st.Idempotency.Reserve(ctx, "k1", "other", time.Hour)
+	require.NoError(t, err)
+	require.False(t, reserved)
+
+	rec, err := st.Idempotency.Get(ctx, "k1")
+	require.NoError(t, err)
+	require.Equal(t, "fp", rec.Fingerprint)
+	require.Zero(t, rec.StatusCode)
+
+	header := http.Header{"Content-Type": {"text/plain"}, "Location": {"/pr/1"}}
+	require.NoError(t, st.Idempotency.Complete(ctx, "k1", 201, header, []byte(`{"ok":true}`)))
+	require.NoError(t, st.Idempotency.Release(ctx, "k1"))
+	rec, err = st.Idempotency.Get(ctx, "k1")
+	require.NoError(t, err)
+	require.Equal(t, 201, rec.StatusCode)
+	require.Equal(t, header, rec.Header)
+	require.Equal(t, []byte(`{"ok":true}`), rec.Body)
+
+	_, err = st.Idempotency.Reserve(ctx, "k2", "fp", time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, st.Idempotency.Release(ctx, "k2"))
+	_, err = st.Idempotency.Get(ctx, "k2")
+	require.ErrorIs(t, err, prerrors.ErrNotFound)
+
+	_, err = st.Idempotency.Reserve(ctx, "expired", "fp", -time.Second)
+	require.NoError(t, err)
+	reserved, err = st.Idempotency.Reserve(ctx, "expired", "fp2", time.Hour)
+	require.NoError(t, err)
+	require.True(t, reserved, "expired key can be reserved again")
+
+	_, err = st.Idempotency.Reserve(ctx, "stale", "fp", -time.Second)
+	require.NoError(t, err)
+	purged, err := st.Idempotency.PurgeExpired(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, purged)
+	_, err = st.Idempotency.Get(ctx, "stale")
+	require.ErrorIs(t, err, prerrors.ErrNotFound)
+}
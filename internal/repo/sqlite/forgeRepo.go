@@ -0,0 +1,136 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/repo/db"
+)
+
+type forgeRepo struct {
+	DB *sql.DB
+}
+
+// ResolveUser ищет пользователя по явному соответствию логина,
+// а если его нет — по совпадению логина с user_id.
+func (fr *forgeRepo) ResolveUser(ctx context.Context, forge, login string) (string, error) {
+	const query = `
+	SELECT user_id FROM (
+		SELECT user_id, 0 AS priority FROM forge_identities WHERE forge = ?1 AND login = ?2
+		UNION ALL
+		SELECT user_id, 1 AS priority FROM users WHERE user_id = ?2
+	) candidates
+	ORDER BY priority
+	LIMIT 1
+	`
+
+	var userId string
+	err := fr.DB.QueryRowContext(ctx, query, forge, login).Scan(&userId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", prerrors.ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return userId, nil
+}
+
+func (fr *forgeRepo) UpsertIdentity(ctx context.Context, identity models.ForgeIdentity) error {
+	const query = `
+	INSERT INTO forge_identities (forge, login, user_id)
+	VALUES (?1, ?2, ?3)
+	ON CONFLICT (forge, login) DO UPDATE
+	SET user_id = excluded.user_id
+	`
+
+	_, err := fr.DB.ExecContext(ctx, query, identity.Forge, identity.Login, identity.UserId)
+	return err
+}
+
+// ClaimDelivery помечает доставку обработанной в транзакции q: откат
+// транзакции снимает отметку. Возвращает false, если такая доставка уже была.
+func (fr *forgeRepo) ClaimDelivery(ctx context.Context, q db.Querier, forge, deliveryId string) (bool, error) {
+	tx, err := sqlTx(q)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := tx.ExecContext(
+		ctx,
+		"INSERT INTO forge_deliveries (forge, delivery_id, received_at) VALUES (?1, ?2, ?3) ON CONFLICT DO NOTHING",
+		forge, deliveryId, ts(now()),
+	)
+	if err != nil {
+		return false, classify(err)
+	}
+
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// LinkPR связывает PR с форджем в транзакции q; повторная связь ничего не меняет.
+func (fr *forgeRepo) LinkPR(ctx context.Context, q db.Querier, link models.ForgePullRequest) error {
+	tx, err := sqlTx(q)
+	if err != nil {
+		return err
+	}
+
+	const query = `
+	INSERT INTO forge_pull_requests (pull_request_id, forge, project, number)
+	VALUES (?1, ?2, ?3, ?4)
+	ON CONFLICT (pull_request_id) DO NOTHING
+	`
+
+	_, err = tx.ExecContext(ctx, query, link.PullRequestId, link.Forge, link.Project, link.Number)
+	return classify(err)
+}
+
+func (fr *forgeRepo) GetPRLink(ctx context.Context, prId string) (*models.ForgePullRequest, error) {
+	var link models.ForgePullRequest
+	err := fr.DB.QueryRowContext(
+		ctx,
+		"SELECT pull_request_id, forge, project, number FROM forge_pull_requests WHERE pull_request_id = ?1",
+		prId,
+	).Scan(&link.PullRequestId, &link.Forge, &link.Project, &link.Number)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, prerrors.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &link, nil
+}
+
+// ForgeLogins возвращает логины пользователей в фордже.
+// Пользователей без явного сопоставления в ответе нет;
+// при нескольких логинах берётся наименьший.
+func (fr *forgeRepo) ForgeLogins(ctx context.Context, forge string, userIds []string) (map[string]string, error) {
+	const query = `
+	SELECT user_id, MIN(login)
+	FROM forge_identities
+	WHERE forge = ?1 AND user_id IN (SELECT value FROM json_each(?2))
+	GROUP BY user_id
+	`
+
+	rows, err := fr.DB.QueryContext(ctx, query, forge, jsonArray(userIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logins := make(map[string]string, len(userIds))
+	for rows.Next() {
+		var userId, login string
+		if err := rows.Scan(&userId, &login); err != nil {
+			return nil, err
+		}
+		logins[userId] = login
+	}
+
+	return logins, rows.Err()
+}
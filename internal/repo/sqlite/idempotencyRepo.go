@@ -0,0 +1,100 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/models"
+)
+
+type idempotencyRepo struct {
+	DB *sql.DB
+}
+
+// Reserve занимает ключ под новый запрос. Возвращает false, если ключ
+// уже занят и ещё не истёк — тогда запись нужно прочитать через Get.
+func (ir *idempotencyRepo) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (bool, error) {
+	at := now()
+	_, err := ir.DB.ExecContext(
+		ctx,
+		"DELETE FROM idempotency_keys WHERE idempotency_key = ?1 AND expires_at <= ?2",
+		key, ts(at),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	const query = `
+	INSERT INTO idempotency_keys (idempotency_key, fingerprint, created_at, expires_at)
+	VALUES (?1, ?2, ?3, ?4)
+	ON CONFLICT (idempotency_key) DO NOTHING
+	`
+
+	res, err := ir.DB.ExecContext(ctx, query, key, fingerprint, ts(at), ts(at.Add(ttl)))
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (ir *idempotencyRepo) Get(ctx context.Context, key string) (*models.IdempotencyRecord, error) {
+	const query = `
+	SELECT idempotency_key, fingerprint, COALESCE(status_code, 0), COALESCE(response_headers, 'null'), COALESCE(response_body, X'')
+	FROM idempotency_keys
+	WHERE idempotency_key = ?1
+	`
+
+	var (
+		rec     models.IdempotencyRecord
+		headers string
+	)
+	err := ir.DB.QueryRowContext(ctx, query, key).Scan(&rec.Key, &rec.Fingerprint, &rec.StatusCode, &headers, &rec.Body)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, prerrors.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(headers), &rec.Header); err != nil {
+		return nil, err
+	}
+
+	return &rec, nil
+}
+
+func (ir *idempotencyRepo) Complete(ctx context.Context, key string, statusCode int, header http.Header, body []byte) error {
+	headers, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	_, err = ir.DB.ExecContext(
+		ctx,
+		"UPDATE idempotency_keys SET status_code = ?2, response_headers = ?3, response_body = ?4 WHERE idempotency_key = ?1",
+		key, statusCode, string(headers), body,
+	)
+	return err
+}
+
+func (ir *idempotencyRepo) Release(ctx context.Context, key string) error {
+	_, err := ir.DB.ExecContext(
+		ctx,
+		"DELETE FROM idempotency_keys WHERE idempotency_key = ?1 AND status_code IS NULL",
+		key,
+	)
+	return err
+}
+
+func (ir *idempotencyRepo) PurgeExpired(ctx context.Context) (int64, error) {
+	res, err := ir.DB.ExecContext(ctx, "DELETE FROM idempotency_keys WHERE expires_at <= ?1", ts(now()))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,129 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"go.uber.org/zap"
+
+	logger "github.com/andro-kes/avito_test/internal/log"
+	"github.com/andro-kes/avito_test/internal/migrations"
+)
+
+// Свой набор миграций: схема Postgres использует массивы, GIN-индексы и
+// функции, которых в SQLite нет.
+//
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// migrate применяет неприменённые миграции, каждую в своей транзакции вместе
+// с записью в schema_migrations. Как и для Postgres, изменённый после
+// применения файл останавливает запуск.
+func migrate(ctx context.Context, conn *sql.DB) error {
+	fsys, err := fs.Sub(migrationsFS, "migrations")
+	if err != nil {
+		return err
+	}
+	all, err := migrations.Load(fsys)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx, `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TEXT NOT NULL,
+		checksum TEXT NOT NULL
+	)`)
+	if err != nil {
+		return err
+	}
+
+	applied, err := appliedMigrations(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	var modified []string
+	for _, m := range all {
+		if checksum, ok := applied[m.Version]; ok && checksum != m.Checksum {
+			modified = append(modified, fmt.Sprintf("%06d_%s", m.Version, m.Name))
+		}
+	}
+	if len(modified) > 0 {
+		return fmt.Errorf("applied migrations were modified: %s; restore the original files and add a new migration instead",
+			strings.Join(modified, ", "))
+	}
+
+	for _, m := range all {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+
+		logger.Log.Info("Applying migration",
+			zap.Int("version", m.Version),
+			zap.String("name", m.Name))
+		if err := apply(ctx, conn, m); err != nil {
+			return fmt.Errorf("migration %d %s: %w", m.Version, m.Name, err)
+		}
+	}
+	return nil
+}
+
+func appliedMigrations(ctx context.Context, conn *sql.DB) (map[int]string, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, checksum FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]string)
+	for rows.Next() {
+		var (
+			version  int
+			checksum string
+		)
+		if err := rows.Scan(&version, &checksum); err != nil {
+			return nil, err
+		}
+		applied[version] = checksum
+	}
+
+	return applied, rows.Err()
+}
+
+// apply выполняет миграцию, если её ещё не применил другой процесс:
+// BEGIN IMMEDIATE не пускает две транзакции записи одновременно.
+func apply(ctx context.Context, conn *sql.DB, m migrations.Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var done bool
+	err = tx.QueryRowContext(ctx,
+		"SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version = ?1)", m.Version,
+	).Scan(&done)
+	if err != nil || done {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, m.UpSQL); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO schema_migrations (version, name, applied_at, checksum) VALUES (?1, ?2, ?3, ?4)",
+		m.Version, m.Name, ts(now()), m.Checksum,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -1,5 +1,9 @@
 -- Удаление таблиц в обратном порядке (из-за внешних ключей)
+DROP TABLE IF EXISTS idempotency_keys;
+DROP TABLE IF EXISTS forge_deliveries;
+DROP TABLE IF EXISTS forge_pull_requests;
+DROP TABLE IF EXISTS forge_identities;
+DROP TABLE IF EXISTS pr_reviewers;
 DROP TABLE IF EXISTS pull_requests;
 DROP TABLE IF EXISTS users;
 DROP TABLE IF EXISTS teams;
-
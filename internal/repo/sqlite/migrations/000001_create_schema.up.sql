@@ -0,0 +1,91 @@
+-- Схема повторяет миграции Postgres. Время хранится текстом в UTC в формате
+-- 2006-01-02 15:04:05.000000: такие строки сравниваются так же, как время.
+-- Массивы хранятся JSON-массивами строк.
+
+CREATE TABLE teams (
+    team_name TEXT PRIMARY KEY
+);
+
+CREATE TABLE users (
+    user_id TEXT PRIMARY KEY,
+    username TEXT NOT NULL,
+    team_name TEXT NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE INDEX idx_users_team_name ON users(team_name);
+
+CREATE TABLE pull_requests (
+    pull_request_id TEXT PRIMARY KEY,
+    pull_request_name TEXT NOT NULL,
+    author_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE RESTRICT,
+    status TEXT NOT NULL DEFAULT 'OPEN' CHECK (status IN ('OPEN', 'MERGED', 'CLOSED')),
+    -- Текущие ревьюверы в порядке назначения
+    assigned_reviewers TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(assigned_reviewers)),
+    is_draft BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TEXT NOT NULL,
+    merged_at TEXT,
+    closed_at TEXT
+);
+
+-- Индексы для выборки PR с keyset-пагинацией (/pullRequest/list/)
+CREATE INDEX idx_pr_created_at ON pull_requests(created_at, pull_request_id);
+CREATE INDEX idx_pr_merged_at ON pull_requests(merged_at, pull_request_id) WHERE merged_at IS NOT NULL;
+CREATE INDEX idx_pr_name ON pull_requests(pull_request_name, pull_request_id);
+CREATE INDEX idx_pr_status_created_at ON pull_requests(status, created_at, pull_request_id);
+CREATE INDEX idx_pr_author_created_at ON pull_requests(author_id, created_at, pull_request_id);
+
+-- История назначений ревьюверов. Текущие назначения — строки без unassigned_at,
+-- они повторяют pull_requests.assigned_reviewers.
+CREATE TABLE pr_reviewers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    assigned_at TEXT NOT NULL,
+    unassigned_at TEXT,
+    decision TEXT NOT NULL DEFAULT 'PENDING' CHECK (decision IN ('PENDING', 'APPROVED', 'CHANGES_REQUESTED')),
+    decided_at TEXT
+);
+
+CREATE UNIQUE INDEX idx_pr_reviewers_current
+    ON pr_reviewers(pull_request_id, user_id) WHERE unassigned_at IS NULL;
+CREATE INDEX idx_pr_reviewers_user_id ON pr_reviewers(user_id, assigned_at);
+
+-- Соответствие логинов во внешних форджах пользователям сервиса
+CREATE TABLE forge_identities (
+    forge TEXT NOT NULL,
+    login TEXT NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    PRIMARY KEY (forge, login)
+);
+
+CREATE INDEX idx_forge_identities_user_id ON forge_identities(user_id);
+
+-- Связь PR сервиса с merge/pull request во внешнем фордже
+CREATE TABLE forge_pull_requests (
+    pull_request_id TEXT PRIMARY KEY REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    forge TEXT NOT NULL,
+    project TEXT NOT NULL,
+    number INTEGER NOT NULL,
+    CONSTRAINT unique_forge_pr UNIQUE (forge, project, number)
+);
+
+-- Уже обработанные доставки вебхуков (идемпотентность)
+CREATE TABLE forge_deliveries (
+    forge TEXT NOT NULL,
+    delivery_id TEXT NOT NULL,
+    received_at TEXT NOT NULL,
+    PRIMARY KEY (forge, delivery_id)
+);
+
+-- Ключи идемпотентности POST-запросов и сохранённые ответы
+CREATE TABLE idempotency_keys (
+    idempotency_key TEXT PRIMARY KEY,
+    fingerprint TEXT NOT NULL,
+    status_code INTEGER,
+    response_body BLOB,
+    created_at TEXT NOT NULL,
+    expires_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,2 @@
+-- Заголовки сохранённого ответа (JSON), которые повторяются вместе с телом
+ALTER TABLE idempotency_keys ADD COLUMN response_headers TEXT;
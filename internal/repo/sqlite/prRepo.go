@@ -0,0 +1,723 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/repo/db"
+)
+
+// prRepo не блокирует строки, как FOR UPDATE в Postgres: транзакции
+// начинаются с BEGIN IMMEDIATE, и пишущие и так выполняются по одной.
+type prRepo struct {
+	DB *sql.DB
+}
+
+// querier — общее у *sql.DB и *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// prColumns — поля PR в порядке scanPR.
+const prColumns = `pull_request_id, pull_request_name, author_id, status, assigned_reviewers, is_draft, created_at, merged_at`
+
+func scanPR(row interface{ Scan(dest ...any) error }) (*models.PullRequest, error) {
+	var pr models.PullRequest
+	err := row.Scan(
+		&pr.PullRequestId, &pr.PullRequestName,
+		&pr.AuthorId, &pr.Status,
+		scanArray(&pr.AssignedReviewers), &pr.IsDraft, scanTime(&pr.CreatedAt), scanTime(&pr.MergedAt),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+func (p *prRepo) CreatePR(ctx context.Context, q db.Querier, pr *models.PullRequestShort, reviewers []string) (*models.PullRequest, error) {
+	tx, err := sqlTx(q)
+	if err != nil {
+		return nil, err
+	}
+
+	status := "OPEN"
+	if pr.Status != "" {
+		status = pr.Status
+	}
+
+	query := `
+	INSERT INTO pull_requests
+	(pull_request_id, pull_request_name, author_id, status, assigned_reviewers, created_at)
+	VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+	RETURNING ` + prColumns
+
+	pullRequest, err := scanPR(tx.QueryRowContext(
+		ctx,
+		query,
+		pr.PullRequestId, pr.PullRequestName, pr.AuthorId, status, jsonArray(reviewers), ts(now()),
+	))
+	if isUniqueViolation(err) {
+		return nil, prerrors.Wrap(prerrors.ErrPRExists, err)
+	}
+	if err != nil {
+		return nil, classify(err)
+	}
+
+	err = syncReviewerHistory(ctx, tx, pullRequest.PullRequestId, pullRequest.AssignedReviewers)
+	return pullRequest, classify(err)
+}
+
+func (p *prRepo) FindActiveReviewers(ctx context.Context, q db.Querier, authorId string) ([]string, error) {
+	tx, err := sqlTx(q)
+	if err != nil {
+		return nil, err
+	}
+
+	const query = `
+	SELECT user_id
+	FROM users
+	WHERE team_name = (
+		SELECT team_name
+		FROM users
+		WHERE user_id = ?1
+	)
+	AND is_active = TRUE
+	AND user_id <> ?1
+	ORDER BY user_id
+	`
+
+	return queryIds(ctx, tx, query, authorId)
+}
+
+func (p *prRepo) FindActiveReviewersInTeam(ctx context.Context, q db.Querier, teamName, authorId string) ([]string, error) {
+	tx, err := sqlTx(q)
+	if err != nil {
+		return nil, err
+	}
+
+	const query = `
+	SELECT user_id
+	FROM users
+	WHERE team_name = ?1
+	AND is_active = TRUE
+	AND user_id <> ?2
+	ORDER BY user_id
+	`
+
+	return queryIds(ctx, tx, query, teamName, authorId)
+}
+
+// queryIds возвращает первую колонку строк запроса; nil, если строк нет.
+func queryIds(ctx context.Context, q querier, query string, args ...any) ([]string, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func (p *prRepo) CheckExistingPR(ctx context.Context, q db.Querier, id string) (bool, error) {
+	tx, err := sqlTx(q)
+	if err != nil {
+		return false, err
+	}
+
+	var exists bool
+	err = tx.QueryRowContext(
+		ctx,
+		"SELECT EXISTS(SELECT 1 FROM pull_requests WHERE pull_request_id = ?1)",
+		id,
+	).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (p *prRepo) MergePR(ctx context.Context, q db.Querier, id string) (*models.PullRequest, error) {
+	tx, err := sqlTx(q)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+	UPDATE pull_requests
+	SET
+		status = 'MERGED',
+		merged_at = COALESCE(merged_at, ?2)
+	WHERE pull_request_id = ?1
+	RETURNING ` + prColumns
+
+	pr, err := scanPR(tx.QueryRowContext(ctx, query, id, ts(now())))
+	if err != nil {
+		return nil, classify(err)
+	}
+
+	return pr, nil
+}
+
+func (p *prRepo) ClosePR(ctx context.Context, q db.Querier, id string) (*models.PullRequest, error) {
+	query := `
+	UPDATE pull_requests
+	SET
+		status = 'CLOSED',
+		closed_at = COALESCE(closed_at, ?2)
+	WHERE pull_request_id = ?1
+	AND status <> 'MERGED'
+	RETURNING ` + prColumns
+
+	return p.updateStatus(ctx, q, query, id, ts(now()))
+}
+
+func (p *prRepo) ReopenPR(ctx context.Context, q db.Querier, id string) (*models.PullRequest, error) {
+	query := `
+	UPDATE pull_requests
+	SET
+		status = 'OPEN',
+		closed_at = NULL
+	WHERE pull_request_id = ?1
+	AND status <> 'MERGED'
+	RETURNING ` + prColumns
+
+	return p.updateStatus(ctx, q, query, id)
+}
+
+func (p *prRepo) SetDraft(ctx context.Context, q db.Querier, id string, isDraft bool) (*models.PullRequest, error) {
+	query := `
+	UPDATE pull_requests
+	SET is_draft = ?2
+	WHERE pull_request_id = ?1
+	RETURNING ` + prColumns
+
+	return p.updateStatus(ctx, q, query, id, isDraft)
+}
+
+// updateStatus выполняет UPDATE ... RETURNING в транзакции q и различает
+// отсутствующий PR и PR, который уже смержен.
+func (p *prRepo) updateStatus(ctx context.Context, q db.Querier, query, id string, args ...any) (*models.PullRequest, error) {
+	tx, err := sqlTx(q)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, err := scanPR(tx.QueryRowContext(ctx, query, append([]any{id}, args...)...))
+	if errors.Is(err, sql.ErrNoRows) {
+		if errors.Is(isMerged(ctx, tx, id), prerrors.ErrPRMerged) {
+			return nil, prerrors.ErrPRMerged
+		}
+		return nil, prerrors.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return pr, nil
+}
+
+func (p *prRepo) IsMerged(ctx context.Context, q db.Querier, id string) error {
+	tx, err := sqlTx(q)
+	if err != nil {
+		return err
+	}
+	return isMerged(ctx, tx, id)
+}
+
+func isMerged(ctx context.Context, q querier, id string) error {
+	var merged bool
+	err := q.QueryRowContext(
+		ctx,
+		"SELECT merged_at IS NOT NULL FROM pull_requests WHERE pull_request_id = ?1",
+		id,
+	).Scan(&merged)
+	if err != nil {
+		return classify(err)
+	}
+
+	if merged {
+		return prerrors.ErrPRMerged
+	}
+
+	return nil
+}
+
+func (p *prRepo) FindReplacementReviewers(ctx context.Context, q db.Querier, prID string, oldUserId []string) ([]string, error) {
+	tx, err := sqlTx(q)
+	if err != nil {
+		return nil, err
+	}
+
+	const query = `
+	SELECT u.user_id
+	FROM users u
+	INNER JOIN users old_reviewer ON u.team_name = old_reviewer.team_name
+	INNER JOIN pull_requests pr ON pr.pull_request_id = ?1
+	WHERE old_reviewer.user_id IN (SELECT value FROM json_each(?2))
+	AND u.is_active = TRUE
+	AND u.user_id <> pr.author_id
+	AND u.user_id NOT IN (SELECT value FROM json_each(?2))
+	AND u.user_id NOT IN (SELECT value FROM json_each(pr.assigned_reviewers))
+	`
+
+	return queryIds(ctx, tx, query, prID, jsonArray(oldUserId))
+}
+
+func (p *prRepo) ReassignReviewer(ctx context.Context, q db.Querier, prId, oldUserId, replacedBy string) (*models.PullRequest, error) {
+	tx, err := sqlTx(q)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		status   string
+		assigned []string
+	)
+	err = tx.QueryRowContext(
+		ctx,
+		"SELECT status, assigned_reviewers FROM pull_requests WHERE pull_request_id = ?1",
+		prId,
+	).Scan(&status, scanArray(&assigned))
+	if err != nil {
+		return nil, classify(err)
+	}
+	switch status {
+	case "MERGED":
+		return nil, prerrors.ErrPRMerged
+	case "CLOSED":
+		return nil, prerrors.ErrConflict
+	}
+	if !slices.Contains(assigned, oldUserId) {
+		return nil, prerrors.ErrNotAssigned
+	}
+
+	// Как array_replace: заменяются все вхождения, порядок сохраняется.
+	for i, id := range assigned {
+		if id == oldUserId {
+			assigned[i] = replacedBy
+		}
+	}
+
+	query := `
+	UPDATE pull_requests
+	SET assigned_reviewers = ?2
+	WHERE pull_request_id = ?1
+	AND status = 'OPEN'
+	RETURNING ` + prColumns
+
+	pr, err := scanPR(tx.QueryRowContext(ctx, query, prId, jsonArray(assigned)))
+
+	// Нет строки — PR уже не открыт.
+	if err != nil {
+		return nil, classify(err)
+	}
+
+	if err := syncReviewerHistory(ctx, tx, pr.PullRequestId, pr.AssignedReviewers); err != nil {
+		return nil, err
+	}
+
+	return pr, nil
+}
+
+func (p *prRepo) GetListByUsers(ctx context.Context, q db.Querier, ids []string) ([]models.PullRequest, error) {
+	prs := make([]models.PullRequest, 0)
+	tx, err := sqlTx(q)
+	if err != nil {
+		return prs, err
+	}
+
+	const query = `
+	SELECT pull_request_id, assigned_reviewers, author_id
+	FROM pull_requests pr
+	WHERE status = 'OPEN'
+	AND EXISTS (
+		SELECT 1 FROM json_each(pr.assigned_reviewers) r
+		WHERE r.value IN (SELECT value FROM json_each(?1))
+	)
+	`
+
+	rows, err := tx.QueryContext(ctx, query, jsonArray(ids))
+	if err != nil {
+		return prs, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pr models.PullRequest
+		if err := rows.Scan(&pr.PullRequestId, scanArray(&pr.AssignedReviewers), &pr.AuthorId); err != nil {
+			return prs, err
+		}
+		prs = append(prs, pr)
+	}
+
+	return prs, rows.Err()
+}
+
+// existingIds возвращает те из ids, что нашлись запросом query
+// с параметром ?1 — JSON-массивом ids.
+func existingIds(ctx context.Context, q querier, query string, ids []string) (map[string]struct{}, error) {
+	rows, err := q.QueryContext(ctx, query, jsonArray(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	found := make(map[string]struct{}, len(ids))
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		found[id] = struct{}{}
+	}
+
+	return found, rows.Err()
+}
+
+func (p *prRepo) ChangeDeactivatedReviewers(ctx context.Context, q db.Querier, prId string, replaced []string) error {
+	tx, err := sqlTx(q)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"UPDATE pull_requests SET assigned_reviewers = ?1 WHERE pull_request_id = ?2",
+		jsonArray(replaced),
+		prId,
+	)
+	if err != nil {
+		return err
+	}
+
+	return syncReviewerHistory(ctx, tx, prId, replaced)
+}
+
+// syncReviewerHistory приводит текущие назначения в pr_reviewers к списку reviewers:
+// снятые получают unassigned_at, новые добавляются с assigned_at — текущим временем.
+func syncReviewerHistory(ctx context.Context, q querier, prId string, reviewers []string) error {
+	at := ts(now())
+	_, err := q.ExecContext(
+		ctx,
+		`UPDATE pr_reviewers SET unassigned_at = ?3
+		WHERE pull_request_id = ?1 AND unassigned_at IS NULL
+		AND user_id NOT IN (SELECT value FROM json_each(?2))`,
+		prId, jsonArray(reviewers), at,
+	)
+	if err != nil {
+		return err
+	}
+
+	// WHERE TRUE нужен парсеру SQLite, чтобы ON CONFLICT не отнёсся к SELECT.
+	_, err = q.ExecContext(
+		ctx,
+		`INSERT INTO pr_reviewers (pull_request_id, user_id, assigned_at)
+		SELECT ?1, value, ?3 FROM json_each(?2) WHERE TRUE
+		ON CONFLICT DO NOTHING`,
+		prId, jsonArray(reviewers), at,
+	)
+	return err
+}
+
+func (p *prRepo) FindExistingPRs(ctx context.Context, q db.Querier, ids []string) (map[string]struct{}, error) {
+	tx, err := sqlTx(q)
+	if err != nil {
+		return nil, err
+	}
+	return existingIds(ctx, tx, "SELECT pull_request_id FROM pull_requests WHERE pull_request_id IN (SELECT value FROM json_each(?1))", ids)
+}
+
+func (p *prRepo) ImportPRs(ctx context.Context, q db.Querier, prs []models.PullRequest) (int64, error) {
+	tx, err := sqlTx(q)
+	if err != nil {
+		return 0, err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+	INSERT INTO pull_requests
+	(pull_request_id, pull_request_name, author_id, status, assigned_reviewers, is_draft, created_at, merged_at, closed_at)
+	VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9)
+	`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	var n int64
+	ids := make([]string, len(prs))
+	for i, pr := range prs {
+		// Время закрытия в модели не хранится — считаем PR закрытым в момент импорта.
+		var closedAt *time.Time
+		if pr.Status == "CLOSED" {
+			at := now()
+			closedAt = &at
+		}
+		_, err := stmt.ExecContext(
+			ctx,
+			pr.PullRequestId, pr.PullRequestName, pr.AuthorId, pr.Status,
+			jsonArray(pr.AssignedReviewers), pr.IsDraft, nullTs(pr.CreatedAt), nullTs(pr.MergedAt), nullTs(closedAt),
+		)
+		if err != nil {
+			return n, err
+		}
+		n++
+		ids[i] = pr.PullRequestId
+	}
+
+	// Импортированные ревьюверы считаются назначенными при создании PR
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO pr_reviewers (pull_request_id, user_id, assigned_at)
+		SELECT pr.pull_request_id, r.value, pr.created_at
+		FROM pull_requests pr, json_each(pr.assigned_reviewers) r
+		WHERE pr.pull_request_id IN (SELECT value FROM json_each(?1))
+		ON CONFLICT DO NOTHING`,
+		jsonArray(ids),
+	)
+	return n, err
+}
+
+// GetPRDetails возвращает PR с автором и ревьюверами одним запросом:
+// по строке на ревьювера в порядке assigned_reviewers.
+func (p *prRepo) GetPRDetails(ctx context.Context, id string) (*models.PullRequestDetails, error) {
+	const query = `
+	SELECT
+	pr.pull_request_id, pr.pull_request_name, pr.status, pr.is_draft, pr.created_at, pr.merged_at,
+	a.user_id, a.username, a.team_name, a.is_active,
+	ar.value, r.username, r.team_name, r.is_active, rv.assigned_at, COALESCE(rv.decision, 'PENDING')
+	FROM pull_requests pr
+	INNER JOIN users a ON a.user_id = pr.author_id
+	LEFT JOIN json_each(pr.assigned_reviewers) ar ON TRUE
+	LEFT JOIN users r ON r.user_id = ar.value
+	LEFT JOIN pr_reviewers rv
+		ON rv.pull_request_id = pr.pull_request_id AND rv.user_id = ar.value AND rv.unassigned_at IS NULL
+	WHERE pr.pull_request_id = ?1
+	ORDER BY ar.key
+	`
+
+	rows, err := p.DB.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pr *models.PullRequestDetails
+	for rows.Next() {
+		var (
+			row      models.PullRequestDetails
+			userId   *string
+			username *string
+			teamName *string
+			isActive *bool
+			reviewer models.PRReviewer
+		)
+		if err := rows.Scan(
+			&row.PullRequestId, &row.PullRequestName, &row.Status, &row.IsDraft,
+			scanTime(&row.CreatedAt), scanTime(&row.MergedAt),
+			&row.Author.UserId, &row.Author.Username, &row.Author.TeamName, &row.Author.IsActive,
+			&userId, &username, &teamName, &isActive, scanTime(&reviewer.AssignedAt), &reviewer.Decision,
+		); err != nil {
+			return nil, err
+		}
+
+		if pr == nil {
+			row.AssignedReviewers = make([]models.PRReviewer, 0, 2)
+			pr = &row
+		}
+		if userId == nil {
+			continue
+		}
+
+		reviewer.UserId = *userId
+		if username != nil {
+			reviewer.Username = *username
+			reviewer.TeamName = *teamName
+			reviewer.IsActive = *isActive
+		}
+		pr.AssignedReviewers = append(pr.AssignedReviewers, reviewer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if pr == nil {
+		return nil, prerrors.ErrNotFound
+	}
+
+	return pr, nil
+}
+
+// SetDecision записывает решение текущего ревьювера; decided_at хранит время
+// первого решения, чтобы считать время до первого ревью. Решения по смерженным
+// и закрытым PR не принимаются.
+func (p *prRepo) SetDecision(ctx context.Context, q db.Querier, prId, userId, decision string) error {
+	tx, err := sqlTx(q)
+	if err != nil {
+		return err
+	}
+
+	var status string
+	err = tx.QueryRowContext(
+		ctx,
+		"SELECT status FROM pull_requests WHERE pull_request_id = ?1",
+		prId,
+	).Scan(&status)
+	if err != nil {
+		return classify(err)
+	}
+	switch status {
+	case "MERGED":
+		return prerrors.ErrPRMerged
+	case "CLOSED":
+		return prerrors.ErrConflict
+	}
+
+	const query = `
+	UPDATE pr_reviewers
+	SET decision = ?3, decided_at = COALESCE(decided_at, ?4)
+	WHERE pull_request_id = ?1 AND user_id = ?2 AND unassigned_at IS NULL
+	`
+
+	res, err := tx.ExecContext(ctx, query, prId, userId, decision, ts(now()))
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return prerrors.ErrNotAssigned
+	}
+	return nil
+}
+
+// prSortColumns — допустимые поля сортировки; true — колонка времени.
+var prSortColumns = map[string]bool{
+	models.PRSortCreatedAt: true,
+	models.PRSortMergedAt:  true,
+	models.PRSortName:      false,
+	models.PRSortId:        false,
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ListPRs повторяет выборку Postgres. Отличие одно: LIKE в SQLite
+// не различает регистр только для латиницы.
+func (p *prRepo) ListPRs(ctx context.Context, f models.PRListFilter) ([]models.PullRequest, error) {
+	sortCol := f.Sort
+	isTime, ok := prSortColumns[sortCol]
+	if !ok {
+		return nil, prerrors.ErrInvalidQuery
+	}
+
+	where := make([]string, 0, 8)
+	args := make([]any, 0, 12)
+	arg := func(v any) string {
+		args = append(args, v)
+		return "?" + strconv.Itoa(len(args))
+	}
+
+	if f.Status != "" {
+		where = append(where, "status = "+arg(f.Status))
+	}
+	if f.AuthorId != "" {
+		where = append(where, "author_id = "+arg(f.AuthorId))
+	}
+	if f.ReviewerId != "" {
+		where = append(where, "EXISTS (SELECT 1 FROM json_each(assigned_reviewers) r WHERE r.value = "+arg(f.ReviewerId)+")")
+	}
+	if f.TeamName != "" {
+		where = append(where, "author_id IN (SELECT user_id FROM users WHERE team_name = "+arg(f.TeamName)+")")
+	}
+	if f.Name != "" {
+		where = append(where, "pull_request_name LIKE '%' || "+arg(likeEscaper.Replace(f.Name))+` || '%' ESCAPE '\'`)
+	}
+	if f.CreatedFrom != nil {
+		where = append(where, "created_at >= "+arg(ts(*f.CreatedFrom)))
+	}
+	if f.CreatedTo != nil {
+		where = append(where, "created_at < "+arg(ts(*f.CreatedTo)))
+	}
+	if f.MergedFrom != nil {
+		where = append(where, "merged_at >= "+arg(ts(*f.MergedFrom)))
+	}
+	if f.MergedTo != nil {
+		where = append(where, "merged_at < "+arg(ts(*f.MergedTo)))
+	}
+	// Сортировка по merged_at имеет смысл только для смёрженных PR
+	if sortCol == models.PRSortMergedAt {
+		where = append(where, "merged_at IS NOT NULL")
+	}
+
+	cmp, dir := ">", "ASC"
+	if f.Desc {
+		cmp, dir = "<", "DESC"
+	}
+	order := "pull_request_id " + dir
+	if sortCol != models.PRSortId {
+		order = sortCol + " " + dir + ", " + order
+	}
+
+	if c := f.After; c != nil {
+		if sortCol == models.PRSortId {
+			where = append(where, "pull_request_id "+cmp+" "+arg(c.Id))
+		} else {
+			value := c.Value
+			if isTime {
+				// Значение курсора приводится к формату колонки, как ::timestamp в Postgres.
+				at, err := time.Parse("2006-01-02T15:04:05.999999", c.Value)
+				if err != nil {
+					return nil, prerrors.Wrap(prerrors.ErrInvalidQuery, err)
+				}
+				value = ts(at)
+			}
+			where = append(where, "("+sortCol+", pull_request_id) "+cmp+" ("+arg(value)+", "+arg(c.Id)+")")
+		}
+	}
+
+	query := `
+	SELECT ` + prColumns + `
+	FROM pull_requests`
+	if len(where) > 0 {
+		query += "\n\tWHERE " + strings.Join(where, "\n\tAND ")
+	}
+	query += "\n\tORDER BY " + order
+	if f.Limit > 0 {
+		query += "\n\tLIMIT " + arg(f.Limit)
+	}
+
+	rows, err := p.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prs := make([]models.PullRequest, 0, max(f.Limit, 4))
+	for rows.Next() {
+		pr, err := scanPR(rows)
+		if err != nil {
+			return nil, err
+		}
+		prs = append(prs, *pr)
+	}
+
+	return prs, rows.Err()
+}
@@ -0,0 +1,47 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	logger "github.com/andro-kes/avito_test/internal/log"
+	"github.com/andro-kes/avito_test/internal/repo"
+	"github.com/andro-kes/avito_test/internal/repo/db"
+	"github.com/andro-kes/avito_test/internal/repo/repotest"
+	"github.com/andro-kes/avito_test/internal/repo/sqlite"
+)
+
+func TestStorage(t *testing.T) {
+	logger.Init()
+
+	repotest.Run(t, func(t *testing.T) *repo.Storage {
+		st, err := sqlite.Open(context.Background(), filepath.Join(t.TempDir(), "pr.db"))
+		require.NoError(t, err)
+		t.Cleanup(st.Close)
+		return st
+	})
+}
+
+func TestReopen(t *testing.T) {
+	logger.Init()
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "pr.db")
+
+	st, err := sqlite.Open(ctx, path)
+	require.NoError(t, err)
+	require.NoError(t, st.Teams.CheckUnique(ctx, "backend"))
+	require.NoError(t, st.Tx.RunInTx(ctx, func(ctx context.Context, q db.Querier) error {
+		return st.Teams.CreateTeam(ctx, q, "backend")
+	}))
+	st.Close()
+
+	// Повторное открытие не применяет миграции заново и видит данные
+	st, err = sqlite.Open(ctx, path)
+	require.NoError(t, err)
+	defer st.Close()
+	require.ErrorIs(t, st.Teams.CheckUnique(ctx, "backend"), prerrors.ErrTeamExists)
+}
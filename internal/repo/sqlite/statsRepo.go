@@ -0,0 +1,209 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/repo/agg"
+)
+
+// statsRepo отбирает строки запросами, а медианы и недельные корзины
+// считает пакет agg: percentile_cont и date_trunc в SQLite нет.
+type statsRepo struct {
+	DB *sql.DB
+}
+
+func (sr *statsRepo) teamUsers(ctx context.Context, teamName string) ([]models.User, error) {
+	rows, err := sr.DB.QueryContext(
+		ctx,
+		"SELECT user_id, username, team_name, is_active FROM users WHERE ?1 = '' OR team_name = ?1",
+		teamName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]models.User, 0)
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.UserId, &u.Username, &u.TeamName, &u.IsActive); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+
+	return users, rows.Err()
+}
+
+// reviews возвращает назначения пользователей команды, затронутые окном,
+// и все решения: первое решение по PR могло быть принято до окна.
+func (sr *statsRepo) reviews(ctx context.Context, w models.StatsWindow, teamName string) ([]agg.Review, error) {
+	const query = `
+	SELECT rv.user_id, rv.pull_request_id, pr.created_at, rv.assigned_at, rv.unassigned_at, rv.decided_at
+	FROM pr_reviewers rv
+	INNER JOIN users u ON u.user_id = rv.user_id
+	INNER JOIN pull_requests pr ON pr.pull_request_id = rv.pull_request_id
+	WHERE (?3 = '' OR u.team_name = ?3)
+	AND rv.assigned_at < ?2
+	AND (rv.assigned_at >= ?1 OR rv.decided_at IS NOT NULL OR rv.unassigned_at >= ?1)
+	`
+
+	rows, err := sr.DB.QueryContext(ctx, query, ts(w.From), ts(w.To), teamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := make([]agg.Review, 0)
+	for rows.Next() {
+		var (
+			r                     agg.Review
+			createdAt, assignedAt *time.Time
+		)
+		if err := rows.Scan(
+			&r.UserId, &r.PullRequestId, scanTime(&createdAt),
+			scanTime(&assignedAt), scanTime(&r.UnassignedAt), scanTime(&r.DecidedAt),
+		); err != nil {
+			return nil, err
+		}
+		r.PRCreatedAt = *createdAt
+		r.AssignedAt = *assignedAt
+		reviews = append(reviews, r)
+	}
+
+	return reviews, rows.Err()
+}
+
+func (sr *statsRepo) ReviewerStats(ctx context.Context, w models.StatsWindow, teamName string) ([]models.ReviewerStats, error) {
+	users, err := sr.teamUsers(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	reviews, err := sr.reviews(ctx, w, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Число открытых PR, где пользователь сейчас ревьювер.
+	const query = `
+	SELECT r.value, COUNT(*)
+	FROM pull_requests pr, json_each(pr.assigned_reviewers) r
+	WHERE pr.status = 'OPEN'
+	GROUP BY r.value
+	`
+
+	rows, err := sr.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	openLoad := make(map[string]int)
+	for rows.Next() {
+		var (
+			userId string
+			n      int
+		)
+		if err := rows.Scan(&userId, &n); err != nil {
+			return nil, err
+		}
+		openLoad[userId] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return agg.ReviewerStats(w, users, reviews, openLoad), nil
+}
+
+func (sr *statsRepo) TeamReviewStats(ctx context.Context, w models.StatsWindow, teamName string) ([]models.TeamReviewStats, error) {
+	users, err := sr.teamUsers(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	reviews, err := sr.reviews(ctx, w, teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	return agg.TeamReviewStats(w, users, reviews), nil
+}
+
+// PRThroughput группирует открытия, мержи и закрытия PR по команде автора и неделе.
+// Время в БД хранится в UTC, недели отсчитываются в часовом поясе tz.
+func (sr *statsRepo) PRThroughput(ctx context.Context, w models.StatsWindow, tz, teamName string) ([]models.PRThroughput, error) {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, err
+	}
+
+	const query = `
+	SELECT u.team_name, pr.created_at, pr.merged_at, pr.closed_at, json_array_length(pr.assigned_reviewers)
+	FROM pull_requests pr
+	INNER JOIN users u ON u.user_id = pr.author_id
+	WHERE (?3 = '' OR u.team_name = ?3)
+	AND (
+		pr.created_at >= ?1 AND pr.created_at < ?2
+		OR pr.merged_at >= ?1 AND pr.merged_at < ?2
+		OR pr.closed_at >= ?1 AND pr.closed_at < ?2
+	)
+	`
+
+	rows, err := sr.DB.QueryContext(ctx, query, ts(w.From), ts(w.To), teamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prs := make([]agg.PR, 0)
+	for rows.Next() {
+		var (
+			pr        agg.PR
+			createdAt *time.Time
+		)
+		if err := rows.Scan(&pr.TeamName, scanTime(&createdAt), scanTime(&pr.MergedAt), scanTime(&pr.ClosedAt), &pr.Reviewers); err != nil {
+			return nil, err
+		}
+		pr.CreatedAt = *createdAt
+		prs = append(prs, pr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return agg.Throughput(w, loc, prs), nil
+}
+
+// OpenPRsByTeam считает открытые PR по команде автора.
+func (sr *statsRepo) OpenPRsByTeam(ctx context.Context) (map[string]int, error) {
+	const query = `
+	SELECT u.team_name, COUNT(*)
+	FROM pull_requests pr
+	INNER JOIN users u ON u.user_id = pr.author_id
+	WHERE pr.status = 'OPEN'
+	GROUP BY u.team_name
+	`
+
+	rows, err := sr.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	open := make(map[string]int)
+	for rows.Next() {
+		var (
+			team  string
+			count int
+		)
+		if err := rows.Scan(&team, &count); err != nil {
+			return nil, err
+		}
+		open[team] = count
+	}
+
+	return open, rows.Err()
+}
@@ -0,0 +1,142 @@
+// Package sqlite — хранилище в одном файле SQLite для тех, кому не нужен
+// отдельный сервер Postgres. Семантика та же, что у Postgres: обоих
+// проверяет общий набор repotest. Рассчитано на один процесс сервиса.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+
+	sqlite "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/repo"
+	"github.com/andro-kes/avito_test/internal/repo/db"
+	"github.com/andro-kes/avito_test/internal/tracing"
+)
+
+// Open открывает базу в файле path, создавая его при необходимости,
+// применяет миграции и возвращает хранилище.
+func Open(ctx context.Context, path string) (*repo.Storage, error) {
+	conn, err := sql.Open("sqlite", dsn(path))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrate(ctx, conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("apply migrations: %w", err)
+	}
+
+	return &repo.Storage{
+		Backend:     repo.BackendSQLite,
+		PRs:         &prRepo{DB: conn},
+		Teams:       &teamRepo{DB: conn},
+		Users:       &userRepo{DB: conn},
+		Stats:       &statsRepo{DB: conn},
+		Forge:       &forgeRepo{DB: conn},
+		Idempotency: &idempotencyRepo{DB: conn},
+		Tx:          &txRunner{DB: conn},
+		DB:          conn,
+	}, nil
+}
+
+// dsn включает внешние ключи и WAL, в котором чтение не ждёт пишущую
+// транзакцию. Транзакции начинаются с BEGIN IMMEDIATE: пишущие ждут друг
+// друга до busy_timeout, а не падают с SQLITE_BUSY посреди транзакции.
+func dsn(path string) string {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Add("_pragma", "busy_timeout(10000)")
+	params.Set("_txlock", "immediate")
+	return "file:" + path + "?" + params.Encode()
+}
+
+type txRunner struct {
+	DB *sql.DB
+}
+
+func (t *txRunner) RunInTx(ctx context.Context, fn func(ctx context.Context, q db.Querier) error) (err error) {
+	ctx, span := tracing.Start(ctx, "db.RunInTx")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := t.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	txCtx, runHooks := db.WithAfterCommit(ctx)
+	if err := fn(txCtx, txn{Tx: tx}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	runHooks()
+	return nil
+}
+
+// txn — транзакция SQLite, которую RunInTx передаёт как db.Querier.
+type txn struct {
+	*sql.Tx
+	db.TxMarker
+}
+
+// sqlTx возвращает q как транзакцию SQLite.
+func sqlTx(q db.Querier) (*sql.Tx, error) {
+	t, ok := q.(txn)
+	if !ok {
+		return nil, db.ErrForeignQuerier
+	}
+	return t.Tx, nil
+}
+
+// classify переводит ошибки SQLite в доменные так же, как db.Classify
+// для Postgres: нет строк и нарушение внешнего ключа — NOT_FOUND, нарушение
+// уникальности — CONFLICT, нарушение NOT NULL и CHECK — VALIDATION_ERROR.
+func classify(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var perr *prerrors.Error
+	if errors.As(err, &perr) {
+		return err
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return prerrors.Wrap(prerrors.ErrNotFound, err)
+	}
+
+	switch errorCode(err) {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		return prerrors.Wrap(prerrors.ErrConflict, err)
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return prerrors.Wrap(prerrors.ErrNotFound, err)
+	case sqlite3.SQLITE_CONSTRAINT_NOTNULL, sqlite3.SQLITE_CONSTRAINT_CHECK:
+		return prerrors.Wrap(prerrors.ErrValidation, err)
+	}
+	return err
+}
+
+// isUniqueViolation сообщает, что err — нарушение уникального ограничения.
+func isUniqueViolation(err error) bool {
+	code := errorCode(err)
+	return code == sqlite3.SQLITE_CONSTRAINT_UNIQUE || code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+}
+
+// errorCode возвращает расширенный код ошибки SQLite или 0.
+func errorCode(err error) int {
+	var serr *sqlite.Error
+	if !errors.As(err, &serr) {
+		return 0
+	}
+	return serr.Code()
+}
@@ -0,0 +1,150 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/repo/db"
+)
+
+type teamRepo struct {
+	DB *sql.DB
+}
+
+func (tr *teamRepo) CheckUnique(ctx context.Context, name string) error {
+	var exists bool
+	err := tr.DB.QueryRowContext(
+		ctx,
+		"SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = ?1)",
+		name,
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return prerrors.ErrTeamExists
+	}
+
+	return nil
+}
+
+func (tr *teamRepo) CreateTeam(ctx context.Context, q db.Querier, name string) error {
+	tx, err := sqlTx(q)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO teams (team_name) VALUES (?1)", name)
+	if isUniqueViolation(err) {
+		return prerrors.Wrap(prerrors.ErrTeamExists, err)
+	}
+	return err
+}
+
+func (tr *teamRepo) GetTeam(ctx context.Context, name string) (*models.Team, error) {
+	var teamName string
+	err := tr.DB.QueryRowContext(
+		ctx,
+		"SELECT team_name FROM teams WHERE team_name = ?1",
+		name,
+	).Scan(&teamName)
+	if err != nil {
+		return nil, classify(err)
+	}
+
+	rows, err := tr.DB.QueryContext(
+		ctx,
+		"SELECT user_id, username, is_active FROM users WHERE team_name = ?1 ORDER BY user_id",
+		name,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := make([]models.TeamMember, 0)
+	for rows.Next() {
+		var member models.TeamMember
+		if err := rows.Scan(&member.UserID, &member.Username, &member.IsActive); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &models.Team{
+		TeamName: teamName,
+		Members:  members,
+	}, nil
+}
+
+// EnsureTeam создаёт команду, если её ещё нет. Возвращает true, если команда создана.
+func (tr *teamRepo) EnsureTeam(ctx context.Context, q db.Querier, name string) (bool, error) {
+	tx, err := sqlTx(q)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := tx.ExecContext(
+		ctx,
+		"INSERT INTO teams (team_name) VALUES (?1) ON CONFLICT (team_name) DO NOTHING",
+		name,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// ListTeams возвращает все команды с участниками, упорядоченные по имени.
+func (tr *teamRepo) ListTeams(ctx context.Context, q db.Querier) ([]models.Team, error) {
+	tx, err := sqlTx(q)
+	if err != nil {
+		return nil, err
+	}
+
+	const query = `
+	SELECT t.team_name, u.user_id, u.username, u.is_active
+	FROM teams t
+	LEFT JOIN users u ON u.team_name = t.team_name
+	ORDER BY t.team_name, u.user_id
+	`
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams := make([]models.Team, 0)
+	for rows.Next() {
+		var teamName string
+		var userId, username *string
+		var isActive *bool
+		if err := rows.Scan(&teamName, &userId, &username, &isActive); err != nil {
+			return nil, err
+		}
+
+		if len(teams) == 0 || teams[len(teams)-1].TeamName != teamName {
+			teams = append(teams, models.Team{TeamName: teamName, Members: make([]models.TeamMember, 0)})
+		}
+		if userId == nil {
+			continue
+		}
+		team := &teams[len(teams)-1]
+		team.Members = append(team.Members, models.TeamMember{
+			UserID:   *userId,
+			Username: *username,
+			IsActive: *isActive,
+		})
+	}
+
+	return teams, rows.Err()
+}
@@ -0,0 +1,124 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/repo/db"
+)
+
+type userRepo struct {
+	DB *sql.DB
+}
+
+func (ur *userRepo) UpsertUser(ctx context.Context, q db.Querier, name string, m models.TeamMember) error {
+	tx, err := sqlTx(q)
+	if err != nil {
+		return err
+	}
+
+	const query = `
+	INSERT INTO users (user_id, username, team_name, is_active)
+	VALUES (?1, ?2, ?3, ?4)
+	ON CONFLICT (user_id) DO UPDATE
+	SET username = excluded.username,
+		team_name = excluded.team_name,
+		is_active = excluded.is_active
+	`
+
+	_, err = tx.ExecContext(ctx, query, m.UserID, m.Username, name, m.IsActive)
+	return classify(err)
+}
+
+func (ur *userRepo) SetIsActive(ctx context.Context, q db.Querier, userId string, isActive bool) error {
+	tx, err := sqlTx(q)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, "UPDATE users SET is_active = ?1 WHERE user_id = ?2", isActive, userId)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return prerrors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (ur *userRepo) GetUser(ctx context.Context, userId string) (*models.User, error) {
+	var user models.User
+	err := ur.DB.QueryRowContext(
+		ctx,
+		"SELECT user_id, username, team_name, is_active FROM users WHERE user_id = ?1",
+		userId,
+	).Scan(&user.UserId, &user.Username, &user.TeamName, &user.IsActive)
+	if err != nil {
+		return nil, classify(err)
+	}
+
+	return &user, nil
+}
+
+func (ur *userRepo) CountReview(ctx context.Context, userId string) (int, error) {
+	var exists bool
+	err := ur.DB.QueryRowContext(
+		ctx,
+		"SELECT EXISTS(SELECT 1 FROM users WHERE user_id = ?1)",
+		userId,
+	).Scan(&exists)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, prerrors.ErrNotFound
+	}
+
+	const query = `
+	SELECT COUNT(*)
+	FROM pull_requests pr
+	WHERE EXISTS (SELECT 1 FROM json_each(pr.assigned_reviewers) r WHERE r.value = ?1)
+	`
+
+	var cnt int
+	if err := ur.DB.QueryRowContext(ctx, query, userId).Scan(&cnt); err != nil {
+		return 0, err
+	}
+
+	return cnt, nil
+}
+
+func (ur *userRepo) DeactivateUsers(ctx context.Context, q db.Querier, userIds []string) error {
+	tx, err := sqlTx(q)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		"UPDATE users SET is_active = FALSE WHERE user_id IN (SELECT value FROM json_each(?1))",
+		jsonArray(userIds),
+	)
+	return err
+}
+
+func (ur *userRepo) FindExistingUsers(ctx context.Context, q db.Querier, userIds []string) (map[string]struct{}, error) {
+	tx, err := sqlTx(q)
+	if err != nil {
+		return nil, err
+	}
+	return existingIds(ctx, tx, "SELECT user_id FROM users WHERE user_id IN (SELECT value FROM json_each(?1))", userIds)
+}
+
+// LockTeammates ничего не блокирует: пишущие транзакции SQLite и так идут по одной.
+func (ur *userRepo) LockTeammates(ctx context.Context, q db.Querier, userIds []string) error {
+	_, err := sqlTx(q)
+	return err
+}
@@ -0,0 +1,97 @@
+package sqlite
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// timeLayout — формат колонок времени: UTC с микросекундами и постоянной
+// длиной, чтобы строки сравнивались в том же порядке, что и время.
+const timeLayout = "2006-01-02 15:04:05.000000"
+
+// now — текущее время с точностью колонки TIMESTAMP в Postgres.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func ts(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// nullTs — ts для необязательного времени; nil записывается как NULL.
+func nullTs(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return ts(*t)
+}
+
+// timeScanner читает колонку времени в *time.Time; NULL даёт nil.
+type timeScanner struct {
+	dst **time.Time
+}
+
+func scanTime(dst **time.Time) timeScanner {
+	return timeScanner{dst: dst}
+}
+
+func (s timeScanner) Scan(v any) error {
+	var text string
+	switch v := v.(type) {
+	case nil:
+		*s.dst = nil
+		return nil
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return fmt.Errorf("unexpected time value %T", v)
+	}
+
+	t, err := time.Parse(timeLayout, text)
+	if err != nil {
+		return err
+	}
+	*s.dst = &t
+	return nil
+}
+
+// jsonArray кодирует ids в JSON-массив — замену массивам Postgres
+// в колонках и параметрах запросов (через json_each).
+func jsonArray(ids []string) string {
+	if ids == nil {
+		ids = []string{}
+	}
+	b, _ := json.Marshal(ids)
+	return string(b)
+}
+
+// arrayScanner читает JSON-массив строк; пустой массив даёт пустой срез, а не nil.
+type arrayScanner struct {
+	dst *[]string
+}
+
+func scanArray(dst *[]string) arrayScanner {
+	return arrayScanner{dst: dst}
+}
+
+func (s arrayScanner) Scan(v any) error {
+	var data []byte
+	switch v := v.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("unexpected array value %T", v)
+	}
+
+	ids := make([]string, 0)
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return err
+	}
+	*s.dst = ids
+	return nil
+}
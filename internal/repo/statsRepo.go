@@ -0,0 +1,224 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/andro-kes/avito_test/internal/models"
+)
+
+type statsRepo struct {
+	Pool *pgxpool.Pool
+}
+
+func NewStatsRepo(pool *pgxpool.Pool) StatsRepo {
+	return &statsRepo{
+		Pool: pool,
+	}
+}
+
+// reviewAggregates — агрегаты по строкам pr_reviewers rv за окно [$1, $2)
+// и медиана из firstReview.
+const reviewAggregates = `
+	COUNT(rv.id) FILTER (WHERE rv.assigned_at >= $1 AND rv.assigned_at < $2),
+	COUNT(rv.id) FILTER (WHERE rv.decided_at >= $1 AND rv.decided_at < $2),
+	MAX(fr.median),
+	COUNT(rv.id) FILTER (WHERE rv.unassigned_at >= $1 AND rv.unassigned_at < $2)`
+
+// firstReview — медиана времени до первого ревью по группе key (пользователь
+// или команда): для каждого PR берётся первое решение ревьюверов группы за всё
+// время и вычитается время создания PR. Учитываются PR, первое решение по
+// которым попало в окно [$1, $2).
+func firstReview(key string) string {
+	return `
+	LEFT JOIN (
+		SELECT f.grp, percentile_cont(0.5) WITHIN GROUP (ORDER BY f.seconds) AS median
+		FROM (
+			SELECT ` + key + ` AS grp, EXTRACT(EPOCH FROM MIN(rv.decided_at) - pr.created_at)::float8 AS seconds
+			FROM pr_reviewers rv
+			INNER JOIN users u ON u.user_id = rv.user_id
+			INNER JOIN pull_requests pr ON pr.pull_request_id = rv.pull_request_id
+			WHERE rv.decided_at IS NOT NULL
+			GROUP BY 1, rv.pull_request_id, pr.created_at
+			HAVING MIN(rv.decided_at) >= $1 AND MIN(rv.decided_at) < $2
+		) f
+		GROUP BY f.grp
+	) fr ON fr.grp = ` + key
+}
+
+// reviewWindowJoin оставляет только назначения, затронутые окном.
+const reviewWindowJoin = `
+	LEFT JOIN pr_reviewers rv ON rv.user_id = u.user_id
+		AND rv.assigned_at < $2
+		AND (rv.assigned_at >= $1 OR rv.decided_at >= $1 OR rv.unassigned_at >= $1)`
+
+// openLoad — число открытых PR, где пользователь сейчас ревьювер.
+const openLoad = `
+	LEFT JOIN (
+		SELECT r.user_id, COUNT(*) AS open_load
+		FROM pull_requests pr
+		CROSS JOIN LATERAL unnest(pr.assigned_reviewers) AS r(user_id)
+		WHERE pr.status = 'OPEN'
+		GROUP BY r.user_id
+	) l ON l.user_id = u.user_id`
+
+func (sr *statsRepo) ReviewerStats(ctx context.Context, w models.StatsWindow, teamName string) ([]models.ReviewerStats, error) {
+	sql := `
+	SELECT u.user_id, u.username, u.team_name, u.is_active,` + reviewAggregates + `,
+	COALESCE(MAX(l.open_load), 0)
+	FROM users u` + reviewWindowJoin + openLoad + firstReview("u.user_id") + `
+	WHERE $3 = '' OR u.team_name = $3
+	GROUP BY u.user_id
+	ORDER BY 6 DESC, 5 DESC, u.user_id
+	`
+
+	rows, err := sr.Pool.Query(ctx, sql, w.From, w.To, teamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]models.ReviewerStats, 0)
+	for rows.Next() {
+		var s models.ReviewerStats
+		if err := rows.Scan(
+			&s.UserId, &s.Username, &s.TeamName, &s.IsActive,
+			&s.AssignmentsReceived, &s.ReviewsCompleted, &s.MedianTimeToFirstReview, &s.ReassignedAway,
+			&s.OpenLoad,
+		); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, rows.Err()
+}
+
+func (sr *statsRepo) TeamReviewStats(ctx context.Context, w models.StatsWindow, teamName string) ([]models.TeamReviewStats, error) {
+	sql := `
+	SELECT u.team_name,` + reviewAggregates + `
+	FROM users u` + reviewWindowJoin + firstReview("u.team_name") + `
+	WHERE $3 = '' OR u.team_name = $3
+	GROUP BY u.team_name
+	ORDER BY u.team_name
+	`
+
+	rows, err := sr.Pool.Query(ctx, sql, w.From, w.To, teamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]models.TeamReviewStats, 0)
+	for rows.Next() {
+		var s models.TeamReviewStats
+		if err := rows.Scan(
+			&s.TeamName,
+			&s.AssignmentsReceived, &s.ReviewsCompleted, &s.MedianTimeToFirstReview, &s.ReassignedAway,
+		); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, rows.Err()
+}
+
+// PRThroughput группирует открытия, мержи и закрытия PR по команде автора и неделе.
+// Время в БД хранится в UTC, недели отсчитываются в часовом поясе tz.
+func (sr *statsRepo) PRThroughput(ctx context.Context, w models.StatsWindow, tz, teamName string) ([]models.PRThroughput, error) {
+	const sql = `
+	WITH prs AS (
+		SELECT u.team_name, pr.created_at, pr.merged_at, pr.closed_at,
+		cardinality(pr.assigned_reviewers) AS reviewers
+		FROM pull_requests pr
+		INNER JOIN users u ON u.user_id = pr.author_id
+		WHERE $4 = '' OR u.team_name = $4
+	), events AS (
+		SELECT team_name, 'opened' AS kind, created_at AS at, NULL::float8 AS merge_seconds, reviewers
+		FROM prs
+		UNION ALL
+		SELECT team_name, 'merged', merged_at, EXTRACT(EPOCH FROM merged_at - created_at)::float8, NULL
+		FROM prs WHERE merged_at IS NOT NULL
+		UNION ALL
+		SELECT team_name, 'closed', closed_at, NULL, NULL
+		FROM prs WHERE closed_at IS NOT NULL
+	)
+	SELECT
+	team_name,
+	date_trunc('week', at AT TIME ZONE 'UTC' AT TIME ZONE $3)::date AS week,
+	COUNT(*) FILTER (WHERE kind = 'opened'),
+	COUNT(*) FILTER (WHERE kind = 'merged'),
+	COUNT(*) FILTER (WHERE kind = 'closed'),
+	percentile_cont(0.5) WITHIN GROUP (ORDER BY merge_seconds) FILTER (WHERE kind = 'merged'),
+	percentile_cont(0.9) WITHIN GROUP (ORDER BY merge_seconds) FILTER (WHERE kind = 'merged'),
+	array_agg(reviewers) FILTER (WHERE kind = 'opened')
+	FROM events
+	WHERE at >= $1 AND at < $2
+	GROUP BY 1, 2
+	ORDER BY 1, 2
+	`
+
+	rows, err := sr.Pool.Query(ctx, sql, w.From, w.To, tz, teamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]models.PRThroughput, 0)
+	for rows.Next() {
+		var (
+			s         models.PRThroughput
+			week      time.Time
+			reviewers []int
+		)
+		if err := rows.Scan(
+			&s.TeamName, &week, &s.Opened, &s.Merged, &s.Closed,
+			&s.MedianOpenToMerge, &s.P90OpenToMerge, &reviewers,
+		); err != nil {
+			return nil, err
+		}
+
+		s.Week = week.Format(time.DateOnly)
+		s.ReviewerCounts = make(map[int]int)
+		for _, n := range reviewers {
+			s.ReviewerCounts[n]++
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, rows.Err()
+}
+
+// OpenPRsByTeam считает открытые PR по команде автора.
+func (sr *statsRepo) OpenPRsByTeam(ctx context.Context) (map[string]int, error) {
+	const sql = `
+	SELECT u.team_name, COUNT(*)
+	FROM pull_requests pr
+	INNER JOIN users u ON u.user_id = pr.author_id
+	WHERE pr.status = 'OPEN'
+	GROUP BY u.team_name
+	`
+
+	rows, err := sr.Pool.Query(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	open := make(map[string]int)
+	for rows.Next() {
+		var (
+			team  string
+			count int
+		)
+		if err := rows.Scan(&team, &count); err != nil {
+			return nil, err
+		}
+		open[team] = count
+	}
+
+	return open, rows.Err()
+}
@@ -0,0 +1,59 @@
+package repo
+
+import (
+	"database/sql"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/andro-kes/avito_test/internal/repo/db"
+)
+
+// Хранилища, из которых выбирает параметр storage.backend.
+const (
+	BackendPostgres = "postgres"
+	BackendMemory   = "memory"
+	BackendSQLite   = "sqlite"
+)
+
+// Storage — репозитории и транзакции одного хранилища. Сервисы получают
+// всё из него и не знают, с чем работают.
+type Storage struct {
+	Backend string
+
+	PRs         PRRepo
+	Teams       TeamRepo
+	Users       UserRepo
+	Stats       StatsRepo
+	Forge       ForgeRepo
+	Idempotency IdempotencyRepo
+	Tx          db.Tx
+
+	// Pool — пул Postgres для проверок здоровья и метрик; nil у других хранилищ.
+	Pool *pgxpool.Pool
+	// DB — база SQLite; nil у других хранилищ.
+	DB *sql.DB
+}
+
+// Close закрывает соединения хранилища.
+func (s *Storage) Close() {
+	if s.Pool != nil {
+		s.Pool.Close()
+	}
+	if s.DB != nil {
+		s.DB.Close()
+	}
+}
+
+func NewPostgresStorage(pool *pgxpool.Pool) *Storage {
+	return &Storage{
+		Backend:     BackendPostgres,
+		PRs:         NewPRRepo(pool),
+		Teams:       NewTeamRepo(pool),
+		Users:       NewUserRepo(pool),
+		Stats:       NewStatsRepo(pool),
+		Forge:       NewForgeRepo(pool),
+		Idempotency: NewIdempotencyRepo(pool),
+		Tx:          db.NewTx(pool),
+		Pool:        pool,
+	}
+}
@@ -28,7 +28,7 @@ func (tr *teamRepo) CheckUnique(ctx context.Context, name string) error {
 		name,
 	).Scan(&exists)
 	if err != nil {
-		return prerrors.ErrServer
+		return err
 	}
 
 	if exists {
@@ -39,11 +39,19 @@ func (tr *teamRepo) CheckUnique(ctx context.Context, name string) error {
 }
 
 func (tr *teamRepo) CreateTeam(ctx context.Context, q db.Querier, name string) error {
-	_, err := q.Exec(
+	pq, err := db.Pgx(q)
+	if err != nil {
+		return err
+	}
+
+	_, err = pq.Exec(
 		ctx,
 		"INSERT INTO teams (team_name) VALUES ($1)",
 		name,
 	)
+	if db.IsUniqueViolation(err) {
+		return prerrors.Wrap(prerrors.ErrTeamExists, err)
+	}
 	return err
 }
 
@@ -55,7 +63,7 @@ func (tr *teamRepo) GetTeam(ctx context.Context, name string) (*models.Team, err
 		name,
 	).Scan(&teamName)
 	if err != nil {
-		return nil, prerrors.ErrNotFound
+		return nil, db.Classify(err)
 	}
 
 	rows, err := tr.Pool.Query(
@@ -86,3 +94,67 @@ func (tr *teamRepo) GetTeam(ctx context.Context, name string) (*models.Team, err
 		Members:  members,
 	}, nil
 }
+
+// EnsureTeam создаёт команду, если её ещё нет. Возвращает true, если команда создана.
+func (tr *teamRepo) EnsureTeam(ctx context.Context, q db.Querier, name string) (bool, error) {
+	pq, err := db.Pgx(q)
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := pq.Exec(
+		ctx,
+		"INSERT INTO teams (team_name) VALUES ($1) ON CONFLICT (team_name) DO NOTHING",
+		name,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ListTeams возвращает все команды с участниками, упорядоченные по имени.
+func (tr *teamRepo) ListTeams(ctx context.Context, q db.Querier) ([]models.Team, error) {
+	pq, err := db.Pgx(q)
+	if err != nil {
+		return nil, err
+	}
+
+	const sql = `
+	SELECT t.team_name, u.user_id, u.username, u.is_active
+	FROM teams t
+	LEFT JOIN users u ON u.team_name = t.team_name
+	ORDER BY t.team_name, u.user_id
+	`
+
+	rows, err := pq.Query(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams := make([]models.Team, 0)
+	for rows.Next() {
+		var teamName string
+		var userId, username *string
+		var isActive *bool
+		if err := rows.Scan(&teamName, &userId, &username, &isActive); err != nil {
+			return nil, err
+		}
+
+		if len(teams) == 0 || teams[len(teams)-1].TeamName != teamName {
+			teams = append(teams, models.Team{TeamName: teamName, Members: make([]models.TeamMember, 0)})
+		}
+		if userId == nil {
+			continue
+		}
+		team := &teams[len(teams)-1]
+		team.Members = append(team.Members, models.TeamMember{
+			UserID:   *userId,
+			Username: *username,
+			IsActive: *isActive,
+		})
+	}
+
+	return teams, rows.Err()
+}
@@ -21,6 +21,11 @@ func NewUserRepo(pool *pgxpool.Pool) UserRepo {
 }
 
 func (ur *userRepo) UpsertUser(ctx context.Context, q db.Querier, name string, m models.TeamMember) error {
+	pq, err := db.Pgx(q)
+	if err != nil {
+		return err
+	}
+
 	sql := `
 	INSERT INTO users (user_id, username, team_name, is_active)
 	VALUES ($1,$2,$3,$4)
@@ -30,29 +35,40 @@ func (ur *userRepo) UpsertUser(ctx context.Context, q db.Querier, name string, m
 		is_active = EXCLUDED.is_active;
 	`
 
-	_, err := q.Exec(
+	_, err = pq.Exec(
 		ctx,
 		sql,
 		m.UserID, m.Username, name, m.IsActive,
 	)
 
-	return err
+	return db.Classify(err)
 }
 
 func (ur *userRepo) SetIsActive(ctx context.Context, q db.Querier, userId string, isActive bool) error {
+	pq, err := db.Pgx(q)
+	if err != nil {
+		return err
+	}
+
 	sql := `
 	UPDATE users
     SET is_active = $1
     WHERE user_id = $2
 	`
 
-	_, err := q.Exec(
+	tag, err := pq.Exec(
 		ctx,
 		sql,
 		isActive, userId,
 	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return prerrors.ErrNotFound
+	}
 
-	return err
+	return nil
 }
 
 func (ur *userRepo) GetUser(ctx context.Context, userId string) (*models.User, error) {
@@ -62,8 +78,11 @@ func (ur *userRepo) GetUser(ctx context.Context, userId string) (*models.User, e
 		"SELECT user_id, username, team_name, is_active FROM users WHERE user_id=$1",
 		userId,
 	).Scan(&user.UserId, &user.Username, &user.TeamName, &user.IsActive)
+	if err != nil {
+		return nil, db.Classify(err)
+	}
 
-	return &user, err
+	return &user, nil
 }
 
 func (ur *userRepo) CountReview(ctx context.Context, userId string) (int, error) {
@@ -100,7 +119,12 @@ func (ur *userRepo) CountReview(ctx context.Context, userId string) (int, error)
 }
 
 func (ur *userRepo) DeactivateUsers(ctx context.Context, q db.Querier, userIds []string) error {
-	_, err := q.Exec(
+	pq, err := db.Pgx(q)
+	if err != nil {
+		return err
+	}
+
+	_, err = pq.Exec(
 		ctx,
 		"UPDATE users SET is_active = false WHERE user_id = ANY($1)",
 		userIds,
@@ -108,3 +132,33 @@ func (ur *userRepo) DeactivateUsers(ctx context.Context, q db.Querier, userIds [
 
 	return err
 }
+
+func (ur *userRepo) FindExistingUsers(ctx context.Context, q db.Querier, userIds []string) (map[string]struct{}, error) {
+	pq, err := db.Pgx(q)
+	if err != nil {
+		return nil, err
+	}
+
+	return existingIds(ctx, pq, "SELECT user_id FROM users WHERE user_id = ANY($1)", userIds)
+}
+
+// LockTeammates блокирует до конца транзакции всех участников команд
+// пользователей userIds по порядку user_id — среди них и кандидаты на замену.
+// Вызывается до блокировки PR.
+func (ur *userRepo) LockTeammates(ctx context.Context, q db.Querier, userIds []string) error {
+	pq, err := db.Pgx(q)
+	if err != nil {
+		return err
+	}
+
+	const sql = `
+	SELECT user_id
+	FROM users
+	WHERE team_name IN (SELECT team_name FROM users WHERE user_id = ANY($1))
+	ORDER BY user_id
+	FOR NO KEY UPDATE
+	`
+
+	_, err = pq.Exec(ctx, sql, userIds)
+	return err
+}
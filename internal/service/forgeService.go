@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/repo"
+	"github.com/andro-kes/avito_test/internal/repo/db"
+)
+
+// Результаты обработки события форджа.
+const (
+	ForgeEventProcessed = "processed"
+	ForgeEventDuplicate = "duplicate"
+	ForgeEventIgnored   = "ignored"
+)
+
+// ForgeService применяет события любых форджей к PR сервиса:
+// сопоставляет логины пользователям и отбрасывает повторные доставки.
+type ForgeService struct {
+	Repo      repo.ForgeRepo
+	PRService *PRService
+	UserRepo  repo.UserRepo
+	Tx        db.Tx
+}
+
+func NewForgeService(st *repo.Storage, prService *PRService) *ForgeService {
+	return &ForgeService{
+		Repo:      st.Forge,
+		PRService: prService,
+		UserRepo:  st.Users,
+		Tx:        st.Tx,
+	}
+}
+
+// ForgePullRequestId — идентификатор PR сервиса для merge/pull request форджа.
+func ForgePullRequestId(forge, project string, number int64) string {
+	return fmt.Sprintf("%s:%s!%d", forge, project, number)
+}
+
+// HandleEvent применяет событие в одной транзакции вместе с отметкой
+// доставки: если событие не применилось, откат снимает и отметку,
+// и повторная доставка будет обработана.
+func (fs *ForgeService) HandleEvent(ctx context.Context, ev *models.ForgeEvent) (string, error) {
+	var status string
+	err := fs.Tx.RunInTx(ctx, func(ctx context.Context, q db.Querier) error {
+		if ev.DeliveryID != "" {
+			fresh, err := fs.Repo.ClaimDelivery(ctx, q, ev.Forge, ev.DeliveryID)
+			if err != nil {
+				return err
+			}
+			if !fresh {
+				status = ForgeEventDuplicate
+				return nil
+			}
+		}
+
+		var err error
+		status, err = fs.apply(ctx, q, ev)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return status, nil
+}
+
+func (fs *ForgeService) apply(ctx context.Context, q db.Querier, ev *models.ForgeEvent) (string, error) {
+	prId := ForgePullRequestId(ev.Forge, ev.Project, ev.Number)
+	switch ev.Action {
+	case models.ForgeActionOpen, models.ForgeActionReopen:
+		return fs.open(ctx, q, prId, ev)
+	case models.ForgeActionClose, models.ForgeActionMerge, models.ForgeActionDraft:
+	default:
+		return ForgeEventIgnored, nil
+	}
+
+	prRepo := fs.PRService.Repo
+	exists, err := prRepo.CheckExistingPR(ctx, q, prId)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return ForgeEventIgnored, nil
+	}
+
+	switch ev.Action {
+	case models.ForgeActionClose:
+		_, err = prRepo.ClosePR(ctx, q, prId)
+	case models.ForgeActionMerge:
+		_, err = prRepo.MergePR(ctx, q, prId)
+	case models.ForgeActionDraft:
+		_, err = prRepo.SetDraft(ctx, q, prId, ev.IsDraft)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return ForgeEventProcessed, nil
+}
+
+// open создаёт PR форджа или открывает существующий и связывает его с
+// форджем. Повторная доставка того же события не падает с PR_EXISTS,
+// а восстанавливает связь, если её нет. Уже смерженный PR не открывается:
+// событие игнорируется, иначе фордж повторял бы доставку бесконечно.
+// Уведомление о ревьюверах уходит после коммита, когда связь уже видна.
+func (fs *ForgeService) open(ctx context.Context, q db.Querier, prId string, ev *models.ForgeEvent) (string, error) {
+	link := models.ForgePullRequest{
+		PullRequestId: prId,
+		Forge:         ev.Forge,
+		Project:       ev.Project,
+		Number:        ev.Number,
+	}
+
+	ps := fs.PRService
+	exists, err := ps.Repo.CheckExistingPR(ctx, q, prId)
+	if err != nil {
+		return "", err
+	}
+
+	if exists {
+		_, err := ps.Repo.ReopenPR(ctx, q, prId)
+		if errors.Is(err, prerrors.ErrPRMerged) {
+			return ForgeEventIgnored, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if err := fs.Repo.LinkPR(ctx, q, link); err != nil {
+			return "", err
+		}
+		return ForgeEventProcessed, nil
+	}
+
+	authorId, err := fs.Repo.ResolveUser(ctx, ev.Forge, ev.AuthorLogin)
+	if err != nil {
+		return "", err
+	}
+	pr := &models.PullRequestShort{
+		PullRequestId:   prId,
+		PullRequestName: ev.Title,
+		AuthorId:        authorId,
+	}
+	if _, err := ps.createPR(ctx, q, pr, ev.TeamName); err != nil {
+		return "", err
+	}
+	if ev.IsDraft {
+		if _, err := ps.Repo.SetDraft(ctx, q, prId, true); err != nil {
+			return "", err
+		}
+	}
+	if err := fs.Repo.LinkPR(ctx, q, link); err != nil {
+		return "", err
+	}
+	return ForgeEventProcessed, nil
+}
+
+func (fs *ForgeService) MapIdentity(ctx context.Context, identity models.ForgeIdentity) error {
+	if _, err := fs.UserRepo.GetUser(ctx, identity.UserId); err != nil {
+		return err
+	}
+
+	return fs.Repo.UpsertIdentity(ctx, identity)
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/andro-kes/avito_test/internal/migrations"
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/repo"
+)
+
+const healthCheckTimeout = 2 * time.Second
+
+type HealthService struct {
+	// Pool — пул Postgres; nil, если хранилище без него и проверять нечего.
+	Pool *pgxpool.Pool
+	// DB — база SQLite; nil у других хранилищ.
+	DB      *sql.DB
+	Backend string
+
+	draining atomic.Bool
+}
+
+func NewHealthService(st *repo.Storage) *HealthService {
+	return &HealthService{
+		Pool:    st.Pool,
+		DB:      st.DB,
+		Backend: st.Backend,
+	}
+}
+
+// SetDraining помечает сервис как останавливающийся: readiness отвечает down,
+// чтобы балансировщик перестал присылать новые запросы.
+func (hs *HealthService) SetDraining() {
+	hs.draining.Store(true)
+}
+
+func (hs *HealthService) Readiness(ctx context.Context) *models.HealthReport {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	report := &models.HealthReport{
+		Status: models.HealthUp,
+		Components: map[string]models.ComponentHealth{
+			"database":   hs.checkDatabase(ctx),
+			"migrations": hs.checkMigrations(ctx),
+			"draining":   hs.checkDraining(),
+		},
+	}
+	for _, c := range report.Components {
+		if c.Status != models.HealthUp {
+			report.Status = models.HealthDown
+		}
+	}
+
+	return report
+}
+
+func (hs *HealthService) checkDatabase(ctx context.Context) models.ComponentHealth {
+	if hs.Pool == nil && hs.DB != nil {
+		return hs.checkSQLite(ctx)
+	}
+	if hs.Pool == nil {
+		return models.ComponentHealth{Status: models.HealthUp, Details: map[string]string{"backend": hs.Backend}}
+	}
+	if err := hs.Pool.Ping(ctx); err != nil {
+		return models.ComponentHealth{Status: models.HealthDown, Error: err.Error()}
+	}
+
+	s := hs.Pool.Stat()
+	return models.ComponentHealth{
+		Status: models.HealthUp,
+		Details: map[string]int32{
+			"total_connections":    s.TotalConns(),
+			"acquired_connections": s.AcquiredConns(),
+			"max_connections":      s.MaxConns(),
+		},
+	}
+}
+
+func (hs *HealthService) checkSQLite(ctx context.Context) models.ComponentHealth {
+	if err := hs.DB.PingContext(ctx); err != nil {
+		return models.ComponentHealth{Status: models.HealthDown, Error: err.Error()}
+	}
+
+	s := hs.DB.Stats()
+	return models.ComponentHealth{
+		Status: models.HealthUp,
+		Details: map[string]any{
+			"backend":            hs.Backend,
+			"open_connections":   s.OpenConnections,
+			"in_use_connections": s.InUse,
+			"max_connections":    s.MaxOpenConnections,
+		},
+	}
+}
+
+func (hs *HealthService) checkMigrations(ctx context.Context) models.ComponentHealth {
+	if hs.Pool == nil {
+		return models.ComponentHealth{Status: models.HealthUp}
+	}
+	status, err := migrations.GetStatus(ctx, hs.Pool)
+	if err != nil {
+		return models.ComponentHealth{Status: models.HealthDown, Error: err.Error()}
+	}
+	if len(status.Modified) > 0 {
+		return models.ComponentHealth{
+			Status:  models.HealthDown,
+			Error:   fmt.Sprintf("%d migrations modified after they were applied", len(status.Modified)),
+			Details: status,
+		}
+	}
+	if len(status.Pending) > 0 {
+		return models.ComponentHealth{
+			Status:  models.HealthDown,
+			Error:   fmt.Sprintf("%d pending migrations", len(status.Pending)),
+			Details: status,
+		}
+	}
+
+	return models.ComponentHealth{Status: models.HealthUp, Details: status}
+}
+
+func (hs *HealthService) checkDraining() models.ComponentHealth {
+	if hs.draining.Load() {
+		return models.ComponentHealth{Status: models.HealthDown, Error: "server is shutting down"}
+	}
+	return models.ComponentHealth{Status: models.HealthUp}
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/repo"
+)
+
+type IdempotencyService struct {
+	Repo repo.IdempotencyRepo
+	TTL  time.Duration
+	// Wait — сколько ждать завершения параллельного запроса с тем же ключом.
+	Wait time.Duration
+}
+
+func NewIdempotencyService(st *repo.Storage, ttl time.Duration) *IdempotencyService {
+	return &IdempotencyService{
+		Repo: st.Idempotency,
+		TTL:  ttl,
+		Wait: 5 * time.Second,
+	}
+}
+
+// Fingerprint однозначно описывает запрос: маршрут и тело.
+func Fingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ScopedKey привязывает ключ к вызывающему, чтобы одинаковые ключи разных
+// клиентов не пересекались. В хранилище попадает только хеш.
+func ScopedKey(caller, key string) string {
+	h := sha256.New()
+	h.Write([]byte(caller))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Begin занимает ключ. Возвращает nil, если запрос нужно выполнить,
+// или сохранённый ответ, если запрос с этим ключом уже завершился.
+func (is *IdempotencyService) Begin(ctx context.Context, key, fingerprint string) (*models.IdempotencyRecord, error) {
+	deadline := time.Now().Add(is.Wait)
+	for {
+		reserved, err := is.Repo.Reserve(ctx, key, fingerprint, is.TTL)
+		if err != nil {
+			return nil, err
+		}
+		if reserved {
+			return nil, nil
+		}
+
+		rec, err := is.Repo.Get(ctx, key)
+		if err != nil {
+			// Запись могли удалить между Reserve и Get — пробуем снова.
+			if errors.Is(err, prerrors.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		if rec.Fingerprint != fingerprint {
+			return nil, prerrors.ErrIdempotencyKeyReused
+		}
+		if rec.StatusCode != 0 {
+			return rec, nil
+		}
+
+		if time.Now().After(deadline) {
+			return nil, prerrors.ErrIdempotencyInProgress
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+func (is *IdempotencyService) Complete(ctx context.Context, key string, statusCode int, header http.Header, body []byte) error {
+	return is.Repo.Complete(ctx, key, statusCode, header, body)
+}
+
+// Abort освобождает ключ, чтобы клиент мог повторить запрос.
+func (is *IdempotencyService) Abort(ctx context.Context, key string) error {
+	return is.Repo.Release(ctx, key)
+}
+
+func (is *IdempotencyService) PurgeExpired(ctx context.Context) (int64, error) {
+	return is.Repo.PurgeExpired(ctx)
+}
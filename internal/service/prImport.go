@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/repo/db"
+	"github.com/andro-kes/avito_test/internal/tracing"
+)
+
+// PRImportRow — строка пакета импорта; Err — ошибка её разбора.
+type PRImportRow struct {
+	PR  models.PullRequest
+	Err error
+}
+
+// ImportPRs загружает уже существующие PR вместе с назначенными ревьюверами.
+// Невалидные строки попадают в отчёт и не мешают импорту остальных.
+func (ps *PRService) ImportPRs(ctx context.Context, rows []PRImportRow) ([]models.PRImportResult, error) {
+	ctx, span := tracing.Start(ctx, "PRService.ImportPRs")
+	defer span.End()
+
+	results := make([]models.PRImportResult, len(rows))
+	fail := func(i int, err *prerrors.Error) {
+		results[i].Status = models.PRImportFailed
+		results[i].Code = err.Code
+		results[i].Message = err.Message
+	}
+
+	now := time.Now().UTC()
+	seen := make(map[string]struct{}, len(rows))
+	valid := make([]int, 0, len(rows))
+	for i := range rows {
+		pr := &rows[i].PR
+		results[i] = models.PRImportResult{
+			Index:         i,
+			PullRequestId: pr.PullRequestId,
+			Status:        models.PRImportImported,
+		}
+
+		if rows[i].Err != nil {
+			fail(i, invalidPR("malformed row: %v", rows[i].Err))
+			continue
+		}
+		if err := normalizeImportedPR(pr, now); err != nil {
+			fail(i, err)
+			continue
+		}
+		if _, dup := seen[pr.PullRequestId]; dup {
+			fail(i, prerrors.New(prerrors.ErrPRExists.Code, "duplicate pull_request_id in batch"))
+			continue
+		}
+		seen[pr.PullRequestId] = struct{}{}
+		valid = append(valid, i)
+	}
+
+	// Отказы внутри транзакции применяются к results только после коммита
+	var rejected map[int]*prerrors.Error
+	err := ps.Tx.RunInTx(ctx, func(ctx context.Context, q db.Querier) error {
+		rejected = make(map[int]*prerrors.Error)
+		prIds := make([]string, 0, len(valid))
+		userIds := make([]string, 0, len(valid))
+		for _, i := range valid {
+			pr := rows[i].PR
+			prIds = append(prIds, pr.PullRequestId)
+			userIds = append(userIds, pr.AuthorId)
+			userIds = append(userIds, pr.AssignedReviewers...)
+		}
+
+		existingPRs, err := ps.Repo.FindExistingPRs(ctx, q, prIds)
+		if err != nil {
+			return err
+		}
+		existingUsers, err := ps.UserRepo.FindExistingUsers(ctx, q, userIds)
+		if err != nil {
+			return err
+		}
+
+		toImport := make([]models.PullRequest, 0, len(valid))
+		for _, i := range valid {
+			pr := rows[i].PR
+			if _, ok := existingPRs[pr.PullRequestId]; ok {
+				rejected[i] = prerrors.ErrPRExists
+				continue
+			}
+			if _, ok := existingUsers[pr.AuthorId]; !ok {
+				rejected[i] = prerrors.New(prerrors.ErrNotFound.Code, fmt.Sprintf("author %s not found", pr.AuthorId))
+				continue
+			}
+			if missing := firstMissing(pr.AssignedReviewers, existingUsers); missing != "" {
+				rejected[i] = prerrors.New(prerrors.ErrNotFound.Code, fmt.Sprintf("reviewer %s not found", missing))
+				continue
+			}
+			toImport = append(toImport, pr)
+		}
+
+		if len(toImport) == 0 {
+			return nil
+		}
+		_, err = ps.Repo.ImportPRs(ctx, q, toImport)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, err := range rejected {
+		fail(i, err)
+	}
+
+	return results, nil
+}
+
+func normalizeImportedPR(pr *models.PullRequest, now time.Time) *prerrors.Error {
+	if pr.PullRequestId == "" || pr.PullRequestName == "" || pr.AuthorId == "" {
+		return invalidPR("pull_request_id, pull_request_name and author_id are required")
+	}
+
+	if pr.Status == "" {
+		pr.Status = "OPEN"
+	}
+	switch pr.Status {
+	case "OPEN", "CLOSED":
+		if pr.MergedAt != nil {
+			return invalidPR("merged_at is set for %s pull request", pr.Status)
+		}
+	case "MERGED":
+		if pr.MergedAt == nil {
+			pr.MergedAt = &now
+		}
+	default:
+		return invalidPR("unknown status %q", pr.Status)
+	}
+
+	// pgx пишет в TIMESTAMP время по часам исходного смещения, поэтому храним UTC
+	if pr.CreatedAt == nil {
+		pr.CreatedAt = &now
+	} else {
+		createdAt := pr.CreatedAt.UTC()
+		pr.CreatedAt = &createdAt
+	}
+	if pr.MergedAt != nil {
+		mergedAt := pr.MergedAt.UTC()
+		pr.MergedAt = &mergedAt
+	}
+	if pr.MergedAt != nil && pr.MergedAt.Before(*pr.CreatedAt) {
+		return invalidPR("merged_at is before created_at")
+	}
+
+	if pr.AssignedReviewers == nil {
+		pr.AssignedReviewers = make([]string, 0)
+	}
+	reviewers := make(map[string]struct{}, len(pr.AssignedReviewers))
+	for _, r := range pr.AssignedReviewers {
+		if r == pr.AuthorId {
+			return invalidPR("author %s cannot review own pull request", r)
+		}
+		if _, dup := reviewers[r]; dup {
+			return invalidPR("reviewer %s is assigned twice", r)
+		}
+		reviewers[r] = struct{}{}
+	}
+
+	return nil
+}
+
+func invalidPR(format string, args ...any) *prerrors.Error {
+	return prerrors.New(prerrors.ErrInvalidPR.Code, fmt.Sprintf(format, args...))
+}
+
+func firstMissing(ids []string, existing map[string]struct{}) string {
+	for _, id := range ids {
+		if _, ok := existing[id]; !ok {
+			return id
+		}
+	}
+	return ""
+}
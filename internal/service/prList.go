@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/tracing"
+)
+
+const (
+	defaultPRListLimit = 50
+	maxPRListLimit     = 200
+)
+
+// ListPRs возвращает страницу PR по фильтру. cursor — next_cursor предыдущей
+// страницы; он действителен только с той же сортировкой.
+func (ps *PRService) ListPRs(ctx context.Context, f models.PRListFilter, cursor string) (*models.PRListPage, error) {
+	ctx, span := tracing.Start(ctx, "PRService.ListPRs")
+	defer span.End()
+
+	if f.Sort == "" {
+		f.Sort = models.PRSortCreatedAt
+	}
+	if f.Limit == 0 {
+		f.Limit = defaultPRListLimit
+	}
+	if err := validatePRListFilter(&f); err != nil {
+		return nil, err
+	}
+
+	return ps.listPage(ctx, f, cursor)
+}
+
+// GetReview возвращает PR, где пользователь назначен ревьювером, по возрастанию
+// created_at. Без limit и cursor отдаёт весь список, как раньше.
+func (ps *PRService) GetReview(ctx context.Context, userId string, rq models.ReviewQuery) (*models.ReviewPage, error) {
+	ctx, span := tracing.Start(ctx, "PRService.GetReview")
+	defer span.End()
+
+	f := models.PRListFilter{
+		Status:      rq.Status,
+		ReviewerId:  userId,
+		CreatedFrom: rq.Since,
+		Sort:        models.PRSortCreatedAt,
+		Limit:       rq.Limit,
+	}
+	if f.Limit == 0 && rq.Cursor != "" {
+		f.Limit = defaultPRListLimit
+	}
+	if err := validatePRListFilter(&f); err != nil {
+		return nil, err
+	}
+
+	page, err := ps.listPage(ctx, f, rq.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews := &models.ReviewPage{
+		PullRequests: make([]models.PullRequestShort, 0, len(page.PullRequests)),
+		NextCursor:   page.NextCursor,
+		HasMore:      page.HasMore,
+	}
+	for _, pr := range page.PullRequests {
+		reviews.PullRequests = append(reviews.PullRequests, models.PullRequestShort{
+			PullRequestId:   pr.PullRequestId,
+			PullRequestName: pr.PullRequestName,
+			AuthorId:        pr.AuthorId,
+			Status:          pr.Status,
+		})
+	}
+
+	return reviews, nil
+}
+
+// listPage выбирает страницу после cursor. При f.Limit == 0 возвращает всё.
+func (ps *PRService) listPage(ctx context.Context, f models.PRListFilter, cursor string) (*models.PRListPage, error) {
+	if cursor != "" {
+		after, err := decodePRCursor(cursor)
+		if err != nil || after.Sort != f.Sort || after.Desc != f.Desc {
+			return nil, invalidQuery("cursor does not match the requested sort")
+		}
+		f.After = after
+	}
+
+	limit := f.Limit
+	if limit > 0 {
+		f.Limit++
+	}
+	prs, err := ps.Repo.ListPRs(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.PRListPage{PullRequests: prs}
+	if limit > 0 && len(prs) > limit {
+		page.PullRequests = prs[:limit]
+		page.HasMore = true
+		page.NextCursor = encodePRCursor(prCursorAt(&prs[limit-1], f.Sort, f.Desc))
+	}
+
+	return page, nil
+}
+
+func validatePRListFilter(f *models.PRListFilter) error {
+	switch f.Status {
+	case "", "OPEN", "MERGED", "CLOSED":
+	default:
+		return invalidQuery("unknown status %s", f.Status)
+	}
+
+	switch f.Sort {
+	case models.PRSortCreatedAt, models.PRSortMergedAt, models.PRSortName, models.PRSortId:
+	default:
+		return invalidQuery("unknown sort %s", f.Sort)
+	}
+
+	if f.Limit < 0 || f.Limit > maxPRListLimit {
+		return invalidQuery("limit must be between 1 and %d", maxPRListLimit)
+	}
+	if f.CreatedFrom != nil && f.CreatedTo != nil && !f.CreatedFrom.Before(*f.CreatedTo) {
+		return invalidQuery("created_from must be before created_to")
+	}
+	if f.MergedFrom != nil && f.MergedTo != nil && !f.MergedFrom.Before(*f.MergedTo) {
+		return invalidQuery("merged_from must be before merged_to")
+	}
+
+	return nil
+}
+
+func prCursorAt(pr *models.PullRequest, sort string, desc bool) *models.PRCursor {
+	c := &models.PRCursor{Sort: sort, Desc: desc, Id: pr.PullRequestId}
+	switch sort {
+	case models.PRSortCreatedAt:
+		c.Value = formatCursorTime(pr.CreatedAt)
+	case models.PRSortMergedAt:
+		c.Value = formatCursorTime(pr.MergedAt)
+	case models.PRSortName:
+		c.Value = pr.PullRequestName
+	}
+	return c
+}
+
+// formatCursorTime хранит время без зоны, как его отдаёт колонка TIMESTAMP.
+func formatCursorTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02T15:04:05.999999")
+}
+
+func encodePRCursor(c *models.PRCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodePRCursor(s string) (*models.PRCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var c models.PRCursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+	if c.Id == "" {
+		return nil, fmt.Errorf("cursor without id")
+	}
+	return &c, nil
+}
+
+func invalidQuery(format string, args ...any) *prerrors.Error {
+	return prerrors.New(prerrors.ErrInvalidQuery.Code, fmt.Sprintf(format, args...))
+}
@@ -2,55 +2,79 @@ package service
 
 import (
 	"context"
+	"errors"
 	"math/rand"
 	"fmt"
+	"sort"
+	"sync/atomic"
 	"time"
 
-	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 
 	prerrors "github.com/andro-kes/avito_test/internal/errors"
 	logger "github.com/andro-kes/avito_test/internal/log"
+	"github.com/andro-kes/avito_test/internal/metrics"
 	"github.com/andro-kes/avito_test/internal/models"
 	"github.com/andro-kes/avito_test/internal/repo"
 	"github.com/andro-kes/avito_test/internal/repo/db"
+	"github.com/andro-kes/avito_test/internal/tracing"
 )
 
 type PRService struct {
-	Repo repo.PRRepo
-	Tx   db.Tx
+	Repo     repo.PRRepo
+	UserRepo repo.UserRepo
+	Tx       db.Tx
+	// reviewersCount — сколько ревьюверов назначается на новый PR;
+	// меняется на ходу при перезагрузке конфигурации.
+	reviewersCount atomic.Int32
+	// Notifier получает изменения ревьюверов после коммита; nil — не уведомлять.
+	Notifier ReviewerNotifier
 }
 
-func NewPRService(pool *pgxpool.Pool) *PRService {
-	return &PRService{
-		Repo: repo.NewPRRepo(pool),
-		Tx:   db.NewTx(pool),
+// ReviewerNotifier сообщает внешним системам о назначении ревьюверов.
+// Вызывается после коммита транзакции и не должен блокировать.
+type ReviewerNotifier interface {
+	ReviewersAssigned(prId string, reviewers []string)
+	// ReviewerReplaced: пустой newUserId — ревьювер снят без замены.
+	ReviewerReplaced(prId, oldUserId, newUserId string)
+}
+
+const defaultReviewersCount = 2
+
+func NewPRService(st *repo.Storage) *PRService {
+	ps := &PRService{
+		Repo:     st.PRs,
+		UserRepo: st.Users,
+		Tx:       st.Tx,
 	}
+	ps.SetReviewersCount(defaultReviewersCount)
+	return ps
+}
+
+// SetReviewersCount задаёт число ревьюверов для новых PR.
+func (ps *PRService) SetReviewersCount(n int) {
+	ps.reviewersCount.Store(int32(n))
+}
+
+func (ps *PRService) ReviewersCount() int {
+	return int(ps.reviewersCount.Load())
 }
 
 func (ps *PRService) CreatePR(ctx context.Context, pr *models.PullRequestShort) (*models.PullRequest, error) {
-	var pullRequest *models.PullRequest
-	err := ps.Tx.RunInTx(ctx, func(ctx context.Context, q db.Querier) error {
-		activeReviewers, err := ps.Repo.FindActiveReviewers(ctx, pr.AuthorId)
-		if err != nil {
-			return err
-		}
-		logger.Log.Info(fmt.Sprintf("Найдено %d кандидатов в ревьюеры", len(activeReviewers)))
+	return ps.CreatePRInTeam(ctx, pr, "")
+}
 
-		reviewers := random(activeReviewers, 2)
-		logger.Log.Info(
-			fmt.Sprintf("Назначено %d ревьюера", len(reviewers)),
-			zap.Any("reviewers", reviewers),
-			zap.String("pr_id", pr.PullRequestId),
-		)
+// CreatePRInTeam создаёт PR и назначает ревьюверов из команды teamName.
+// Пустое имя команды означает команду автора.
+func (ps *PRService) CreatePRInTeam(ctx context.Context, pr *models.PullRequestShort, teamName string) (*models.PullRequest, error) {
+	ctx, span := tracing.Start(ctx, "PRService.CreatePRInTeam")
+	defer span.End()
 
-		newPR, err := ps.Repo.CreatePR(ctx, q, pr, reviewers)
-		if err != nil {
-			return err
-		}
-		logger.Log.Info("PR создан")
-		pullRequest = newPR
-		return nil
+	var pullRequest *models.PullRequest
+	err := ps.Tx.RunInTx(ctx, func(ctx context.Context, q db.Querier) error {
+		var err error
+		pullRequest, err = ps.createPR(ctx, q, pr, teamName)
+		return err
 	})
 	if err != nil {
 		return nil, err
@@ -59,6 +83,53 @@ func (ps *PRService) CreatePR(ctx context.Context, pr *models.PullRequestShort)
 	return pullRequest, nil
 }
 
+// createPR создаёт PR в транзакции q. Метрики и уведомление о ревьюверах
+// откладываются до коммита всей транзакции.
+func (ps *PRService) createPR(ctx context.Context, q db.Querier, pr *models.PullRequestShort, teamName string) (*models.PullRequest, error) {
+	exists, err := ps.Repo.CheckExistingPR(ctx, q, pr.PullRequestId)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, prerrors.ErrPRExists
+	}
+
+	var activeReviewers []string
+	if teamName == "" {
+		activeReviewers, err = ps.Repo.FindActiveReviewers(ctx, q, pr.AuthorId)
+	} else {
+		activeReviewers, err = ps.Repo.FindActiveReviewersInTeam(ctx, q, teamName, pr.AuthorId)
+	}
+	if err != nil {
+		return nil, err
+	}
+	logger.Ctx(ctx).Info(fmt.Sprintf("Найдено %d кандидатов в ревьюеры", len(activeReviewers)))
+
+	reviewers := random(activeReviewers, ps.ReviewersCount())
+	logger.Ctx(ctx).Info(
+		fmt.Sprintf("Назначено %d ревьюера", len(reviewers)),
+		zap.Any("reviewers", reviewers),
+		zap.String("pr_id", pr.PullRequestId),
+	)
+
+	newPR, err := ps.Repo.CreatePR(ctx, q, pr, reviewers)
+	if err != nil {
+		return nil, err
+	}
+	logger.Ctx(ctx).Info("PR создан")
+	db.AfterCommit(ctx, func() {
+		metrics.PRsCreated.Inc()
+		metrics.ReviewersAssigned.Add(float64(len(reviewers)))
+	})
+	if len(reviewers) > 0 {
+		ps.notify(ctx, func(n ReviewerNotifier) {
+			n.ReviewersAssigned(newPR.PullRequestId, reviewers)
+		})
+	}
+
+	return newPR, nil
+}
+
 func random(r []string, n int) []string {
 	if len(r) == 0 {
 		return []string{}
@@ -79,23 +150,114 @@ func random(r []string, n int) []string {
 	return out
 }
 
+func (ps *PRService) GetPR(ctx context.Context, id string) (*models.PullRequestDetails, error) {
+	ctx, span := tracing.Start(ctx, "PRService.GetPR")
+	defer span.End()
+
+	return ps.Repo.GetPRDetails(ctx, id)
+}
+
+// SetDecision записывает решение ревьювера и возвращает PR с обновлёнными ревьюверами.
+func (ps *PRService) SetDecision(ctx context.Context, prId, userId, decision string) (*models.PullRequestDetails, error) {
+	ctx, span := tracing.Start(ctx, "PRService.SetDecision")
+	defer span.End()
+
+	switch decision {
+	case models.ReviewApproved, models.ReviewChangesRequested:
+	default:
+		return nil, invalidPR("unknown decision %q", decision)
+	}
+
+	err := ps.Tx.RunInTx(ctx, func(ctx context.Context, q db.Querier) error {
+		return ps.Repo.SetDecision(ctx, q, prId, userId, decision)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ps.Repo.GetPRDetails(ctx, prId)
+}
+
 func (ps *PRService) CheckExistingPR(ctx context.Context, id string) (bool, error) {
-	return ps.Repo.CheckExistingPR(ctx, id)
+	ctx, span := tracing.Start(ctx, "PRService.CheckExistingPR")
+	defer span.End()
+
+	var exists bool
+	err := ps.Tx.RunInTx(ctx, func(ctx context.Context, q db.Querier) error {
+		var err error
+		exists, err = ps.Repo.CheckExistingPR(ctx, q, id)
+		return err
+	})
+	return exists, err
 }
 
 func (ps *PRService) MergePR(ctx context.Context, id string) (*models.PullRequest, error) {
-	return ps.Repo.MergePR(ctx, id)
+	ctx, span := tracing.Start(ctx, "PRService.MergePR")
+	defer span.End()
+
+	return ps.updatePR(ctx, func(ctx context.Context, q db.Querier) (*models.PullRequest, error) {
+		return ps.Repo.MergePR(ctx, q, id)
+	})
 }
 
-func (ps *PRService) IsMerged(ctx context.Context, id string) error {
-	return ps.Repo.IsMerged(ctx, id)
+func (ps *PRService) ClosePR(ctx context.Context, id string) (*models.PullRequest, error) {
+	ctx, span := tracing.Start(ctx, "PRService.ClosePR")
+	defer span.End()
+
+	return ps.updatePR(ctx, func(ctx context.Context, q db.Querier) (*models.PullRequest, error) {
+		return ps.Repo.ClosePR(ctx, q, id)
+	})
+}
+
+func (ps *PRService) ReopenPR(ctx context.Context, id string) (*models.PullRequest, error) {
+	ctx, span := tracing.Start(ctx, "PRService.ReopenPR")
+	defer span.End()
+
+	return ps.updatePR(ctx, func(ctx context.Context, q db.Querier) (*models.PullRequest, error) {
+		return ps.Repo.ReopenPR(ctx, q, id)
+	})
+}
+
+func (ps *PRService) SetDraft(ctx context.Context, id string, isDraft bool) (*models.PullRequest, error) {
+	ctx, span := tracing.Start(ctx, "PRService.SetDraft")
+	defer span.End()
+
+	return ps.updatePR(ctx, func(ctx context.Context, q db.Querier) (*models.PullRequest, error) {
+		return ps.Repo.SetDraft(ctx, q, id, isDraft)
+	})
+}
+
+// updatePR меняет PR в отдельной транзакции: строка PR заблокирована до
+// коммита, как при переназначении.
+func (ps *PRService) updatePR(ctx context.Context, fn func(ctx context.Context, q db.Querier) (*models.PullRequest, error)) (*models.PullRequest, error) {
+	var pr *models.PullRequest
+	err := ps.Tx.RunInTx(ctx, func(ctx context.Context, q db.Querier) error {
+		var err error
+		pr, err = fn(ctx, q)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pr, nil
 }
 
 func (ps *PRService) ReassignReviewer(ctx context.Context, prId, oldUserId string) (*models.PullRequest, string, error) {
+	ctx, span := tracing.Start(ctx, "PRService.ReassignReviewer")
+	defer span.End()
+
 	var pr *models.PullRequest
 	var replacedBy string
 	err := ps.Tx.RunInTx(ctx, func(ctx context.Context, q db.Querier) error {
-		replacement, err := ps.Repo.FindReplacementReviewers(ctx, prId, []string{oldUserId})
+		// Сначала кандидаты, затем PR — в том же порядке, что и при деактивации
+		if err := ps.UserRepo.LockTeammates(ctx, q, []string{oldUserId}); err != nil {
+			return err
+		}
+		// PR не смержат и не переназначат, пока идёт замена
+		if err := ps.Repo.IsMerged(ctx, q, prId); err != nil {
+			return err
+		}
+
+		replacement, err := ps.Repo.FindReplacementReviewers(ctx, q, prId, []string{oldUserId})
 		if err != nil {
 			return err
 		}
@@ -106,84 +268,185 @@ func (ps *PRService) ReassignReviewer(ctx context.Context, prId, oldUserId strin
 
 		replacedBy = random(replacement, 1)[0]
 		pr, err = ps.Repo.ReassignReviewer(ctx, q, prId, oldUserId, replacedBy)
-		return err
+		if err != nil {
+			return err
+		}
+
+		db.AfterCommit(ctx, func() {
+			metrics.Reassignments.WithLabelValues(metrics.ReasonManual).Inc()
+		})
+		ps.notify(ctx, func(n ReviewerNotifier) {
+			n.ReviewerReplaced(prId, oldUserId, replacedBy)
+		})
+		return nil
 	})
+	if errors.Is(err, prerrors.ErrNoCandidate) {
+		metrics.NoCandidate.Inc()
+	}
 
 	return pr, replacedBy, err
 }
 
-func (ps *PRService) GetReview(ctx context.Context, userId string) ([]models.PullRequestShort, error) {
-	return ps.Repo.GetReview(ctx, userId)
-}
+// DeactivateReviewers деактивирует пользователей и в той же транзакции
+// заменяет их в открытых PR. Если замены не нашлось, ревьювер просто
+// снимается, а PR попадает в Unreplaced.
+func (ps *PRService) DeactivateReviewers(ctx context.Context, ids []string) (*models.Deactivation, error) {
+	ctx, span := tracing.Start(ctx, "PRService.DeactivateReviewers")
+	defer span.End()
 
-func (ps *PRService) GetListByUsers(ctx context.Context, ids []string) (map[string]models.PullRequest, error) {
-	prsMap := make(map[string]models.PullRequest)
-	prs, err := ps.Repo.GetListByUsers(ctx, ids)
+	var reassignments []models.PRReassignment
+	err := ps.Tx.RunInTx(ctx, func(ctx context.Context, q db.Querier) error {
+		if err := ps.UserRepo.LockTeammates(ctx, q, ids); err != nil {
+			return err
+		}
+		if err := ps.UserRepo.DeactivateUsers(ctx, q, ids); err != nil {
+			return err
+		}
+
+		var err error
+		reassignments, err = ps.reassignDeactivated(ctx, q, ids)
+		return err
+	})
 	if err != nil {
-		return prsMap, err
+		return nil, err
 	}
 
-	for _, pr := range prs {
-		prsMap[pr.PullRequestId] = pr
+	result := &models.Deactivation{
+		Deactivated:   ids,
+		Reassignments: reassignments,
+		Unreplaced:    make([]string, 0),
+	}
+	for _, r := range reassignments {
+		if r.NewUserId != "" {
+			continue
+		}
+		if n := len(result.Unreplaced); n == 0 || result.Unreplaced[n-1] != r.PullRequestId {
+			result.Unreplaced = append(result.Unreplaced, r.PullRequestId)
+		}
 	}
+	metrics.NoCandidate.Add(float64(len(result.Unreplaced)))
 
-	return prsMap, nil
+	return result, nil
 }
 
-func (ps *PRService) ReassignDeactivatedUsers(ctx context.Context, pr *models.PullRequest, ids []string) error {
-	return ps.Tx.RunInTx(ctx, func(ctx context.Context, q db.Querier) error {
-		replacement, err := ps.Repo.FindReplacementReviewers(ctx, pr.PullRequestId, ids)
+// reassignDeactivated заменяет деактивированных ids во всех открытых PR
+// в транзакции q и возвращает замены, сгруппированные по PR. Если кандидатов
+// не хватило, ревьювер снимается без замены.
+func (ps *PRService) reassignDeactivated(
+	ctx context.Context, q db.Querier, ids []string,
+) ([]models.PRReassignment, error) {
+	// Деактивация уже заблокировала пользователей, поэтому список PR
+	// включает назначения, сделанные до неё
+	prs, err := ps.Repo.GetListByUsers(ctx, q, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	reassignments := make([]models.PRReassignment, 0)
+	for i := range prs {
+		pr := &prs[i]
+		replacement, err := ps.Repo.FindReplacementReviewers(ctx, q, pr.PullRequestId, ids)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		replaced, err := ps.replaceDeactivated(ctx, q, pr, ids, replacement)
+		if err != nil {
+			return nil, err
 		}
 
-		if len(replacement) == 0 {
-			return prerrors.ErrNoCandidate
+		olds := make([]string, 0, len(replaced))
+		for old := range replaced {
+			olds = append(olds, old)
 		}
+		sort.Strings(olds)
+		for _, old := range olds {
+			reassignments = append(reassignments, models.PRReassignment{
+				PullRequestId: pr.PullRequestId,
+				OldUserId:     old,
+				NewUserId:     replaced[old],
+			})
+		}
+	}
+
+	return reassignments, nil
+}
 
-		replaced := random(replacement, len(replacement))
+// replaceDeactivated заменяет деактивированных ревьюверов PR кандидатами из replacement
+// в транзакции q. Возвращает замены: старый ревьювер -> новый ("" — снят без замены).
+func (ps *PRService) replaceDeactivated(
+	ctx context.Context, q db.Querier, pr *models.PullRequest, ids, replacement []string,
+) (map[string]string, error) {
+	replaced := random(replacement, len(replacement))
 
-		deactivated := make(map[string]struct{}, len(ids))
-		for _, u := range ids {
-			deactivated[u] = struct{}{}
+	deactivated := make(map[string]struct{}, len(ids))
+	for _, u := range ids {
+		deactivated[u] = struct{}{}
+	}
+
+	used := make(map[string]struct{}, 0)
+	for _, u := range pr.AssignedReviewers {
+		if _, ok := deactivated[u]; !ok {
+			used[u] = struct{}{}
 		}
+	}
+	used[pr.AuthorId] = struct{}{}
 
-		used := make(map[string]struct{}, 0)
-		for _, u := range pr.AssignedReviewers {
-			if _, ok := deactivated[u]; !ok {
-				used[u] = struct{}{}
+	repIdx := 0
+	nextReplacement := func() (string, bool) {
+		for repIdx < len(replaced) {
+			c := replaced[repIdx]
+			repIdx++
+			if _, d := deactivated[c]; d {
+				continue
 			}
-		}
-		used[pr.AuthorId] = struct{}{}
-
-		repIdx := 0
-		nextReplacement := func() (string, bool) {
-			for repIdx < len(replaced) {
-				c := replaced[repIdx]
-				repIdx++
-				if _, d := deactivated[c]; d {
-					continue
-				}
-				if _, u := used[c]; u {
-					continue
-				}
-				used[c] = struct{}{}
-				return c, true
+			if _, u := used[c]; u {
+				continue
 			}
-			return "", false
+			used[c] = struct{}{}
+			return c, true
 		}
+		return "", false
+	}
 
-		newAssigned := make([]string, 0, len(pr.AssignedReviewers))
-		for _, r := range pr.AssignedReviewers {
-			if _, d := deactivated[r]; !d {
-				newAssigned = append(newAssigned, r)
-				continue
-			}
-			if cand, ok := nextReplacement(); ok {
-				newAssigned = append(newAssigned, cand)
+	newAssigned := make([]string, 0, len(pr.AssignedReviewers))
+	replacedBy := make(map[string]string)
+	for _, r := range pr.AssignedReviewers {
+		if _, d := deactivated[r]; !d {
+			newAssigned = append(newAssigned, r)
+			continue
+		}
+		cand, ok := nextReplacement()
+		if ok {
+			newAssigned = append(newAssigned, cand)
+		}
+		replacedBy[r] = cand
+	}
+
+	if err := ps.Repo.ChangeDeactivatedReviewers(ctx, q, pr.PullRequestId, newAssigned); err != nil {
+		return nil, err
+	}
+
+	db.AfterCommit(ctx, func() {
+		for _, cand := range replacedBy {
+			if cand != "" {
+				metrics.Reassignments.WithLabelValues(metrics.ReasonDeactivated).Inc()
 			}
 		}
+	})
+	ps.notify(ctx, func(n ReviewerNotifier) {
+		for old, cand := range replacedBy {
+			n.ReviewerReplaced(pr.PullRequestId, old, cand)
+		}
+	})
+	return replacedBy, nil
+}
 
-		return ps.Repo.ChangeDeactivatedReviewers(ctx, q, pr.PullRequestId, newAssigned)
+// notify откладывает уведомление до коммита текущей транзакции.
+func (ps *PRService) notify(ctx context.Context, fn func(n ReviewerNotifier)) {
+	if ps.Notifier == nil {
+		return
+	}
+	db.AfterCommit(ctx, func() {
+		fn(ps.Notifier)
 	})
 }
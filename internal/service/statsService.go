@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/repo"
+)
+
+const (
+	defaultStatsWindow     = 30 * 24 * time.Hour
+	defaultStatsCacheTTL   = 30 * time.Second
+	defaultStatsCacheLimit = 128
+)
+
+// StatsService считает статистику агрегатами в SQL и кратко кэширует ответы:
+// менеджеры открывают одни и те же отчёты, а запросы идут по всей истории.
+type StatsService struct {
+	Repo repo.StatsRepo
+	TTL  time.Duration
+	// CacheLimit ограничивает число ответов в кэше: окно, команду и часовой
+	// пояс задаёт клиент, и без предела кэш рос бы на каждый новый запрос.
+	CacheLimit int
+	// Timezone — часовой пояс недель /stats/prs/, если он не задан в запросе.
+	Timezone string
+
+	mu    sync.Mutex
+	cache map[string]statsEntry
+}
+
+type statsEntry struct {
+	value   any
+	expires time.Time
+}
+
+func NewStatsService(st *repo.Storage) *StatsService {
+	return &StatsService{
+		Repo:       st.Stats,
+		TTL:        defaultStatsCacheTTL,
+		CacheLimit: defaultStatsCacheLimit,
+		Timezone:   "UTC",
+		cache:      make(map[string]statsEntry),
+	}
+}
+
+// StatsWindow достраивает окно: по умолчанию последние 30 дней до текущей минуты.
+func StatsWindow(from, to *time.Time) (models.StatsWindow, error) {
+	w := models.StatsWindow{To: time.Now().UTC().Truncate(time.Minute)}
+	if to != nil {
+		w.To = to.UTC()
+	}
+	w.From = w.To.Add(-defaultStatsWindow)
+	if from != nil {
+		w.From = from.UTC()
+	}
+	if !w.From.Before(w.To) {
+		return w, invalidQuery("from must be before to")
+	}
+	return w, nil
+}
+
+func (ss *StatsService) ReviewerStats(ctx context.Context, w models.StatsWindow, teamName string) (*models.ReviewerStatsReport, error) {
+	key := fmt.Sprintf("reviewers|%d|%d|%s", w.From.UnixNano(), w.To.UnixNano(), teamName)
+	return cached(ss, key, func() (*models.ReviewerStatsReport, error) {
+		users, err := ss.Repo.ReviewerStats(ctx, w, teamName)
+		if err != nil {
+			return nil, err
+		}
+		teams, err := ss.Repo.TeamReviewStats(ctx, w, teamName)
+		if err != nil {
+			return nil, err
+		}
+
+		load := make(map[string]int, len(teams))
+		for _, u := range users {
+			load[u.TeamName] += u.OpenLoad
+		}
+		for i := range teams {
+			teams[i].OpenLoad = load[teams[i].TeamName]
+		}
+
+		return &models.ReviewerStatsReport{
+			Window: w,
+			Users:  users,
+			Teams:  teams,
+		}, nil
+	})
+}
+
+func (ss *StatsService) PRThroughput(ctx context.Context, w models.StatsWindow, tz, teamName string) (*models.PRThroughputReport, error) {
+	if tz == "" {
+		tz = ss.Timezone
+	}
+	if _, err := time.LoadLocation(tz); err != nil {
+		return nil, invalidQuery("unknown timezone %s", tz)
+	}
+
+	key := fmt.Sprintf("prs|%d|%d|%s|%s", w.From.UnixNano(), w.To.UnixNano(), tz, teamName)
+	return cached(ss, key, func() (*models.PRThroughputReport, error) {
+		weeks, err := ss.Repo.PRThroughput(ctx, w, tz, teamName)
+		if err != nil {
+			return nil, err
+		}
+
+		return &models.PRThroughputReport{
+			Window:   w,
+			Timezone: tz,
+			Weeks:    weeks,
+		}, nil
+	})
+}
+
+// cached возвращает значение из кэша или вычисляет и сохраняет его на ss.TTL.
+// Если кэш полон, вытесняется ответ, который истёк бы первым.
+func cached[T any](ss *StatsService, key string, load func() (T, error)) (T, error) {
+	now := time.Now()
+
+	ss.mu.Lock()
+	if e, ok := ss.cache[key]; ok && now.Before(e.expires) {
+		ss.mu.Unlock()
+		return e.value.(T), nil
+	}
+	ss.mu.Unlock()
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	for k, e := range ss.cache {
+		if !now.Before(e.expires) {
+			delete(ss.cache, k)
+		}
+	}
+	_, replace := ss.cache[key]
+	for !replace && len(ss.cache) >= ss.CacheLimit && len(ss.cache) > 0 {
+		oldest := ""
+		for k, e := range ss.cache {
+			if oldest == "" || e.expires.Before(ss.cache[oldest].expires) {
+				oldest = k
+			}
+		}
+		delete(ss.cache, oldest)
+	}
+	ss.cache[key] = statsEntry{value: value, expires: now.Add(ss.TTL)}
+
+	return value, nil
+}
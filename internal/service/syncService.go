@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/repo"
+	"github.com/andro-kes/avito_test/internal/repo/db"
+)
+
+// errDryRun откатывает транзакцию пробной синхронизации.
+var errDryRun = errors.New("dry run")
+
+// SyncService приводит команды и пользователей к желаемому состоянию целиком:
+// всё, чего нет в документе, деактивируется.
+type SyncService struct {
+	TeamService *TeamService
+	PRService   *PRService
+	Tx          db.Tx
+}
+
+func NewSyncService(st *repo.Storage, teamService *TeamService, prService *PRService) *SyncService {
+	return &SyncService{
+		TeamService: teamService,
+		PRService:   prService,
+		Tx:          st.Tx,
+	}
+}
+
+// Sync применяет doc в одной транзакции и возвращает план изменений.
+// При dryRun транзакция откатывается, а новые ревьюверы в плане лишь показательны.
+func (ss *SyncService) Sync(ctx context.Context, doc *models.OrgDocument, dryRun bool) (*models.OrgSyncPlan, error) {
+	if err := validateOrgDocument(doc); err != nil {
+		return nil, err
+	}
+	// Пустой документ деактивировал бы всю организацию — скорее всего, это ошибка
+	if len(memberIds(doc.Teams)) == 0 {
+		return nil, invalidTeam("document has no members, sync would deactivate every user")
+	}
+
+	plan := &models.OrgSyncPlan{DryRun: dryRun}
+	err := ss.Tx.RunInTx(ctx, func(ctx context.Context, q db.Querier) error {
+		if err := ss.reconcile(ctx, q, doc, plan); err != nil {
+			return err
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+func (ss *SyncService) reconcile(ctx context.Context, q db.Querier, doc *models.OrgDocument, plan *models.OrgSyncPlan) error {
+	teamRepo := ss.TeamService.TeamRepo
+	userRepo := ss.TeamService.UserRepo
+
+	current, err := teamRepo.ListTeams(ctx, q)
+	if err != nil {
+		return err
+	}
+	plan.Diff = diffOrg(current, doc.Teams)
+
+	desired := make(map[string]struct{})
+	for _, team := range doc.Teams {
+		for _, m := range team.Members {
+			desired[m.UserID] = struct{}{}
+		}
+	}
+	missing := make([]string, 0)
+	for _, team := range current {
+		for _, m := range team.Members {
+			if _, ok := desired[m.UserID]; !ok && m.IsActive {
+				missing = append(missing, m.UserID)
+			}
+		}
+	}
+	plan.Diff.UsersDeactivated = append(plan.Diff.UsersDeactivated, missing...)
+	sort.Strings(plan.Diff.UsersDeactivated)
+
+	// Пользователей блокируем разом и по порядку, до их PR
+	if err := userRepo.LockTeammates(ctx, q, append(memberIds(current), memberIds(doc.Teams)...)); err != nil {
+		return err
+	}
+	if err := ss.TeamService.upsertTeams(ctx, q, doc.Teams); err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		if err := userRepo.DeactivateUsers(ctx, q, missing); err != nil {
+			return err
+		}
+	}
+
+	plan.Reassignments = make([]models.PRReassignment, 0)
+	deactivated := plan.Diff.UsersDeactivated
+	if len(deactivated) == 0 {
+		return nil
+	}
+
+	plan.Reassignments, err = ss.PRService.reassignDeactivated(ctx, q, deactivated)
+	return err
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
+	"github.com/andro-kes/avito_test/internal/models"
+	"github.com/andro-kes/avito_test/internal/repo/db"
+)
+
+func (ts *TeamService) ExportTeams(ctx context.Context) (*models.OrgDocument, error) {
+	var doc models.OrgDocument
+	err := ts.Tx.RunInTx(ctx, func(ctx context.Context, q db.Querier) error {
+		teams, err := ts.TeamRepo.ListTeams(ctx, q)
+		doc.Teams = teams
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &doc, nil
+}
+
+// ImportTeams создаёт недостающие команды и обновляет участников так же,
+// как CreateTeamWithMembers. При dryRun только возвращает изменения.
+func (ts *TeamService) ImportTeams(ctx context.Context, doc *models.OrgDocument, dryRun bool) (*models.OrgDiff, error) {
+	if err := validateOrgDocument(doc); err != nil {
+		return nil, err
+	}
+
+	var diff *models.OrgDiff
+	err := ts.Tx.RunInTx(ctx, func(ctx context.Context, q db.Querier) error {
+		current, err := ts.TeamRepo.ListTeams(ctx, q)
+		if err != nil {
+			return err
+		}
+
+		diff = diffOrg(current, doc.Teams)
+		if dryRun {
+			return nil
+		}
+		return ts.upsertTeams(ctx, q, doc.Teams)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return diff, nil
+}
+
+func (ts *TeamService) upsertTeams(ctx context.Context, q db.Querier, teams []models.Team) error {
+	if err := ts.UserRepo.LockTeammates(ctx, q, memberIds(teams)); err != nil {
+		return err
+	}
+	for _, team := range teams {
+		if _, err := ts.TeamRepo.EnsureTeam(ctx, q, team.TeamName); err != nil {
+			return err
+		}
+		for _, m := range team.Members {
+			if err := ts.UserRepo.UpsertUser(ctx, q, team.TeamName, m); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func memberIds(teams []models.Team) []string {
+	ids := make([]string, 0)
+	for _, team := range teams {
+		for _, m := range team.Members {
+			ids = append(ids, m.UserID)
+		}
+	}
+	return ids
+}
+
+func validateOrgDocument(doc *models.OrgDocument) error {
+	teams := make(map[string]struct{}, len(doc.Teams))
+	users := make(map[string]string)
+	for _, team := range doc.Teams {
+		if team.TeamName == "" {
+			return invalidTeam("team_name is required")
+		}
+		if _, dup := teams[team.TeamName]; dup {
+			return invalidTeam("team %s is listed twice", team.TeamName)
+		}
+		teams[team.TeamName] = struct{}{}
+
+		for _, m := range team.Members {
+			if m.UserID == "" || m.Username == "" {
+				return invalidTeam("team %s: user_id and username are required", team.TeamName)
+			}
+			if other, dup := users[m.UserID]; dup {
+				return invalidTeam("user %s is listed in teams %s and %s", m.UserID, other, team.TeamName)
+			}
+			users[m.UserID] = team.TeamName
+		}
+	}
+
+	return nil
+}
+
+func invalidTeam(format string, args ...any) error {
+	return prerrors.New(prerrors.ErrInvalidTeam.Code, fmt.Sprintf(format, args...))
+}
+
+// diffOrg сравнивает текущий состав команд с желаемым.
+// Пользователи, которых нет в desired, не затрагиваются.
+func diffOrg(current, desired []models.Team) *models.OrgDiff {
+	diff := &models.OrgDiff{
+		TeamsCreated:     make([]string, 0),
+		UsersAdded:       make([]models.UserChange, 0),
+		UsersMoved:       make([]models.UserChange, 0),
+		UsersRenamed:     make([]models.UserChange, 0),
+		UsersActivated:   make([]string, 0),
+		UsersDeactivated: make([]string, 0),
+	}
+
+	type currentUser struct {
+		team string
+		models.TeamMember
+	}
+	teams := make(map[string]struct{}, len(current))
+	users := make(map[string]currentUser)
+	for _, team := range current {
+		teams[team.TeamName] = struct{}{}
+		for _, m := range team.Members {
+			users[m.UserID] = currentUser{team: team.TeamName, TeamMember: m}
+		}
+	}
+
+	for _, team := range desired {
+		if _, ok := teams[team.TeamName]; !ok {
+			diff.TeamsCreated = append(diff.TeamsCreated, team.TeamName)
+		}
+
+		for _, m := range team.Members {
+			cur, ok := users[m.UserID]
+			if !ok {
+				diff.UsersAdded = append(diff.UsersAdded, models.UserChange{
+					UserId: m.UserID, Username: m.Username, ToTeam: team.TeamName,
+				})
+				continue
+			}
+
+			if cur.team != team.TeamName {
+				diff.UsersMoved = append(diff.UsersMoved, models.UserChange{
+					UserId: m.UserID, Username: m.Username, FromTeam: cur.team, ToTeam: team.TeamName,
+				})
+			}
+			if cur.Username != m.Username {
+				diff.UsersRenamed = append(diff.UsersRenamed, models.UserChange{
+					UserId: m.UserID, Username: m.Username,
+				})
+			}
+			switch {
+			case cur.IsActive && !m.IsActive:
+				diff.UsersDeactivated = append(diff.UsersDeactivated, m.UserID)
+			case !cur.IsActive && m.IsActive:
+				diff.UsersActivated = append(diff.UsersActivated, m.UserID)
+			}
+		}
+	}
+
+	return diff
+}
@@ -3,8 +3,6 @@ package service
 import (
 	"context"
 
-	"github.com/jackc/pgx/v5/pgxpool"
-
 	"github.com/andro-kes/avito_test/internal/models"
 	"github.com/andro-kes/avito_test/internal/repo"
 	"github.com/andro-kes/avito_test/internal/repo/db"
@@ -16,11 +14,11 @@ type TeamService struct {
 	Tx       db.Tx
 }
 
-func NewTeamService(pool *pgxpool.Pool) *TeamService {
+func NewTeamService(st *repo.Storage) *TeamService {
 	return &TeamService{
-		TeamRepo: repo.NewTeamRepo(pool),
-		UserRepo: repo.NewUserRepo(pool),
-		Tx:       db.NewTx(pool),
+		TeamRepo: st.Teams,
+		UserRepo: st.Users,
+		Tx:       st.Tx,
 	}
 }
 
@@ -34,6 +32,11 @@ func (ts *TeamService) CreateTeamWithMembers(ctx context.Context, team models.Te
 		if err != nil {
 			return err
 		}
+		// Участники могут перейти из других команд — блокируем их по порядку
+		err = ts.UserRepo.LockTeammates(ctx, q, memberIds([]models.Team{team}))
+		if err != nil {
+			return err
+		}
 		for _, m := range team.Members {
 			err = ts.UserRepo.UpsertUser(ctx, q, team.TeamName, m)
 			if err != nil {
@@ -3,11 +3,10 @@ package service
 import (
 	"context"
 
-	"github.com/jackc/pgx/v5/pgxpool"
-
 	"github.com/andro-kes/avito_test/internal/models"
 	"github.com/andro-kes/avito_test/internal/repo"
 	"github.com/andro-kes/avito_test/internal/repo/db"
+	"github.com/andro-kes/avito_test/internal/tracing"
 )
 
 type UserService struct {
@@ -15,29 +14,32 @@ type UserService struct {
 	Tx   db.Tx
 }
 
-func NewUserService(pool *pgxpool.Pool) *UserService {
+func NewUserService(st *repo.Storage) *UserService {
 	return &UserService{
-		Repo: repo.NewUserRepo(pool),
-		Tx:   db.NewTx(pool),
+		Repo: st.Users,
+		Tx:   st.Tx,
 	}
 }
 
 func (us *UserService) SetIsActive(ctx context.Context, userId string, isActive bool) error {
+	ctx, span := tracing.Start(ctx, "UserService.SetIsActive")
+	defer span.End()
+
 	return us.Tx.RunInTx(ctx, func(ctx context.Context, q db.Querier) error {
 		return us.Repo.SetIsActive(ctx, q, userId, isActive)
 	})
 }
 
 func (us *UserService) GetUser(ctx context.Context, userId string) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUser")
+	defer span.End()
+
 	return us.Repo.GetUser(ctx, userId)
 }
 
 func (us *UserService) CountReview(ctx context.Context, userId string) (int, error) {
-	return us.Repo.CountReview(ctx, userId)
-}
+	ctx, span := tracing.Start(ctx, "UserService.CountReview")
+	defer span.End()
 
-func (us *UserService) DeactivateUsers(ctx context.Context, userIds []string) error {
-	return us.Tx.RunInTx(ctx, func(ctx context.Context, q db.Querier) error {
-		return us.Repo.DeactivateUsers(ctx, q, userIds)
-	})
+	return us.Repo.CountReview(ctx, userId)
 }
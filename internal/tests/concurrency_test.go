@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// post отправляет JSON и возвращает код ответа; годится для горутин,
+// потому что не останавливает тест сам.
+func post(url string, payload any) (int, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(context.Background(), "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// Переназначения и деактивации идут одновременно; после них ни в одном
+// открытом PR не должно остаться неактивного ревьювера.
+func TestConcurrentReassignAndDeactivate(t *testing.T) {
+	baseURL, db, _ := SetupTest(t)
+
+	members := make([]map[string]any, 0, 8)
+	for i := 1; i <= 8; i++ {
+		members = append(members, map[string]any{
+			"user_id": fmt.Sprintf("u%d", i), "username": fmt.Sprintf("User %d", i), "is_active": true,
+		})
+	}
+	code, err := post(baseURL+"/team/add/", map[string]any{"team_name": "backend", "members": members})
+	require.NoError(t, err)
+	require.Equal(t, 201, code)
+
+	const prs = 6
+	for i := 1; i <= prs; i++ {
+		code, err := post(baseURL+"/pullRequest/create/", map[string]any{
+			"pull_request_id":   fmt.Sprintf("pr-%d", i),
+			"pull_request_name": "Concurrent",
+			"author_id":         "u1",
+		})
+		require.NoError(t, err)
+		require.Equal(t, 201, code)
+	}
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		codes = make(map[string][]int)
+		errs  []error
+	)
+	send := func(kind, url string, payload any) {
+		defer wg.Done()
+		code, err := post(url, payload)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		codes[kind] = append(codes[kind], code)
+	}
+
+	for i := 1; i <= prs; i++ {
+		for u := 2; u <= 8; u++ {
+			wg.Add(1)
+			go send("reassign", baseURL+"/pullRequest/reassign/", map[string]any{
+				"pull_request_id": fmt.Sprintf("pr-%d", i),
+				"old_user_id":     fmt.Sprintf("u%d", u),
+			})
+		}
+	}
+	for _, u := range []string{"u3", "u5", "u7"} {
+		wg.Add(1)
+		go send("deactivate", baseURL+"/users/deactivate/", map[string]any{"user_ids": []string{u}})
+	}
+	wg.Wait()
+
+	require.Empty(t, errs)
+	// Блокировки берутся в одном порядке, поэтому взаимных блокировок нет и 500 не бывает
+	for kind, cs := range codes {
+		for _, c := range cs {
+			require.Contains(t, []int{200, 409}, c, kind)
+		}
+	}
+	require.NotContains(t, codes["deactivate"], 409, "enough candidates are left for every PR")
+
+	var inactive []string
+	rows, err := db.Query(context.Background(), `
+	SELECT pr.pull_request_id || ':' || u.user_id
+	FROM pull_requests pr
+	CROSS JOIN LATERAL unnest(pr.assigned_reviewers) AS r
+	INNER JOIN users u ON u.user_id = r
+	WHERE pr.status = 'OPEN' AND NOT u.is_active
+	`)
+	require.NoError(t, err)
+	for rows.Next() {
+		var s string
+		require.NoError(t, rows.Scan(&s))
+		inactive = append(inactive, s)
+	}
+	require.NoError(t, rows.Err())
+	require.Empty(t, inactive)
+
+	var broken int
+	err = db.QueryRow(context.Background(), `
+	SELECT COUNT(*)
+	FROM pull_requests pr
+	WHERE pr.author_id = ANY(pr.assigned_reviewers)
+	OR cardinality(pr.assigned_reviewers) <> (SELECT COUNT(DISTINCT r) FROM unnest(pr.assigned_reviewers) AS r)
+	OR cardinality(pr.assigned_reviewers) <> 2
+	`).Scan(&broken)
+	require.NoError(t, err)
+	require.Zero(t, broken, "reviewers must stay distinct, without the author, two per PR")
+}
@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/andro-kes/avito_test/internal/models"
+)
+
+func sendGitLabHook(t *testing.T, baseURL, token, deliveryID string, hook map[string]any) *http.Response {
+	t.Helper()
+
+	body, _ := json.Marshal(hook)
+	req, err := http.NewRequestWithContext(context.Background(), "POST", baseURL+"/integrations/gitlab/webhook", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gitlab-Token", token)
+	req.Header.Set("X-Gitlab-Event", "Merge Request Hook")
+	req.Header.Set("X-Gitlab-Event-UUID", deliveryID)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func mergeRequestHook(username, action string, attrs map[string]any) map[string]any {
+	objectAttributes := map[string]any{
+		"iid":    7,
+		"title":  "Add search",
+		"action": action,
+	}
+	for k, v := range attrs {
+		objectAttributes[k] = v
+	}
+	return map[string]any{
+		"object_kind":       "merge_request",
+		"user":              map[string]any{"username": username},
+		"project":           map[string]any{"id": 42, "path_with_namespace": "group/backend"},
+		"object_attributes": objectAttributes,
+	}
+}
+
+func TestGitLabWebhook(t *testing.T) {
+	baseURL, _, _ := SetupTest(t)
+	client := &http.Client{}
+
+	team := map[string]any{
+		"team_name": "backend",
+		"members": []map[string]any{
+			{"user_id": "u1", "username": "Alice", "is_active": true},
+			{"user_id": "u2", "username": "Bob", "is_active": true},
+		},
+	}
+	body, _ := json.Marshal(team)
+	req, err := http.NewRequestWithContext(context.Background(), "POST", baseURL+"/team/add/", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 201, resp.StatusCode)
+
+	// Логин в GitLab отличается от user_id — нужно сопоставление
+	identity := map[string]any{"forge": "gitlab", "login": "alice.gl", "user_id": "u1"}
+	body, _ = json.Marshal(identity)
+	req, err = http.NewRequestWithContext(context.Background(), "POST", baseURL+"/integrations/identities/", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+
+	// Неверный токен
+	resp = sendGitLabHook(t, baseURL, "wrong", "d-0", mergeRequestHook("alice.gl", "open", nil))
+	defer resp.Body.Close()
+	require.Equal(t, 401, resp.StatusCode)
+
+	// Открытие MR создаёт PR
+	resp = sendGitLabHook(t, baseURL, gitlabTestToken, "d-1", mergeRequestHook("alice.gl", "open", nil))
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+
+	var result map[string]string
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	require.Equal(t, "processed", result["status"])
+	require.Equal(t, "gitlab:group/backend!7", result["pull_request_id"])
+
+	// Повторная доставка не обрабатывается
+	resp = sendGitLabHook(t, baseURL, gitlabTestToken, "d-1", mergeRequestHook("alice.gl", "open", nil))
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	require.Equal(t, "duplicate", result["status"])
+
+	// Та же доставка под новым идентификатором не падает с PR_EXISTS
+	resp = sendGitLabHook(t, baseURL, gitlabTestToken, "d-1-retry", mergeRequestHook("alice.gl", "open", nil))
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	require.Equal(t, "processed", result["status"])
+
+	// Переключение черновика
+	hook := mergeRequestHook("alice.gl", "update", map[string]any{"draft": true})
+	hook["changes"] = map[string]any{"draft": map[string]any{"previous": false, "current": true}}
+	resp = sendGitLabHook(t, baseURL, gitlabTestToken, "d-2", hook)
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+
+	// Слияние MR
+	resp = sendGitLabHook(t, baseURL, gitlabTestToken, "d-3", mergeRequestHook("alice.gl", "merge", nil))
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+
+	req, err = http.NewRequestWithContext(context.Background(), "GET", baseURL+"/users/getReview/?user_id=u2", http.NoBody)
+	require.NoError(t, err)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+
+	var reviews struct {
+		PullRequests []models.PullRequestShort `json:"pull_requests"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&reviews))
+	require.Len(t, reviews.PullRequests, 1)
+	require.Equal(t, "MERGED", reviews.PullRequests[0].Status)
+
+	// Открытие смерженного PR игнорируется, а не заставляет GitLab повторять доставку
+	resp = sendGitLabHook(t, baseURL, gitlabTestToken, "d-4", mergeRequestHook("alice.gl", "reopen", nil))
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	require.Equal(t, "ignored", result["status"])
+}
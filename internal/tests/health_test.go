@@ -0,0 +1,38 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/andro-kes/avito_test/internal/models"
+)
+
+func TestHealth(t *testing.T) {
+	baseURL, _, _ := SetupTest(t)
+	client := &http.Client{}
+
+	req, err := http.NewRequestWithContext(context.Background(), "GET", baseURL+"/health/live", http.NoBody)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+
+	req, err = http.NewRequestWithContext(context.Background(), "GET", baseURL+"/health/ready", http.NoBody)
+	require.NoError(t, err)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+
+	var report models.HealthReport
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	require.Equal(t, models.HealthUp, report.Status)
+	for _, name := range []string{"database", "migrations", "draining"} {
+		require.Equal(t, models.HealthUp, report.Components[name].Status, name)
+	}
+}
@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func postWithKey(t *testing.T, url, key string, payload any) (*http.Response, []byte) {
+	t.Helper()
+
+	body, _ := json.Marshal(payload)
+	req, err := http.NewRequestWithContext(context.Background(), "POST", url, bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", key)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, respBody
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	baseURL, _, _ := SetupTest(t)
+
+	team := map[string]any{
+		"team_name": "backend",
+		"members": []map[string]any{
+			{"user_id": "u1", "username": "Alice", "is_active": true},
+			{"user_id": "u2", "username": "Bob", "is_active": true},
+			{"user_id": "u3", "username": "Charlie", "is_active": true},
+			{"user_id": "u4", "username": "Dave", "is_active": true},
+		},
+	}
+	resp, _ := postWithKey(t, baseURL+"/team/add/", "team-key", team)
+	require.Equal(t, 201, resp.StatusCode)
+
+	pr := map[string]any{
+		"pull_request_id":   "pr-5001",
+		"pull_request_name": "Add cache",
+		"author_id":         "u1",
+	}
+	resp, first := postWithKey(t, baseURL+"/pullRequest/create/", "pr-key", pr)
+	require.Equal(t, 201, resp.StatusCode)
+	require.Empty(t, resp.Header.Get("Idempotent-Replayed"))
+
+	// Повтор с тем же ключом возвращает исходный ответ, а не PR_EXISTS
+	resp, second := postWithKey(t, baseURL+"/pullRequest/create/", "pr-key", pr)
+	require.Equal(t, 201, resp.StatusCode)
+	require.Equal(t, "true", resp.Header.Get("Idempotent-Replayed"))
+	require.Equal(t, "application/json; charset=utf-8", resp.Header.Get("Content-Type"))
+	require.JSONEq(t, string(first), string(second))
+
+	// Тот же ключ с другим телом отклоняется
+	pr["pull_request_name"] = "Another name"
+	resp, body := postWithKey(t, baseURL+"/pullRequest/create/", "pr-key", pr)
+	require.Equal(t, 422, resp.StatusCode)
+	require.Contains(t, string(body), "IDEMPOTENCY_KEY_REUSED")
+}
@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/andro-kes/avito_test/internal/models"
+)
+
+type importResponse struct {
+	Imported int                     `json:"imported"`
+	Failed   int                     `json:"failed"`
+	Results  []models.PRImportResult `json:"results"`
+}
+
+func TestImportPRs(t *testing.T) {
+	baseURL, db, _ := SetupTest(t)
+	client := &http.Client{}
+
+	team := map[string]any{
+		"team_name": "backend",
+		"members": []map[string]any{
+			{"user_id": "u1", "username": "Alice", "is_active": true},
+			{"user_id": "u2", "username": "Bob", "is_active": true},
+			{"user_id": "u3", "username": "Charlie", "is_active": true},
+		},
+	}
+	body, _ := json.Marshal(team)
+	req, err := http.NewRequestWithContext(context.Background(), "POST", baseURL+"/team/add/", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 201, resp.StatusCode)
+
+	// NDJSON: валидная строка, неизвестный ревьювер, битая строка, дубликат
+	ndjson := strings.Join([]string{
+		`{"pull_request_id":"pr-1","pull_request_name":"One","author_id":"u1","status":"OPEN","assigned_reviewers":["u2","u3"],"created_at":"2025-01-10T13:00:00+03:00"}`,
+		`{"pull_request_id":"pr-2","pull_request_name":"Two","author_id":"u1","assigned_reviewers":["ghost"]}`,
+		`{"pull_request_id":`,
+		`{"pull_request_id":"pr-1","pull_request_name":"One again","author_id":"u2"}`,
+	}, "\n")
+	req, err = http.NewRequestWithContext(context.Background(), "POST", baseURL+"/pullRequest/import/", strings.NewReader(ndjson))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+
+	var result importResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	require.Equal(t, 1, result.Imported)
+	require.Equal(t, 3, result.Failed)
+	require.Equal(t, models.PRImportImported, result.Results[0].Status)
+	require.Equal(t, "NOT_FOUND", result.Results[1].Code)
+	require.Equal(t, "INVALID_PR", result.Results[2].Code)
+	require.Equal(t, "PR_EXISTS", result.Results[3].Code)
+
+	// Время со смещением сохраняется в UTC
+	var createdAt time.Time
+	err = db.QueryRow(context.Background(), "SELECT created_at FROM pull_requests WHERE pull_request_id = 'pr-1'").Scan(&createdAt)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, 1, 10, 10, 0, 0, 0, time.UTC), createdAt)
+
+	// JSON-массив: PR уже существует в базе, второй — смерженный
+	array := `[
+		{"pull_request_id":"pr-1","pull_request_name":"One","author_id":"u1"},
+		{"pull_request_id":"pr-3","pull_request_name":"Three","author_id":"u2","status":"MERGED","assigned_reviewers":["u1"]}
+	]`
+	req, err = http.NewRequestWithContext(context.Background(), "POST", baseURL+"/pullRequest/import/", strings.NewReader(array))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	require.Equal(t, 1, result.Imported)
+	require.Equal(t, "PR_EXISTS", result.Results[0].Code)
+	require.Equal(t, models.PRImportImported, result.Results[1].Status)
+
+	req, err = http.NewRequestWithContext(context.Background(), "GET", baseURL+"/users/countReview/?user_id=u2", http.NoBody)
+	require.NoError(t, err)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var count map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&count))
+	require.Equal(t, float64(1), count["reviews"])
+}
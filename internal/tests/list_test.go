@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/andro-kes/avito_test/internal/models"
+)
+
+func TestListPRs(t *testing.T) {
+	baseURL, _, _ := SetupTest(t)
+	client := &http.Client{}
+
+	addBody := `{
+		"team_name": "backend",
+		"members": [
+			{"user_id": "u1", "username": "Alice", "is_active": true},
+			{"user_id": "u2", "username": "Bob", "is_active": true}
+		]
+	}`
+	req, err := http.NewRequestWithContext(context.Background(), "POST", baseURL+"/team/add/", strings.NewReader(addBody))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 201, resp.StatusCode)
+
+	// Три PR от u1, один из них мержим
+	for i, name := range []string{"Add feature", "Fix bug", "Add logging"} {
+		prBody := fmt.Sprintf(`{"pull_request_id": "pr-600%d", "pull_request_name": %q, "author_id": "u1"}`, i+1, name)
+		req, err = http.NewRequestWithContext(context.Background(), "POST", baseURL+"/pullRequest/create/", strings.NewReader(prBody))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err = client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, 201, resp.StatusCode)
+	}
+
+	req, err = http.NewRequestWithContext(context.Background(), "POST", baseURL+"/pullRequest/merge/", strings.NewReader(`{"pull_request_id": "pr-6002"}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+
+	list := func(query string) models.PRListPage {
+		req, err := http.NewRequestWithContext(context.Background(), "GET", baseURL+"/pullRequest/list/?"+query, http.NoBody)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, 200, resp.StatusCode)
+
+		var page models.PRListPage
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		return page
+	}
+	ids := func(page models.PRListPage) []string {
+		res := make([]string, 0, len(page.PullRequests))
+		for _, pr := range page.PullRequests {
+			res = append(res, pr.PullRequestId)
+		}
+		return res
+	}
+
+	// Постраничный обход по id
+	page := list("sort=pull_request_id&limit=2")
+	require.Equal(t, []string{"pr-6001", "pr-6002"}, ids(page))
+	require.True(t, page.HasMore)
+	require.NotEmpty(t, page.NextCursor)
+	require.NotNil(t, page.PullRequests[0].CreatedAt)
+
+	page = list("sort=pull_request_id&limit=2&cursor=" + page.NextCursor)
+	require.Equal(t, []string{"pr-6003"}, ids(page))
+	require.False(t, page.HasMore)
+
+	page = list("sort=pull_request_id&order=desc&limit=2")
+	require.Equal(t, []string{"pr-6003", "pr-6002"}, ids(page))
+
+	// Фильтры
+	page = list("status=MERGED")
+	require.Equal(t, []string{"pr-6002"}, ids(page))
+	require.NotNil(t, page.PullRequests[0].MergedAt)
+
+	page = list("name=add&reviewer_id=u2&team_name=backend&sort=pull_request_id")
+	require.Equal(t, []string{"pr-6001", "pr-6003"}, ids(page))
+
+	page = list("author_id=u2")
+	require.Empty(t, page.PullRequests)
+
+	// Курсор от другой сортировки не принимается
+	req, err = http.NewRequestWithContext(context.Background(), "GET", baseURL+"/pullRequest/list/?sort=pull_request_name&cursor="+list("sort=pull_request_id&limit=1").NextCursor, http.NoBody)
+	require.NoError(t, err)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 400, resp.StatusCode)
+}
@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/andro-kes/avito_test/internal/migrations"
+)
+
+func versions(ms []migrations.Migration) []int {
+	out := make([]int, 0, len(ms))
+	for _, m := range ms {
+		out = append(out, m.Version)
+	}
+	return out
+}
+
+func TestMigrateDownAndGoto(t *testing.T) {
+	_, db, _ := SetupTest(t)
+	ctx := context.Background()
+
+	reverted, err := migrations.Down(ctx, db, 1)
+	require.NoError(t, err)
+	require.Equal(t, []int{5}, versions(reverted))
+
+	status, err := migrations.GetStatus(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, []int{5}, status.Pending)
+
+	var exists bool
+	require.NoError(t, db.QueryRow(ctx, "SELECT to_regclass('pr_reviewers') IS NOT NULL").Scan(&exists))
+	require.False(t, exists)
+
+	applied, reverted, err := migrations.Goto(ctx, db, 2)
+	require.NoError(t, err)
+	require.Empty(t, applied)
+	require.Equal(t, []int{4, 3}, versions(reverted))
+
+	applied, err = migrations.Up(ctx, db, 1)
+	require.NoError(t, err)
+	require.Equal(t, []int{3}, versions(applied))
+
+	states, err := migrations.List(ctx, db)
+	require.NoError(t, err)
+	require.Len(t, states, 5)
+	require.NotNil(t, states[2].AppliedAt)
+	require.Nil(t, states[3].AppliedAt)
+
+	// откат всех миграций и повторное применение с нуля
+	_, reverted, err = migrations.Goto(ctx, db, 0)
+	require.NoError(t, err)
+	require.Equal(t, []int{3, 2, 1}, versions(reverted))
+
+	applied, _, err = migrations.Goto(ctx, db, 5)
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2, 3, 4, 5}, versions(applied))
+
+	_, _, err = migrations.Goto(ctx, db, 42)
+	require.ErrorContains(t, err, "migration 42 not found")
+}
+
+func TestMigrateConcurrentAndChecksum(t *testing.T) {
+	_, db, _ := SetupTest(t)
+	ctx := context.Background()
+
+	_, _, err := migrations.Goto(ctx, db, 0)
+	require.NoError(t, err)
+
+	// Две реплики стартуют одновременно: каждая миграция применяется один раз.
+	results := make(chan []migrations.Migration, 2)
+	errs := make(chan error, 2)
+	for range 2 {
+		go func() {
+			applied, err := migrations.Up(ctx, db, 0)
+			results <- applied
+			errs <- err
+		}()
+	}
+	var total []int
+	for range 2 {
+		require.NoError(t, <-errs)
+		total = append(total, versions(<-results)...)
+	}
+	require.ElementsMatch(t, []int{1, 2, 3, 4, 5}, total)
+
+	var rows int
+	require.NoError(t, db.QueryRow(ctx, "SELECT count(*) FROM schema_migrations WHERE checksum IS NOT NULL").Scan(&rows))
+	require.Equal(t, 5, rows)
+
+	// Применённый файл изменён: запуск должен завершиться ошибкой.
+	_, err = db.Exec(ctx, "UPDATE schema_migrations SET checksum = 'edited' WHERE version = 2")
+	require.NoError(t, err)
+
+	err = migrations.ApplyMigrations(ctx, db)
+	require.ErrorContains(t, err, "applied migrations were modified: 000002_forge_integrations")
+
+	status, err := migrations.GetStatus(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, []int{2}, status.Modified)
+
+	// Базы, где миграции применены до появления контрольных сумм, принимают текущие файлы.
+	_, err = db.Exec(ctx, "UPDATE schema_migrations SET checksum = NULL")
+	require.NoError(t, err)
+	require.NoError(t, migrations.ApplyMigrations(ctx, db))
+}
+
+func TestMigrateVerify(t *testing.T) {
+	_, db, _ := SetupTest(t)
+	ctx := context.Background()
+
+	diffs, err := migrations.Verify(ctx, db)
+	require.NoError(t, err)
+	require.Empty(t, diffs)
+
+	_, err = db.Exec(ctx, `
+		ALTER TABLE users ADD COLUMN nickname TEXT;
+		ALTER TABLE users ALTER COLUMN username DROP NOT NULL;
+		DROP INDEX idx_pr_name`)
+	require.NoError(t, err)
+
+	diffs, err = migrations.Verify(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, []migrations.Difference{
+		{Object: "column", Name: "users.nickname", Actual: "text"},
+		{Object: "column", Name: "users.username", Expected: "varchar(255) NOT NULL", Actual: "varchar(255)"},
+		{
+			Object:   "index",
+			Name:     "idx_pr_name",
+			Expected: "CREATE INDEX idx_pr_name ON pull_requests USING btree (pull_request_name, pull_request_id)",
+		},
+	}, diffs)
+
+	// Проверка не оставляет временных схем.
+	var schemas int
+	require.NoError(t, db.QueryRow(ctx,
+		"SELECT count(*) FROM pg_namespace WHERE nspname LIKE 'migrate_verify_%'").Scan(&schemas))
+	require.Zero(t, schemas)
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	prerrors "github.com/andro-kes/avito_test/internal/errors"
 	"github.com/andro-kes/avito_test/internal/models"
 )
 
@@ -52,6 +53,26 @@ func TestCreatePR(t *testing.T) {
 	err = json.NewDecoder(resp.Body).Decode(&result)
 	require.NoError(t, err)
 	require.Equal(t, "pr-2001", result["pr"].PullRequestId)
+
+	// Автор должен существовать
+	pr = map[string]any{
+		"pull_request_id":   "pr-2002",
+		"pull_request_name": "Add feature",
+		"author_id":         "u-missing",
+	}
+	body, _ = json.Marshal(pr)
+	req, err = http.NewRequestWithContext(context.Background(), "POST", baseURL+"/pullRequest/create/", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 400, resp.StatusCode)
+
+	var errResp prerrors.Response
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&errResp))
+	require.Equal(t, "VALIDATION_ERROR", errResp.Error.Code)
+	require.Equal(t, []prerrors.FieldError{{Field: "author_id", Reason: "user does not exist"}}, errResp.Error.Details)
 }
 
 func TestMergePR(t *testing.T) {
@@ -230,3 +251,169 @@ func TestGetUserReview(t *testing.T) {
 	reviews := result["pull_requests"].([]any)
 	require.GreaterOrEqual(t, len(reviews), 1)
 }
+
+func TestGetPR(t *testing.T) {
+	baseURL, _, _ := SetupTest(t)
+	client := &http.Client{}
+
+	// Создаем команду
+	team := map[string]any{
+		"team_name": "backend",
+		"members": []map[string]any{
+			{"user_id": "u1", "username": "Alice", "is_active": true},
+			{"user_id": "u2", "username": "Bob", "is_active": true},
+			{"user_id": "u3", "username": "Charlie", "is_active": true},
+		},
+	}
+	body, _ := json.Marshal(team)
+	req, err := http.NewRequestWithContext(context.Background(), "POST", baseURL+"/team/add/", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 201, resp.StatusCode)
+
+	// Создаем PR
+	pr := map[string]any{
+		"pull_request_id":   "pr-7001",
+		"pull_request_name": "Add details",
+		"author_id":         "u1",
+	}
+	body, _ = json.Marshal(pr)
+	req, err = http.NewRequestWithContext(context.Background(), "POST", baseURL+"/pullRequest/create/", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 201, resp.StatusCode)
+
+	// Получаем PR с раскрытыми ревьюверами
+	req, err = http.NewRequestWithContext(context.Background(), "GET", baseURL+"/pullRequest/get/?pull_request_id=pr-7001", http.NoBody)
+	require.NoError(t, err)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+
+	var result map[string]models.PullRequestDetails
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	require.NoError(t, err)
+
+	details := result["pr"]
+	require.Equal(t, "Alice", details.Author.Username)
+	require.Equal(t, "backend", details.Author.TeamName)
+	require.Len(t, details.AssignedReviewers, 2)
+	for _, r := range details.AssignedReviewers {
+		require.NotEqual(t, "u1", r.UserId)
+		require.NotEmpty(t, r.Username)
+		require.Equal(t, "backend", r.TeamName)
+		require.NotNil(t, r.AssignedAt)
+		require.Equal(t, models.ReviewPending, r.Decision)
+	}
+
+	// Несуществующий PR
+	req, err = http.NewRequestWithContext(context.Background(), "GET", baseURL+"/pullRequest/get/?pull_request_id=pr-missing", http.NoBody)
+	require.NoError(t, err)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 404, resp.StatusCode)
+}
+
+func TestGetUserReviewPagination(t *testing.T) {
+	baseURL, _, _ := SetupTest(t)
+	client := &http.Client{}
+
+	// Создаем команду из двух человек: u2 — ревьювер всех PR от u1
+	team := map[string]any{
+		"team_name": "backend",
+		"members": []map[string]any{
+			{"user_id": "u1", "username": "Alice", "is_active": true},
+			{"user_id": "u2", "username": "Bob", "is_active": true},
+		},
+	}
+	body, _ := json.Marshal(team)
+	req, err := http.NewRequestWithContext(context.Background(), "POST", baseURL+"/team/add/", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 201, resp.StatusCode)
+
+	for _, id := range []string{"pr-8001", "pr-8002", "pr-8003"} {
+		pr := map[string]any{
+			"pull_request_id":   id,
+			"pull_request_name": "Review me",
+			"author_id":         "u1",
+		}
+		body, _ = json.Marshal(pr)
+		req, err = http.NewRequestWithContext(context.Background(), "POST", baseURL+"/pullRequest/create/", bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err = client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, 201, resp.StatusCode)
+	}
+
+	// Мержим первый PR
+	body, _ = json.Marshal(map[string]any{"pull_request_id": "pr-8001"})
+	req, err = http.NewRequestWithContext(context.Background(), "POST", baseURL+"/pullRequest/merge/", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+
+	type reviewPage struct {
+		PullRequests []models.PullRequestShort `json:"pull_requests"`
+		HasMore      bool                      `json:"has_more"`
+		NextCursor   string                    `json:"next_cursor"`
+	}
+	getReview := func(query string) reviewPage {
+		req, err := http.NewRequestWithContext(context.Background(), "GET", baseURL+"/users/getReview/?user_id=u2"+query, http.NoBody)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, 200, resp.StatusCode)
+
+		var page reviewPage
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&page))
+		return page
+	}
+
+	// Без параметров — все PR в порядке создания
+	page := getReview("")
+	require.Len(t, page.PullRequests, 3)
+	require.Equal(t, "pr-8001", page.PullRequests[0].PullRequestId)
+	require.False(t, page.HasMore)
+	require.Empty(t, page.NextCursor)
+
+	// Только открытые, по одному на страницу
+	page = getReview("&status=OPEN&limit=1")
+	require.Len(t, page.PullRequests, 1)
+	require.Equal(t, "pr-8002", page.PullRequests[0].PullRequestId)
+	require.True(t, page.HasMore)
+
+	page = getReview("&status=OPEN&limit=1&cursor=" + page.NextCursor)
+	require.Len(t, page.PullRequests, 1)
+	require.Equal(t, "pr-8003", page.PullRequests[0].PullRequestId)
+	require.False(t, page.HasMore)
+
+	// since в будущем отсекает всё
+	page = getReview("&since=2999-01-01")
+	require.Empty(t, page.PullRequests)
+
+	// без user_id запрос отклоняется, а не отдаёт все PR
+	req, err = http.NewRequestWithContext(context.Background(), "GET", baseURL+"/users/getReview/?status=OPEN", http.NoBody)
+	require.NoError(t, err)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 400, resp.StatusCode)
+}
@@ -11,13 +11,22 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/ory/dockertest/v3"
 	"github.com/ory/dockertest/v3/docker"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/stretchr/testify/require"
 
+	"github.com/andro-kes/avito_test/internal/config"
 	"github.com/andro-kes/avito_test/internal/http/handlers"
+	"github.com/andro-kes/avito_test/internal/http/middleware"
 	logger "github.com/andro-kes/avito_test/internal/log"
+	"github.com/andro-kes/avito_test/internal/metrics"
 	"github.com/andro-kes/avito_test/internal/migrations"
+	"github.com/andro-kes/avito_test/internal/repo"
+	"github.com/andro-kes/avito_test/internal/service"
+	"github.com/andro-kes/avito_test/internal/tracing"
 )
 
+const gitlabTestToken = "gitlab-test-token"
+
 func SetupTest(t *testing.T) (baseURL string, db *pgxpool.Pool, router *gin.Engine) {
 	t.Helper()
 
@@ -64,13 +73,26 @@ func SetupTest(t *testing.T) (baseURL string, db *pgxpool.Pool, router *gin.Engi
 	require.NoError(t, migrations.ApplyMigrations(context.Background(), db))
 
 	gin.SetMode(gin.TestMode)
-	router = gin.Default()
+	router = gin.New()
+	router.Use(tracing.Middleware())
+	router.Use(middleware.RequestID(), middleware.AccessLog(), middleware.Recovery())
+	router.Use(metrics.Middleware())
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	st := repo.NewPostgresStorage(db)
+	router.Use(middleware.Idempotency(service.NewIdempotencyService(st, time.Hour)))
+	router.Use(middleware.Errors())
+
+	hm := handlers.NewHandlerManager(st)
 
-	hm := handlers.NewHandlerManager(db)
+	health := router.Group("/health/")
+	health.GET("live", hm.Live)
+	health.GET("ready", hm.Ready)
 
 	team := router.Group("/team/")
 	team.POST("add/", hm.AddTeam)
 	team.GET("get/", hm.GetTeam)
+	team.GET("export/", hm.ExportTeams)
+	team.POST("import/", hm.ImportTeams)
 
 	user := router.Group("/users/")
 	user.POST("set_is_active/", hm.SetIsActive)
@@ -82,6 +104,24 @@ func SetupTest(t *testing.T) (baseURL string, db *pgxpool.Pool, router *gin.Engi
 	pr.POST("create/", hm.CreatePR)
 	pr.POST("merge/", hm.MergePR)
 	pr.POST("reassign/", hm.ReassignReviewer)
+	pr.POST("review/", hm.ReviewPR)
+	pr.GET("get/", hm.GetPR)
+	pr.GET("list/", hm.ListPRs)
+	pr.POST("import/", hm.ImportPRs)
+
+	stats := router.Group("/stats/")
+	stats.GET("reviewers/", hm.ReviewerStats)
+	stats.GET("prs/", hm.PRStats)
+
+	admin := router.Group("/admin/")
+	admin.POST("sync/", hm.SyncOrg)
+
+	integrations := router.Group("/integrations/")
+	integrations.POST("gitlab/webhook", hm.GitLabWebhook(config.GitLabConfig{
+		WebhookToken: gitlabTestToken,
+		ProjectTeams: map[string]string{"group/backend": "backend"},
+	}))
+	integrations.POST("identities/", hm.MapForgeIdentity)
 
 	ts := httptest.NewServer(router)
 
@@ -0,0 +1,159 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/andro-kes/avito_test/internal/models"
+)
+
+func TestReviewerStats(t *testing.T) {
+	baseURL, _, _ := SetupTest(t)
+	client := &http.Client{}
+
+	post := func(path, body string, status int) map[string]any {
+		req, err := http.NewRequestWithContext(context.Background(), "POST", baseURL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, status, resp.StatusCode)
+
+		var result map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		return result
+	}
+
+	// u2 и u3 — единственные кандидаты в ревьюверы PR от u1
+	post("/team/add/", `{
+		"team_name": "backend",
+		"members": [
+			{"user_id": "u1", "username": "Alice", "is_active": true},
+			{"user_id": "u2", "username": "Bob", "is_active": true},
+			{"user_id": "u3", "username": "Charlie", "is_active": true}
+		]
+	}`, 201)
+	post("/pullRequest/create/", `{"pull_request_id": "pr-9001", "pull_request_name": "Stats", "author_id": "u1"}`, 201)
+
+	// u2 одобряет PR, повторное решение время первого ревью не меняет
+	post("/pullRequest/review/", `{"pull_request_id": "pr-9001", "user_id": "u2", "decision": "APPROVED"}`, 200)
+	result := post("/pullRequest/review/", `{"pull_request_id": "pr-9001", "user_id": "u2", "decision": "CHANGES_REQUESTED"}`, 200)
+	require.Contains(t, result, "pr")
+	post("/pullRequest/review/", `{"pull_request_id": "pr-9001", "user_id": "u1", "decision": "APPROVED"}`, 409)
+	post("/pullRequest/review/", `{"pull_request_id": "pr-9001", "user_id": "u2", "decision": "LGTM"}`, 400)
+	// PENDING — не решение: ошибка поля, как и у других неверных значений
+	result = post("/pullRequest/review/", `{"pull_request_id": "pr-9001", "user_id": "u2", "decision": "PENDING"}`, 400)
+	require.Equal(t, "VALIDATION_ERROR", result["error"].(map[string]any)["code"])
+
+	// Появляется u4, на него переназначаем u3
+	post("/team/import/?format=json", `{"teams": [{"team_name": "backend", "members": [
+		{"user_id": "u4", "username": "Dave", "is_active": true}
+	]}]}`, 200)
+	post("/pullRequest/reassign/", `{"pull_request_id": "pr-9001", "old_user_id": "u3"}`, 200)
+
+	req, err := http.NewRequestWithContext(context.Background(), "GET", baseURL+"/stats/reviewers/?team_name=backend&from=2000-01-01&to=2999-01-01", http.NoBody)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+
+	var report models.ReviewerStatsReport
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+
+	users := make(map[string]models.ReviewerStats, len(report.Users))
+	for _, u := range report.Users {
+		users[u.UserId] = u
+	}
+	require.Len(t, users, 4)
+
+	require.Equal(t, 1, users["u2"].AssignmentsReceived)
+	require.Equal(t, 1, users["u2"].ReviewsCompleted)
+	require.NotNil(t, users["u2"].MedianTimeToFirstReview)
+	require.Equal(t, 1, users["u2"].OpenLoad)
+
+	require.Equal(t, 1, users["u3"].ReassignedAway)
+	require.Equal(t, 0, users["u3"].OpenLoad)
+	require.Nil(t, users["u3"].MedianTimeToFirstReview)
+
+	require.Equal(t, 1, users["u4"].AssignmentsReceived)
+	require.Equal(t, 1, users["u4"].OpenLoad)
+
+	require.Len(t, report.Teams, 1)
+	require.Equal(t, "backend", report.Teams[0].TeamName)
+	require.Equal(t, 3, report.Teams[0].AssignmentsReceived)
+	require.Equal(t, 1, report.Teams[0].ReassignedAway)
+	require.Equal(t, 2, report.Teams[0].OpenLoad)
+}
+
+func TestPRStats(t *testing.T) {
+	baseURL, _, _ := SetupTest(t)
+	client := &http.Client{}
+
+	post := func(path, body string) {
+		req, err := http.NewRequestWithContext(context.Background(), "POST", baseURL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Less(t, resp.StatusCode, 300)
+	}
+
+	post("/team/add/", `{
+		"team_name": "backend",
+		"members": [
+			{"user_id": "u1", "username": "Alice", "is_active": true},
+			{"user_id": "u2", "username": "Bob", "is_active": true}
+		]
+	}`)
+	post("/pullRequest/create/", `{"pull_request_id": "pr-9101", "pull_request_name": "One", "author_id": "u1"}`)
+	post("/pullRequest/create/", `{"pull_request_id": "pr-9102", "pull_request_name": "Two", "author_id": "u1"}`)
+	post("/pullRequest/merge/", `{"pull_request_id": "pr-9101"}`)
+
+	get := func(query string) *http.Response {
+		req, err := http.NewRequestWithContext(context.Background(), "GET", baseURL+"/stats/prs/?from=2000-01-01&to=2999-01-01"+query, http.NoBody)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	resp := get("&tz=Europe/Moscow")
+	require.Equal(t, 200, resp.StatusCode)
+
+	var report models.PRThroughputReport
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	require.Equal(t, "Europe/Moscow", report.Timezone)
+	require.Len(t, report.Weeks, 1)
+
+	week := report.Weeks[0]
+	require.Equal(t, "backend", week.TeamName)
+	require.Equal(t, 2, week.Opened)
+	require.Equal(t, 1, week.Merged)
+	require.Equal(t, 0, week.Closed)
+	require.NotNil(t, week.MedianOpenToMerge)
+	require.NotNil(t, week.P90OpenToMerge)
+	require.Equal(t, map[int]int{1: 2}, week.ReviewerCounts)
+
+	resp = get("&format=csv")
+	require.Equal(t, 200, resp.StatusCode)
+	require.Equal(t, "text/csv", resp.Header.Get("Content-Type"))
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	require.Len(t, lines, 2)
+	require.Equal(t, "team_name,week,opened,merged,closed,median_open_to_merge_seconds,p90_open_to_merge_seconds,reviewers_1", lines[0])
+	require.True(t, strings.HasPrefix(lines[1], "backend,"))
+
+	resp = get("&tz=Mars/Olympus")
+	require.Equal(t, 400, resp.StatusCode)
+}
@@ -0,0 +1,22 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/andro-kes/avito_test/internal/repo"
+	"github.com/andro-kes/avito_test/internal/repo/repotest"
+)
+
+func TestPostgresStorage(t *testing.T) {
+	_, db, _ := SetupTest(t)
+
+	repotest.Run(t, func(t *testing.T) *repo.Storage {
+		_, err := db.Exec(context.Background(), `TRUNCATE teams, users, pull_requests, pr_reviewers,
+			forge_identities, forge_pull_requests, forge_deliveries, idempotency_keys CASCADE`)
+		require.NoError(t, err)
+		return repo.NewPostgresStorage(db)
+	})
+}
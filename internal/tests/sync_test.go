@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/andro-kes/avito_test/internal/models"
+)
+
+func TestSyncOrg(t *testing.T) {
+	baseURL, _, _ := SetupTest(t)
+	client := &http.Client{}
+
+	addBody := `{
+		"team_name": "backend",
+		"members": [
+			{"user_id": "u1", "username": "Alice", "is_active": true},
+			{"user_id": "u2", "username": "Bob", "is_active": true},
+			{"user_id": "u3", "username": "Charlie", "is_active": true}
+		]
+	}`
+	req, err := http.NewRequestWithContext(context.Background(), "POST", baseURL+"/team/add/", strings.NewReader(addBody))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 201, resp.StatusCode)
+
+	// PR от u1 получает ревьюверами u2 и u3
+	prBody := `{"pull_request_id": "pr-5001", "pull_request_name": "Sync", "author_id": "u1"}`
+	req, err = http.NewRequestWithContext(context.Background(), "POST", baseURL+"/pullRequest/create/", strings.NewReader(prBody))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 201, resp.StatusCode)
+
+	// В желаемом состоянии нет u3, зато есть u4
+	yamlBody := "teams:\n" +
+		"  - team_name: backend\n" +
+		"    members:\n" +
+		"      - {user_id: u1, username: Alice, is_active: true}\n" +
+		"      - {user_id: u2, username: Bob, is_active: true}\n" +
+		"      - {user_id: u4, username: Dave, is_active: true}\n"
+
+	sync := func(query string) models.OrgSyncPlan {
+		req, err := http.NewRequestWithContext(context.Background(), "POST", baseURL+"/admin/sync/?format=yaml"+query, strings.NewReader(yamlBody))
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, 200, resp.StatusCode)
+
+		var plan models.OrgSyncPlan
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&plan))
+		return plan
+	}
+
+	expected := []models.PRReassignment{{PullRequestId: "pr-5001", OldUserId: "u3", NewUserId: "u4"}}
+
+	plan := sync("&dry_run=true")
+	require.True(t, plan.DryRun)
+	require.Equal(t, []string{"u3"}, plan.Diff.UsersDeactivated)
+	require.Len(t, plan.Diff.UsersAdded, 1)
+	require.Equal(t, expected, plan.Reassignments)
+
+	// dry_run откатывает транзакцию: u4 ещё нет
+	req, err = http.NewRequestWithContext(context.Background(), "GET", baseURL+"/users/countReview/?user_id=u4", http.NoBody)
+	require.NoError(t, err)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 404, resp.StatusCode)
+
+	plan = sync("")
+	require.False(t, plan.DryRun)
+	require.Equal(t, expected, plan.Reassignments)
+
+	req, err = http.NewRequestWithContext(context.Background(), "GET", baseURL+"/users/getReview/?user_id=u4", http.NoBody)
+	require.NoError(t, err)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+
+	var review map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&review))
+	require.Len(t, review["pull_requests"], 1)
+
+	// Повторная синхронизация ничего не меняет
+	plan = sync("")
+	require.True(t, plan.Diff.Empty())
+	require.Empty(t, plan.Reassignments)
+
+	// Документ без пользователей отклоняется, а не деактивирует всех
+	req, err = http.NewRequestWithContext(context.Background(), "POST", baseURL+"/admin/sync/?format=yaml", strings.NewReader("teams: []\n"))
+	require.NoError(t, err)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 400, resp.StatusCode)
+}
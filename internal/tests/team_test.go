@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"strings"
 	"testing"
@@ -78,7 +79,7 @@ func TestGetTeam(t *testing.T) {
 	resp2, err := client.Do(req2)
 	require.NoError(t, err)
 	defer resp2.Body.Close()
-	require.Equal(t, 404, resp2.StatusCode)
+	require.Equal(t, 400, resp2.StatusCode)
 
 	req3, err := http.NewRequestWithContext(context.Background(), "GET", baseURL+"/team/get?team_name=nonexistent", http.NoBody)
 	require.NoError(t, err)
@@ -87,3 +88,75 @@ func TestGetTeam(t *testing.T) {
 	defer resp3.Body.Close()
 	require.Equal(t, 404, resp3.StatusCode)
 }
+
+func TestImportTeams(t *testing.T) {
+	baseURL, _, _ := SetupTest(t)
+	client := &http.Client{}
+
+	addBody := `{
+		"team_name": "backend",
+		"members": [
+			{"user_id": "u1", "username": "Alice", "is_active": true},
+			{"user_id": "u2", "username": "Bob", "is_active": true}
+		]
+	}`
+	req, err := http.NewRequestWithContext(context.Background(), "POST", baseURL+"/team/add/", strings.NewReader(addBody))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 201, resp.StatusCode)
+
+	// u2 переходит в payments, u1 деактивируется, u3 добавляется
+	csvBody := "team_name,user_id,username,is_active\n" +
+		"backend,u1,Alice,false\n" +
+		"payments,u2,Bob,true\n" +
+		"payments,u3,Charlie,true\n"
+
+	req, err = http.NewRequestWithContext(context.Background(), "POST", baseURL+"/team/import/?format=csv&dry_run=true", strings.NewReader(csvBody))
+	require.NoError(t, err)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+
+	var result struct {
+		DryRun bool           `json:"dry_run"`
+		Diff   models.OrgDiff `json:"diff"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	require.True(t, result.DryRun)
+	require.Equal(t, []string{"payments"}, result.Diff.TeamsCreated)
+	require.Equal(t, []string{"u1"}, result.Diff.UsersDeactivated)
+	require.Len(t, result.Diff.UsersAdded, 1)
+	require.Len(t, result.Diff.UsersMoved, 1)
+	require.Equal(t, "backend", result.Diff.UsersMoved[0].FromTeam)
+
+	// dry_run ничего не меняет
+	req, err = http.NewRequestWithContext(context.Background(), "GET", baseURL+"/team/get/?team_name=payments", http.NoBody)
+	require.NoError(t, err)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 404, resp.StatusCode)
+
+	req, err = http.NewRequestWithContext(context.Background(), "POST", baseURL+"/team/import/?format=csv", strings.NewReader(csvBody))
+	require.NoError(t, err)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+
+	req, err = http.NewRequestWithContext(context.Background(), "GET", baseURL+"/team/export/?format=yaml&team_name=payments", http.NoBody)
+	require.NoError(t, err)
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+	require.Equal(t, "application/yaml", resp.Header.Get("Content-Type"))
+
+	exported, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(exported), "user_id: u3")
+}
@@ -130,6 +130,15 @@ func TestDeactivateUsers(t *testing.T) {
 	defer resp.Body.Close()
 	require.Equal(t, 201, resp.StatusCode)
 
+	// u3 — единственный кандидат, заменить его некем
+	code, err := post(baseURL+"/pullRequest/create/", map[string]any{
+		"pull_request_id":   "pr-1",
+		"pull_request_name": "Add search",
+		"author_id":         "u1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 201, code)
+
 	deactivateBody := map[string]any{
 		"user_ids": []string{"u3"},
 	}
@@ -144,9 +153,11 @@ func TestDeactivateUsers(t *testing.T) {
 	defer resp.Body.Close()
 	require.Equal(t, 200, resp.StatusCode)
 
-	var result map[string][]string
+	var result models.Deactivation
 	err = json.NewDecoder(resp.Body).Decode(&result)
 	require.NoError(t, err)
 
-	require.Equal(t, []string{"u3"}, result["deactivated"])
+	require.Equal(t, []string{"u3"}, result.Deactivated)
+	require.Equal(t, []models.PRReassignment{{PullRequestId: "pr-1", OldUserId: "u3"}}, result.Reassignments)
+	require.Equal(t, []string{"pr-1"}, result.Unreplaced)
 }
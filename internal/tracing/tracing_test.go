@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Middleware())
+	router.POST("/pullRequest/merge/", func(c *gin.Context) {
+		_, span := Start(c.Request.Context(), "PRService.MergePR")
+		span.End()
+		c.Status(500)
+	})
+
+	const traceId = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodPost, "/pullRequest/merge/", http.NoBody)
+	req.Header.Set("traceparent", "00-"+traceId+"-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	child, server := spans[0], spans[1]
+	require.Equal(t, "PRService.MergePR", child.Name())
+	require.Equal(t, "POST /pullRequest/merge/", server.Name())
+
+	// Трасса продолжает входящий traceparent, спан сервиса вложен в спан запроса
+	require.Equal(t, traceId, server.SpanContext().TraceID().String())
+	require.Equal(t, server.SpanContext().SpanID(), child.Parent().SpanID())
+	require.Equal(t, codes.Error, server.Status().Code)
+}
+
+func TestStatement(t *testing.T) {
+	require.Equal(t, "SELECT 1 FROM users WHERE user_id = $1", statement(`
+	SELECT 1
+	FROM users
+	WHERE user_id = $1
+	`))
+}